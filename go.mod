@@ -6,14 +6,15 @@ require (
 	fyne.io/fyne/v2 v2.5.4
 	github.com/davecgh/go-spew v1.1.1
 	github.com/empack/minuit2go v0.0.0-20250212104857-a1740a8eb28b
+	github.com/fsnotify/fsnotify v1.8.0
 	golang.org/x/image v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	fyne.io/systray v1.11.0 // indirect
 	github.com/BurntSushi/toml v1.4.0 // indirect
 	github.com/fredbi/uri v1.1.0 // indirect
-	github.com/fsnotify/fsnotify v1.8.0 // indirect
 	github.com/fyne-io/gl-js v0.1.0 // indirect
 	github.com/fyne-io/glfw-js v0.1.0 // indirect
 	github.com/fyne-io/image v0.1.0 // indirect
@@ -37,5 +38,4 @@ require (
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -2,15 +2,29 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"os"
 	"physicsGUI/pkg/gui"
-	"physicsGUI/pkg/trigger"
+	"physicsGUI/pkg/recipe"
+	"physicsGUI/pkg/simulate"
 )
 
 func main() {
-	fmt.Println("Hello, World!")
+	// subcommands run without the GUI
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if err := simulate.Run(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "recipe" {
+		if err := recipe.Command(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	// Initialize trigger for recalculating gui based on changes
-	trigger.Init()
+	fmt.Println("Hello, World!")
 
 	// Start GUI (blocking so it needs to be called at the end)
 	gui.Start()
@@ -0,0 +1,408 @@
+// Package api is a JSON API over HTTP to drive SPIRIT from scripts and notebooks while the GUI shows the changes
+//
+//	GET  /samples                         names of the samples
+//	GET  /parameters                      parameters of the sample
+//	PUT  /parameters/{group}/{name}       change the value, the limits, the step or the fit flag of a parameter
+//	GET  /datasets                        loaded data sets
+//	POST /datasets                        add a data set
+//	POST /recalculate                     recalculate the curves
+//	GET  /curves/{name}                   points of a curve, f.e. eden or intensity
+//	GET  /fit                             status and result of the current or the last fit
+//	POST /fit/{action}                    start, pause, resume or stop the fit
+//
+// every request acts on the selected sample unless another one is given by the query parameter sample,
+// requests of web pages of other hosts are rejected and requests with a body need the content type application/json
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
+	"strings"
+	"time"
+)
+
+// Sample is a sample the API acts on
+type Sample interface {
+	Session() *state.Session
+	// AddDataset adds a data set as data track
+	AddDataset(name string, points function.Points) error
+	// Recalculate calculates the curves of the current parameters
+	Recalculate() error
+	// Curve returns the points of the curve with the name
+	Curve(name string) (function.Points, error)
+}
+
+// Samples finds the sample of a request
+type Samples interface {
+	Names() []string
+	// Sample returns the sample with the name, the selected sample for an empty name
+	Sample(name string) (Sample, error)
+}
+
+var (
+	// ErrSampleNotFound is returned by Samples for unknown names
+	ErrSampleNotFound = errors.New("sample not found")
+	// ErrCurveNotFound is returned by Sample for unknown curves
+	ErrCurveNotFound = errors.New("curve not found")
+)
+
+// Parameter is a parameter as sent and received by the API
+type Parameter struct {
+	Group   string  `json:"group"`
+	Name    string  `json:"name"`
+	Value   float64 `json:"value"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Limited bool    `json:"limited"`
+	Fit     bool    `json:"fit"`
+	Step    float64 `json:"step"`
+	Error   float64 `json:"error"`
+	Order   int     `json:"order"`
+}
+
+// ParameterChange changes the fields of a parameter which are set
+type ParameterChange struct {
+	Value   *float64 `json:"value"`
+	Min     *float64 `json:"min"`
+	Max     *float64 `json:"max"`
+	Limited *bool    `json:"limited"`
+	Fit     *bool    `json:"fit"`
+	Step    *float64 `json:"step"`
+	Order   *int     `json:"order"`
+}
+
+// Point is a point of a data set or a curve, the error is left out for curves
+type Point struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Error float64 `json:"error,omitempty"`
+}
+
+// Dataset is a loaded data set, the points are only sent when a data set is added
+type Dataset struct {
+	Name     string  `json:"name"`
+	Source   string  `json:"source,omitempty"`
+	Points   []Point `json:"points,omitempty"`
+	Count    int     `json:"count"`
+	Excluded bool    `json:"excluded"`
+}
+
+// Recalculation is the answer of a recalculation
+type Recalculation struct {
+	Duration string `json:"duration"`
+}
+
+// FitStart configures a started fit, empty fields use the defaults
+type FitStart struct {
+	Engine   string `json:"engine"`
+	MaxCalls int    `json:"max_calls"`
+	// seed of the engines with random numbers, a random seed if empty
+	Seed uint64 `json:"seed"`
+}
+
+// Fit is the state of the current or the last fit
+type Fit struct {
+	Status string `json:"status"`
+	Run    int    `json:"run"`
+	Engine string `json:"engine"`
+	// seed of the engine, 0 if the engine uses no random numbers
+	Seed uint64 `json:"seed,omitempty"`
+	// names of the fitted parameters
+	Free []string `json:"free"`
+	// values and errors of all parameters, empty before the first iteration
+	Values    []float64 `json:"values,omitempty"`
+	Errors    []float64 `json:"errors,omitempty"`
+	FVal      float64   `json:"fval"`
+	NFcn      int       `json:"nfcn"`
+	Converged bool      `json:"converged"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// maximum size of a request body, f.e. a data set with several 100000 points
+const maxBodySize = 32 << 20
+
+type handler struct {
+	samples Samples
+}
+
+// NewHandler returns the handler of the API, it only answers requests to and from localhost
+func NewHandler(samples Samples) http.Handler {
+	h := &handler{samples: samples}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /samples", h.listSamples)
+	mux.HandleFunc("GET /parameters", h.withSample(h.listParameters))
+	mux.HandleFunc("PUT /parameters/{group}/{name}", h.withSample(h.changeParameter))
+	mux.HandleFunc("GET /datasets", h.withSample(h.listDatasets))
+	mux.HandleFunc("POST /datasets", h.withSample(h.addDataset))
+	mux.HandleFunc("POST /recalculate", h.withSample(h.recalculate))
+	mux.HandleFunc("GET /curves/{name}", h.withSample(h.curve))
+	mux.HandleFunc("GET /fit", h.withSample(h.fit))
+	mux.HandleFunc("POST /fit/{action}", h.withSample(h.fitAction))
+	return guard(mux)
+}
+
+// guard rejects requests which a web page could send to the API
+// another host name reaches the API by DNS rebinding, another origin is a page of another site and
+// any other content type than JSON is sent by pages without asking the API first (CORS preflight)
+func guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLocalhost(r.Host) {
+			writeJSON(w, http.StatusForbidden, errorResponse{Error: fmt.Sprintf("the host %s is not allowed", r.Host)})
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !isLocalhost(u.Host) {
+				writeJSON(w, http.StatusForbidden, errorResponse{Error: fmt.Sprintf("the origin %s is not allowed", origin)})
+				return
+			}
+		}
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: "the content type must be application/json"})
+				return
+			}
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLocalhost reports whether the host with an optional port is localhost or a loopback address
+func isLocalhost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// writes the value as JSON
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writes the error with a status fitting to it
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrSampleNotFound), errors.Is(err, ErrCurveNotFound), errors.Is(err, state.ErrParameterNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, state.ErrFitRunning), errors.Is(err, state.ErrNoFit):
+		status = http.StatusConflict
+	case errors.As(err, new(*http.MaxBytesError)):
+		status = http.StatusRequestEntityTooLarge
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// decodes the JSON body of the request
+func readJSON(r *http.Request, value any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// passes the sample of the request to the handler
+func (h *handler) withSample(handle func(w http.ResponseWriter, r *http.Request, s Sample)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, err := h.samples.Sample(r.URL.Query().Get("sample"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		handle(w, r, s)
+	}
+}
+
+func (h *handler) listSamples(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.samples.Names())
+}
+
+func (h *handler) listParameters(w http.ResponseWriter, r *http.Request, s Sample) {
+	parameters := s.Session().Parameters()
+	list := make([]Parameter, len(parameters))
+	for i, p := range parameters {
+		list[i] = parameter(p)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func parameter(p state.Parameter) Parameter {
+	return Parameter{
+		Group: p.Group, Name: p.Name, Value: p.Value,
+		Min: p.Min, Max: p.Max, Limited: p.Limited,
+		Fit: p.Fit, Step: p.Step, Error: p.Error, Order: p.Order,
+	}
+}
+
+func (h *handler) changeParameter(w http.ResponseWriter, r *http.Request, s Sample) {
+	var change ParameterChange
+	if err := readJSON(r, &change); err != nil {
+		writeError(w, err)
+		return
+	}
+	group, name := r.PathValue("group"), r.PathValue("name")
+	err := s.Session().UpdateParameter(group, name, func(p *state.Parameter) {
+		set(&p.Value, change.Value)
+		set(&p.Min, change.Min)
+		set(&p.Max, change.Max)
+		set(&p.Limited, change.Limited)
+		set(&p.Fit, change.Fit)
+		set(&p.Step, change.Step)
+		set(&p.Order, change.Order)
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	p, err := s.Session().Parameter(group, name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, parameter(p))
+}
+
+// sets the field to the value if the value is given
+func set[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func (h *handler) listDatasets(w http.ResponseWriter, r *http.Request, s Sample) {
+	datasets := s.Session().Datasets()
+	list := make([]Dataset, len(datasets))
+	for i, d := range datasets {
+		list[i] = Dataset{Name: d.Name, Source: d.Source, Count: len(d.Points), Excluded: d.Excluded}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) addDataset(w http.ResponseWriter, r *http.Request, s Sample) {
+	var d Dataset
+	if err := readJSON(r, &d); err != nil {
+		writeError(w, err)
+		return
+	}
+	if d.Name == "" {
+		writeError(w, errors.New("the data set needs a name"))
+		return
+	}
+	if len(d.Points) == 0 {
+		writeError(w, errors.New("no data"))
+		return
+	}
+	points := make(function.Points, len(d.Points))
+	for i, p := range d.Points {
+		points[i] = &function.Point{X: p.X, Y: p.Y, Error: p.Error}
+	}
+	// same rule as the import of data files
+	if err := data.CheckErrors(points); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.AddDataset(d.Name, points); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, Dataset{Name: d.Name, Count: len(points)})
+}
+
+func (h *handler) recalculate(w http.ResponseWriter, r *http.Request, s Sample) {
+	start := time.Now()
+	if err := s.Recalculate(); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, Recalculation{Duration: time.Since(start).String()})
+}
+
+func (h *handler) curve(w http.ResponseWriter, r *http.Request, s Sample) {
+	points, err := s.Curve(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	list := make([]Point, len(points))
+	for i, p := range points {
+		list[i] = Point{X: p.X, Y: p.Y, Error: p.Error}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *handler) fit(w http.ResponseWriter, r *http.Request, s Sample) {
+	writeJSON(w, http.StatusOK, fitState(s.Session()))
+}
+
+func fitState(s *state.Session) Fit {
+	f := s.Fit()
+	names := s.Names()
+	fit := Fit{Status: f.Status.String(), Run: f.Run, Engine: f.Engine, Seed: f.Seed, Free: make([]string, len(f.Free))}
+	for i, index := range f.Free {
+		fit.Free[i] = names[index]
+	}
+	if res := f.Result; res != nil {
+		fit.Values, fit.Errors = res.Parameters, res.Errors
+		fit.FVal, fit.NFcn, fit.Converged = res.FVal, res.NFcn, res.Converged
+	}
+	if f.Err != nil {
+		fit.Error = f.Err.Error()
+	}
+	return fit
+}
+
+func (h *handler) fitAction(w http.ResponseWriter, r *http.Request, s Sample) {
+	session := s.Session()
+	var err error
+	switch action := r.PathValue("action"); action {
+	case "start":
+		var start FitStart
+		// the configuration is optional
+		if r.ContentLength != 0 {
+			if err := readJSON(r, &start); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+		err = session.StartFit(context.Background(), minimizer.FitConfig{Engine: start.Engine, MaxCalls: start.MaxCalls, Seed: start.Seed})
+	case "pause":
+		err = session.PauseFit()
+	case "resume":
+		err = session.ResumeFit()
+	case "stop":
+		session.StopFit()
+	default:
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("unknown fit action %s", action)})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fitState(session))
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/state"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSample fits a line y = a*x + b to its data sets
+type testSample struct {
+	session        *state.Session
+	recalculations int
+}
+
+func newTestSample() *testSample {
+	s := state.NewSession(func(values []float64, datasets []function.Points) (float64, error) {
+		chi2 := 0.0
+		for _, points := range datasets {
+			for _, p := range points {
+				chi2 += math.Pow((values[0]*p.X+values[1]-p.Y)/p.Error, 2)
+			}
+		}
+		return chi2, nil
+	})
+	_ = s.AddParameter(state.Parameter{Group: "line", Name: "a", Value: 1})
+	_ = s.AddParameter(state.Parameter{Group: "line", Name: "b", Value: 0})
+	return &testSample{session: s}
+}
+
+func (s *testSample) Session() *state.Session {
+	return s.session
+}
+
+func (s *testSample) AddDataset(name string, points function.Points) error {
+	s.session.AddDataset(state.Dataset{Name: name, Points: points})
+	return nil
+}
+
+func (s *testSample) Recalculate() error {
+	s.recalculations++
+	return nil
+}
+
+func (s *testSample) Curve(name string) (function.Points, error) {
+	if name != "line" {
+		return nil, ErrCurveNotFound
+	}
+	values := s.session.Values()
+	return function.Points{{X: 0, Y: values[1]}, {X: 1, Y: values[0] + values[1]}}, nil
+}
+
+// testSamples are the samples of the test server, the first one is selected
+type testSamples struct {
+	names   []string
+	samples []*testSample
+}
+
+func (t *testSamples) Names() []string {
+	return t.names
+}
+
+func (t *testSamples) Sample(name string) (Sample, error) {
+	if name == "" {
+		return t.samples[0], nil
+	}
+	for i, n := range t.names {
+		if n == name {
+			return t.samples[i], nil
+		}
+	}
+	return nil, ErrSampleNotFound
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *testSamples) {
+	samples := &testSamples{names: []string{"Reference", "Modified"}, samples: []*testSample{newTestSample(), newTestSample()}}
+	server := httptest.NewServer(NewHandler(samples))
+	t.Cleanup(server.Close)
+	return server, samples
+}
+
+// sends the request with the body as JSON and decodes the answer into the result
+func request(t *testing.T, method, url string, body, result any) int {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		assert.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	assert.NoError(t, err)
+	if method != "GET" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	if result != nil {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+	}
+	return resp.StatusCode
+}
+
+func TestParameters(t *testing.T) {
+	server, samples := newTestServer(t)
+
+	var names []string
+	assert.Equal(t, http.StatusOK, request(t, "GET", server.URL+"/samples", nil, &names))
+	assert.Equal(t, []string{"Reference", "Modified"}, names)
+
+	var parameters []Parameter
+	assert.Equal(t, http.StatusOK, request(t, "GET", server.URL+"/parameters", nil, &parameters))
+	assert.Equal(t, []Parameter{{Group: "line", Name: "a", Value: 1}, {Group: "line", Name: "b"}}, parameters)
+
+	// only the given fields are changed
+	var changed Parameter
+	value, fit := 2.5, true
+	assert.Equal(t, http.StatusOK, request(t, "PUT", server.URL+"/parameters/line/a?sample=Modified", ParameterChange{Value: &value, Fit: &fit}, &changed))
+	assert.Equal(t, Parameter{Group: "line", Name: "a", Value: 2.5, Fit: true}, changed)
+	p, _ := samples.samples[1].session.Parameter("line", "a")
+	assert.Equal(t, 2.5, p.Value)
+	p, _ = samples.samples[0].session.Parameter("line", "a")
+	assert.Equal(t, 1.0, p.Value)
+
+	var failed errorResponse
+	assert.Equal(t, http.StatusNotFound, request(t, "PUT", server.URL+"/parameters/line/c", ParameterChange{Value: &value}, &failed))
+	assert.Contains(t, failed.Error, "parameter not found")
+	assert.Equal(t, http.StatusNotFound, request(t, "GET", server.URL+"/parameters?sample=Other", nil, &failed))
+	assert.Equal(t, "sample not found", failed.Error)
+	assert.Equal(t, http.StatusBadRequest, request(t, "PUT", server.URL+"/parameters/line/a", map[string]any{"unknown": 1}, &failed))
+}
+
+func TestDatasetsAndCurves(t *testing.T) {
+	server, samples := newTestServer(t)
+
+	var added Dataset
+	upload := Dataset{Name: "line.dat", Points: []Point{{X: 0, Y: 1, Error: 0.1}, {X: 1, Y: 3, Error: 0.1}}}
+	assert.Equal(t, http.StatusCreated, request(t, "POST", server.URL+"/datasets", upload, &added))
+	assert.Equal(t, Dataset{Name: "line.dat", Count: 2}, added)
+	var datasets []Dataset
+	assert.Equal(t, http.StatusOK, request(t, "GET", server.URL+"/datasets", nil, &datasets))
+	assert.Equal(t, []Dataset{{Name: "line.dat", Count: 2}}, datasets)
+	assert.Equal(t, http.StatusBadRequest, request(t, "POST", server.URL+"/datasets", Dataset{Name: "empty.dat"}, nil))
+	var failed errorResponse
+	noError := Dataset{Name: "zero.dat", Points: []Point{{X: 0, Y: 1, Error: 0.1}, {X: 1, Y: 3}}}
+	assert.Equal(t, http.StatusBadRequest, request(t, "POST", server.URL+"/datasets", noError, &failed))
+	assert.Equal(t, "point 2 needs a positive error", failed.Error)
+
+	assert.Equal(t, http.StatusOK, request(t, "POST", server.URL+"/recalculate", nil, nil))
+	assert.Equal(t, 1, samples.samples[0].recalculations)
+
+	var curve []Point
+	assert.Equal(t, http.StatusOK, request(t, "GET", server.URL+"/curves/line", nil, &curve))
+	assert.Equal(t, []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, curve)
+	assert.Equal(t, http.StatusNotFound, request(t, "GET", server.URL+"/curves/eden", nil, nil))
+}
+
+func TestFit(t *testing.T) {
+	server, samples := newTestServer(t)
+	s := samples.samples[0]
+	s.session.AddDataset(state.Dataset{Name: "line.dat", Points: function.Points{{X: 0, Y: 1, Error: 0.1}, {X: 1, Y: 3, Error: 0.1}, {X: 2, Y: 5, Error: 0.1}}})
+	fit := true
+	for _, name := range []string{"a", "b"} {
+		assert.Equal(t, http.StatusOK, request(t, "PUT", server.URL+"/parameters/line/"+name, ParameterChange{Fit: &fit}, nil))
+	}
+
+	var started Fit
+	assert.Equal(t, http.StatusOK, request(t, "POST", server.URL+"/fit/start", nil, &started))
+	assert.Equal(t, 1, started.Run)
+	assert.Equal(t, []string{"a", "b"}, started.Free)
+	s.session.WaitFit()
+
+	var finished Fit
+	assert.Equal(t, http.StatusOK, request(t, "GET", server.URL+"/fit", nil, &finished))
+	assert.Equal(t, "Completed", finished.Status)
+	assert.InDelta(t, 2, finished.Values[0], 1e-6)
+	assert.InDelta(t, 1, finished.Values[1], 1e-6)
+	assert.Len(t, finished.Errors, 2)
+
+	var failed errorResponse
+	assert.Equal(t, http.StatusConflict, request(t, "POST", server.URL+"/fit/pause", nil, &failed))
+	assert.Equal(t, state.ErrNoFit.Error(), failed.Error)
+	assert.Equal(t, http.StatusOK, request(t, "POST", server.URL+"/fit/stop", nil, &finished))
+	assert.Equal(t, "Not Initialized", finished.Status)
+	assert.Equal(t, http.StatusNotFound, request(t, "POST", server.URL+"/fit/restart", nil, nil))
+	assert.Equal(t, http.StatusBadRequest, request(t, "POST", server.URL+"/fit/start", FitStart{Engine: "unknown"}, &failed))
+}
+
+func TestGuard(t *testing.T) {
+	server, samples := newTestServer(t)
+	send := func(method, path, contentType string, body []byte, header map[string]string) int {
+		req, err := http.NewRequest(method, server.URL+path, bytes.NewReader(body))
+		assert.NoError(t, err)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for key, value := range header {
+			if key == "Host" {
+				req.Host = value
+			} else {
+				req.Header.Set(key, value)
+			}
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// a page loaded by DNS rebinding and a page of another site
+	assert.Equal(t, http.StatusForbidden, send("GET", "/parameters", "", nil, map[string]string{"Host": "attacker.example:8765"}))
+	assert.Equal(t, http.StatusForbidden, send("POST", "/fit/start", "application/json", nil, map[string]string{"Origin": "https://attacker.example"}))
+	assert.Equal(t, http.StatusForbidden, send("GET", "/fit", "", nil, map[string]string{"Origin": "null"}))
+	// notebooks served on localhost may use the API
+	assert.Equal(t, http.StatusOK, send("GET", "/fit", "", nil, map[string]string{"Origin": "http://localhost:8888", "Host": "localhost:8765"}))
+	assert.Equal(t, http.StatusOK, send("GET", "/fit", "", nil, map[string]string{"Host": "[::1]:8765"}))
+
+	// simple requests of forms need no preflight
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("POST", "/fit/start", "text/plain", []byte("{}"), nil))
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("POST", "/fit/stop", "", nil, nil))
+	assert.Equal(t, http.StatusOK, send("POST", "/recalculate", "application/json; charset=utf-8", nil, nil))
+	assert.Equal(t, 1, samples.samples[0].recalculations)
+
+	large := []byte(`{"name": "large.dat", "points": [` + strings.Repeat(`{"x": 0.1, "y": 1, "error": 0.1},`, maxBodySize/30) + `]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send("POST", "/datasets", "application/json", large, nil))
+	assert.Empty(t, samples.samples[0].session.Datasets())
+}
+
+func TestServer(t *testing.T) {
+	_, err := Start("0.0.0.0:0", &testSamples{})
+	assert.Error(t, err)
+	_, err = Start("8765", &testSamples{})
+	assert.Error(t, err)
+
+	server, err := Start("127.0.0.1:0", &testSamples{names: []string{"Sample 1"}, samples: []*testSample{newTestSample()}})
+	assert.NoError(t, err)
+	var names []string
+	assert.Equal(t, http.StatusOK, request(t, "GET", server.URL()+"/samples", nil, &names))
+	assert.Equal(t, []string{"Sample 1"}, names)
+
+	assert.NoError(t, server.Close())
+	client := http.Client{Timeout: time.Second}
+	_, err = client.Get(server.URL() + "/samples")
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultAddress is the address the server listens on by default
+const DefaultAddress = "127.0.0.1:8765"
+
+// Server serves the API on a loopback address, other computers can't connect to it
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// Start listens on the address and serves the API in the background
+func Start(address string, samples Samples) (*Server, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("the server only listens on localhost, not on %s", host)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		server:   &http.Server{Handler: NewHandler(samples), ReadHeaderTimeout: 10 * time.Second},
+		listener: listener,
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Println("Error while serving the API:", err)
+		}
+	}()
+	return s, nil
+}
+
+// URL is the base address of the API
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Close stops the server after the running requests
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -80,3 +80,14 @@ func Parse(data []byte) (function.Points, error) {
 
 	return measurements, nil
 }
+
+// CheckErrors returns an error for the first point without a positive error,
+// the penalties of the fits divide by the error
+func CheckErrors(points function.Points) error {
+	for i, p := range points {
+		if !(p.Error > 0) {
+			return fmt.Errorf("point %d needs a positive error", i+1)
+		}
+	}
+	return nil
+}
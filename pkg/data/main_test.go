@@ -3,6 +3,7 @@ package data
 import (
 	"os"
 	"path"
+	"physicsGUI/pkg/function"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -39,3 +40,12 @@ func TestImportAlt(t *testing.T) {
 
 	spew.Dump(data)
 }
+
+func TestCheckErrors(t *testing.T) {
+	if err := CheckErrors(function.Points{{X: 0.1, Y: 1, Error: 0.1}}); err != nil {
+		t.Errorf("expected no error but got %v", err)
+	}
+	if err := CheckErrors(function.Points{{X: 0.1, Y: 1, Error: 0.1}, {X: 0.2, Y: 0.5}}); err == nil {
+		t.Errorf("expected an error for the point without error")
+	}
+}
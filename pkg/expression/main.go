@@ -0,0 +1,86 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+var (
+	// ErrUnknownVariable is returned when an expression references a name that can not be resolved
+	ErrUnknownVariable = errors.New("unknown variable")
+
+	// functions usable inside an expression, f.e. 2*sqrt(Thickness 1)
+	functions = map[string]func(float64) float64{
+		"sqrt": math.Sqrt,
+		"abs":  math.Abs,
+		"exp":  math.Exp,
+		"ln":   math.Log,
+		"log":  math.Log10,
+		"sin":  math.Sin,
+		"cos":  math.Cos,
+	}
+)
+
+// Lookup resolves a variable name to its current value
+type Lookup func(name string) (float64, error)
+
+// Expression is a parsed arithmetic expression which can reference named variables
+type Expression struct {
+	source    string
+	root      node
+	variables []string
+}
+
+// Parse parses an arithmetic expression
+//
+// names are the variables the expression may reference, they can contain spaces and
+// special characters (f.e. "Roughness a/1") and are matched greedily, so the longest name wins
+func Parse(source string, names []string) (*Expression, error) {
+	// longest names first so "Eden 10" is preferred over "Eden 1"
+	sorted := slices.Clone(names)
+	slices.SortFunc(sorted, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	p := &parser{
+		source: []rune(source),
+		names:  sorted,
+	}
+
+	root, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("expression '%s': %w", source, err)
+	}
+
+	variables := make([]string, 0)
+	root.collect(&variables)
+	slices.Sort(variables)
+
+	return &Expression{
+		source:    source,
+		root:      root,
+		variables: slices.Compact(variables),
+	}, nil
+}
+
+// Eval evaluates the expression, variables are resolved with the given lookup
+func (e *Expression) Eval(lookup Lookup) (float64, error) {
+	return e.root.eval(lookup)
+}
+
+// Variables returns the sorted names of all variables referenced by the expression
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// IsConstant returns true if the expression does not reference any variable
+func (e *Expression) IsConstant() bool {
+	return len(e.variables) == 0
+}
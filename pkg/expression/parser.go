@@ -0,0 +1,345 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+// node of the parsed expression tree
+type node interface {
+	eval(lookup Lookup) (float64, error)
+	collect(variables *[]string)
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(Lookup) (float64, error) {
+	return n.value, nil
+}
+
+func (n *numberNode) collect(*[]string) {}
+
+type variableNode struct {
+	name string
+}
+
+func (n *variableNode) eval(lookup Lookup) (float64, error) {
+	if lookup == nil {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownVariable, n.name)
+	}
+	return lookup(n.name)
+}
+
+func (n *variableNode) collect(variables *[]string) {
+	*variables = append(*variables, n.name)
+}
+
+type unaryNode struct {
+	operator rune
+	operand  node
+}
+
+func (n *unaryNode) eval(lookup Lookup) (float64, error) {
+	v, err := n.operand.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	if n.operator == '-' {
+		return -v, nil
+	}
+	return v, nil
+}
+
+func (n *unaryNode) collect(variables *[]string) {
+	n.operand.collect(variables)
+}
+
+type binaryNode struct {
+	operator    rune
+	left, right node
+}
+
+func (n *binaryNode) eval(lookup Lookup) (float64, error) {
+	l, err := n.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.operator {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case '^':
+		v := math.Pow(l, r)
+		if math.IsNaN(v) {
+			return 0, fmt.Errorf("%g^%g is not a number", l, r)
+		}
+		return v, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator '%c'", n.operator)
+}
+
+func (n *binaryNode) collect(variables *[]string) {
+	n.left.collect(variables)
+	n.right.collect(variables)
+}
+
+type functionNode struct {
+	name     string
+	function func(float64) float64
+	argument node
+}
+
+func (n *functionNode) eval(lookup Lookup) (float64, error) {
+	v, err := n.argument.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	result := n.function(v)
+	if math.IsNaN(result) {
+		return 0, fmt.Errorf("%s(%g) is not a number", n.name, v)
+	}
+	return result, nil
+}
+
+func (n *functionNode) collect(variables *[]string) {
+	n.argument.collect(variables)
+}
+
+// recursive descent parser
+//
+//	expression := term (('+' | '-') term)*
+//	term       := unary (('*' | '/') unary)*
+//	unary      := ('+' | '-') unary | power
+//	power      := primary ('^' unary)?
+//	primary    := number | variable | function '(' expression ')' | '(' expression ')'
+type parser struct {
+	source []rune
+	names  []string
+	pos    int
+}
+
+func (p *parser) parse() (node, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.source) {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	n, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.source) {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", string(p.source[p.pos:]), p.pos)
+	}
+
+	return n, nil
+}
+
+func (p *parser) expression() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator, ok := p.accept('+', '-')
+		if !ok {
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator, ok := p.accept('*', '/')
+		if !ok {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if operator, ok := p.accept('+', '-'); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{operator: operator, operand: operand}, nil
+	}
+
+	return p.power()
+}
+
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.accept('^'); ok {
+		exponent, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{operator: '^', left: base, right: exponent}, nil
+	}
+
+	return base, nil
+}
+
+func (p *parser) primary() (node, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.source) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	// parenthesis
+	if _, ok := p.accept('('); ok {
+		inner, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(')'); !ok {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos)
+		}
+		return inner, nil
+	}
+
+	// variables are checked first, their names can contain spaces, digits and operators
+	if name, ok := p.acceptName(); ok {
+		return &variableNode{name: name}, nil
+	}
+
+	r := p.source[p.pos]
+
+	// numbers
+	if unicode.IsDigit(r) || r == '.' {
+		return p.number()
+	}
+
+	// functions
+	if unicode.IsLetter(r) {
+		start := p.pos
+		for p.pos < len(p.source) && unicode.IsLetter(p.source[p.pos]) {
+			p.pos++
+		}
+		name := string(p.source[start:p.pos])
+
+		f, ok := functions[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownVariable, name)
+		}
+		if _, ok := p.accept('('); !ok {
+			return nil, fmt.Errorf("expected '(' after function %s", name)
+		}
+		argument, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(')'); !ok {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos)
+		}
+		return &functionNode{name: name, function: f, argument: argument}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected '%c' at position %d", r, p.pos)
+}
+
+func (p *parser) number() (node, error) {
+	start := p.pos
+	for p.pos < len(p.source) && (unicode.IsDigit(p.source[p.pos]) || p.source[p.pos] == '.') {
+		p.pos++
+	}
+
+	// optional exponent (1e-3, 2.5E+4)
+	if p.pos < len(p.source) && (p.source[p.pos] == 'e' || p.source[p.pos] == 'E') {
+		end := p.pos + 1
+		if end < len(p.source) && (p.source[end] == '+' || p.source[end] == '-') {
+			end++
+		}
+		if end < len(p.source) && unicode.IsDigit(p.source[end]) {
+			p.pos = end
+			for p.pos < len(p.source) && unicode.IsDigit(p.source[p.pos]) {
+				p.pos++
+			}
+		}
+	}
+
+	value, err := strconv.ParseFloat(string(p.source[start:p.pos]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number '%s'", string(p.source[start:p.pos]))
+	}
+
+	return &numberNode{value: value}, nil
+}
+
+// accepts the next non space rune if it is one of the given runes
+func (p *parser) accept(runes ...rune) (rune, bool) {
+	p.skipSpaces()
+	if p.pos >= len(p.source) {
+		return 0, false
+	}
+	for _, r := range runes {
+		if p.source[p.pos] == r {
+			p.pos++
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// accepts the longest known variable name at the current position
+func (p *parser) acceptName() (string, bool) {
+	for _, name := range p.names {
+		n := []rune(name)
+		if len(n) == 0 || p.pos+len(n) > len(p.source) {
+			continue
+		}
+		if string(p.source[p.pos:p.pos+len(n)]) == name {
+			p.pos += len(n)
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.source) && unicode.IsSpace(p.source[p.pos]) {
+		p.pos++
+	}
+}
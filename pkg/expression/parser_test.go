@@ -0,0 +1,114 @@
+package expression
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+var testNames = []string{"Thickness 1", "Thickness 2", "Roughness a/1", "Roughness 1/2", "Eden 1", "Eden 10"}
+
+func testLookup(name string) (float64, error) {
+	values := map[string]float64{
+		"Thickness 1":   10,
+		"Thickness 2":   4,
+		"Roughness a/1": 3,
+		"Roughness 1/2": 2,
+		"Eden 1":        0.5,
+		"Eden 10":       0.25,
+	}
+	if v, ok := values[name]; ok {
+		return v, nil
+	}
+	return 0, ErrUnknownVariable
+}
+
+func TestEval(t *testing.T) {
+	cases := map[string]float64{
+		"0.334":                         0.334,
+		"1e-3":                          1e-3,
+		"2.5E+2":                        250,
+		"1 + 2 * 3":                     7,
+		"(1 + 2) * 3":                   9,
+		"-2^2":                          -4,
+		"2^-1":                          0.5,
+		"2^3^2":                         512,
+		"10 / 4 - 1":                    1.5,
+		"2*Thickness 1":                 20,
+		"Roughness a/1":                 3,
+		"Roughness a/1 / Roughness 1/2": 1.5,
+		"Thickness 1 + Thickness 2":     14,
+		"sqrt(Thickness 2) + abs(-1)":   3,
+		"Eden 10":                       0.25,
+		"Eden 1*2":                      1,
+	}
+
+	for source, expected := range cases {
+		e, err := Parse(source, testNames)
+		if err != nil {
+			t.Errorf("failed to parse '%s': %s", source, err)
+			continue
+		}
+		v, err := e.Eval(testLookup)
+		if err != nil {
+			t.Errorf("failed to evaluate '%s': %s", source, err)
+			continue
+		}
+		if math.Abs(v-expected) > 1e-12 {
+			t.Errorf("'%s' evaluated to %g, expected %g", source, v, expected)
+		}
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e, err := Parse("Thickness 2 + 2*Thickness 1 - Thickness 2", testNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(e.Variables(), []string{"Thickness 1", "Thickness 2"}) {
+		t.Errorf("unexpected variables %v", e.Variables())
+	}
+
+	c, err := Parse("0.334", testNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsConstant() {
+		t.Errorf("expected '0.334' to be constant")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, source := range []string{"", "1 +", "(1 + 2", "Thickness 3", "foo(1)", "sqrt 2", "1 2", "2 * * 3"} {
+		if _, err := Parse(source, testNames); err == nil {
+			t.Errorf("expected '%s' to fail", source)
+		}
+	}
+
+	if _, err := Parse("Thickness 3", testNames); !errors.Is(err, ErrUnknownVariable) {
+		t.Errorf("expected unknown variable error, got %v", err)
+	}
+}
+
+func TestDivisionByZero(t *testing.T) {
+	e, err := Parse("1 / (Thickness 2 - 4)", testNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Eval(testLookup); err == nil {
+		t.Errorf("expected division by zero error")
+	}
+}
+
+func TestNotANumber(t *testing.T) {
+	for _, source := range []string{"sqrt(Thickness 2 - 5)", "ln(-1)", "(Thickness 2 - 5)^0.5"} {
+		e, err := Parse(source, testNames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Eval(testLookup); err == nil {
+			t.Errorf("%s: expected an error for a result which is not a number", source)
+		}
+	}
+}
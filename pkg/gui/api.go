@@ -0,0 +1,99 @@
+package gui
+
+import (
+	"fmt"
+	"physicsGUI/pkg/api"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/state"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// server of the API, nil if it isn't running
+var apiServer *api.Server
+
+// Names returns the names of the samples for the API
+func (t *sampleTabs) Names() []string {
+	list := t.list()
+	names := make([]string, len(list))
+	for i, s := range list {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Sample returns the first sample with the name for the API, the selected sample for an empty name
+func (t *sampleTabs) Sample(name string) (api.Sample, error) {
+	if name == "" {
+		return t.current(), nil
+	}
+	for _, s := range t.list() {
+		if s.name == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", api.ErrSampleNotFound, name)
+}
+
+func (s *sample) Session() *state.Session {
+	return s.session
+}
+
+// AddDataset adds the points sent to the API as data track of the intensity graph
+func (s *sample) AddDataset(name string, points function.Points) error {
+	track := function.NewFunction(points)
+	s.updateDataset(track, func(info *datasetInfo) { info.Imported = time.Now() })
+	s.graphMap["intensity"].AddStyledDataTrack(track, graph.TrackStyle{Name: name})
+	return nil
+}
+
+// Recalculate calculates the functions of the graphs for the API, after a running calculation of the trigger
+func (s *sample) Recalculate() error {
+	return s.calculate()
+}
+
+// Curve returns a copy of the points of a registered function, f.e. eden or intensity
+func (s *sample) Curve(name string) (function.Points, error) {
+	f, ok := s.functionMap[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", api.ErrCurveNotFound, name)
+	}
+	return f.GetData().Copy(), nil
+}
+
+// apiDialog starts the API server on an address or stops it
+func apiDialog() {
+	if apiServer != nil {
+		dialog.ShowConfirm("API Server", fmt.Sprintf("The API is served at %s.\nStop the server?", apiServer.URL()), func(stop bool) {
+			if !stop {
+				return
+			}
+			if err := apiServer.Close(); err != nil {
+				dialog.ShowError(err, MainWindow)
+			}
+			apiServer = nil
+		}, MainWindow)
+		return
+	}
+
+	address := widget.NewEntry()
+	address.SetText(api.DefaultAddress)
+	dialog.ShowForm("API Server", "Start", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Address", address),
+		widget.NewFormItem("", widget.NewLabel("Only programs on this computer can connect.")),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		server, err := api.Start(address.Text, samples)
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		apiServer = server
+		dialog.ShowInformation("API Server", fmt.Sprintf("The API is served at %s.", server.URL()), MainWindow)
+	}, MainWindow)
+}
@@ -0,0 +1,49 @@
+package gui
+
+import (
+	"errors"
+	"physicsGUI/pkg/api"
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPISamples(t *testing.T) {
+	TestSetup(t)
+	tabs := newSampleTabs()
+	first, err := tabs.add()
+	assert.NoError(t, err)
+	second, err := tabs.add()
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		for _, s := range tabs.list() {
+			tabs.remove(s)
+		}
+	})
+	tabs.rename(first, "Reference")
+
+	assert.Equal(t, []string{"Reference", "Sample 2"}, tabs.Names())
+	selected, err := tabs.Sample("")
+	assert.NoError(t, err)
+	assert.Equal(t, second, selected)
+	found, err := tabs.Sample("Reference")
+	assert.NoError(t, err)
+	assert.Equal(t, first, found)
+	_, err = tabs.Sample("Other")
+	assert.True(t, errors.Is(err, api.ErrSampleNotFound))
+
+	// uploaded data sets are data tracks of the intensity graph
+	assert.NoError(t, first.AddDataset("upload", function.Points{{X: 0.02, Y: 0.5, Error: 0.1}, {X: 0.05, Y: 0.01, Error: 0.002}}))
+	datasets := first.Session().Datasets()
+	assert.Len(t, datasets, 1)
+	assert.Equal(t, "upload", datasets[0].Name)
+	assert.False(t, datasets[0].Imported.IsZero())
+
+	assert.NoError(t, first.Recalculate())
+	intensity, err := first.Curve("intensity")
+	assert.NoError(t, err)
+	assert.Len(t, intensity, 2)
+	_, err = first.Curve("other")
+	assert.True(t, errors.Is(err, api.ErrCurveNotFound))
+}
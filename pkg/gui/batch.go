@@ -0,0 +1,426 @@
+package gui
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	batchKeyIndex    = "File index"
+	batchKeyFileName = "Number in file name"
+
+	// default pattern for the number in a file name, the last number wins
+	defaultBatchKeyPattern = `(-?\d+(?:\.\d+)?)[^\d]*$`
+)
+
+// batchFile is one dataset of a batch
+type batchFile struct {
+	Name   string
+	Points function.Points
+}
+
+// batchResult is the fit result of one file of a batch
+type batchResult struct {
+	File      string    `json:"file"`
+	Key       float64   `json:"key"`
+	Values    []float64 `json:"values"`
+	Errors    []float64 `json:"errors"`
+	FVal      float64   `json:"fval"`
+	NFcn      int       `json:"nfcn"`
+	Converged bool      `json:"converged"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// batchFit is the result of a batch, the values of every result are ordered like the names
+type batchFit struct {
+	KeyName string         `json:"key_name"`
+	Names   []string       `json:"names"`
+	Results []*batchResult `json:"results"`
+}
+
+// batchSettings configures a batch fit
+type batchSettings struct {
+	Engine   string
+	MaxCalls int
+	// name of the key and the key of every file, nil uses the file index
+	KeyName string
+	Keys    []float64
+}
+
+// fileKey extracts the number of a file name with the pattern, the first capture group is the number
+func fileKey(name string, pattern *regexp.Regexp) (float64, error) {
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	matches := pattern.FindStringSubmatch(base)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("no number found in file name '%s'", name)
+	}
+	return strconv.ParseFloat(matches[1], 64)
+}
+
+// runBatch fits every file in turn, each fit starts at the result of the previous one
+// the first fit starts at the current parameter values, no dialogs are shown
+// progress is called after every file, the batch stops early once the context is cancelled
+func runBatch(ctx context.Context, files []batchFile, s *state.Session, settings batchSettings, progress func(done int)) *batchFit {
+	fit := &batchFit{
+		KeyName: cmp.Or(settings.KeyName, batchKeyIndex),
+		Names:   s.Names(),
+		Results: make([]*batchResult, 0, len(files)),
+	}
+
+	var start []float64
+	for i, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+
+		result := &batchResult{File: file.Name, Key: float64(i)}
+		if settings.Keys != nil {
+			result.Key = settings.Keys[i]
+		}
+		fit.Results = append(fit.Results, result)
+
+		event, err := batchFitFile(ctx, file, s, start, settings)
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case event.Kind == minimizer.FitFailed || event.Kind == minimizer.FitCancelled:
+			result.Error = event.Err.Error()
+		default:
+			res := event.Result
+			result.Values = res.Parameters
+			result.Errors = res.Errors
+			result.FVal = res.FVal
+			result.NFcn = res.NFcn
+			result.Converged = event.Kind == minimizer.FitConverged
+			start = res.Parameters
+		}
+
+		if progress != nil {
+			progress(i + 1)
+		}
+	}
+
+	return fit
+}
+
+// fits a single file of a batch starting at the given values, nil starts at the current parameter values
+func batchFitFile(ctx context.Context, file batchFile, s *state.Session, start []float64, settings batchSettings) (minimizer.FitEvent, error) {
+	problem, err := s.FitProblem(file.Points)
+	if err != nil {
+		return minimizer.FitEvent{}, err
+	}
+	if start != nil {
+		problem = problem.WithValues(start)
+	}
+
+	return minimizer.RunFit(ctx, problem, minimizer.FitConfig{Engine: settings.Engine, MaxCalls: settings.MaxCalls})
+}
+
+// WriteCSV writes one row per file with the key, the penalty and the value and error of every parameter
+func (b *batchFit) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"file", b.KeyName, "fval", "nfcn", "converged", "error"}
+	for _, name := range b.Names {
+		header = append(header, name, name+" error")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range b.Results {
+		record := []string{
+			r.File,
+			strconv.FormatFloat(r.Key, 'g', -1, 64),
+			strconv.FormatFloat(r.FVal, 'g', -1, 64),
+			strconv.Itoa(r.NFcn),
+			strconv.FormatBool(r.Converged),
+			r.Error,
+		}
+		for i := range b.Names {
+			if r.Values == nil {
+				record = append(record, "", "")
+				continue
+			}
+			record = append(record, strconv.FormatFloat(r.Values[i], 'g', -1, 64), strconv.FormatFloat(r.Errors[i], 'g', -1, 64))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the whole batch as json
+func (b *batchFit) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// trend of a parameter over the key of the successful fits
+func (b *batchFit) trend(param int) function.Points {
+	points := make(function.Points, 0, len(b.Results))
+	for _, r := range b.Results {
+		if r.Values == nil {
+			continue
+		}
+		points = append(points, &function.Point{X: r.Key, Y: r.Values[param], Error: r.Errors[param]})
+	}
+	points.Sort()
+	return points
+}
+
+// reads and parses all files of a folder matching the glob pattern, sorted by name
+func readBatchFolder(folder, pattern string) ([]batchFile, error) {
+	paths, err := filepath.Glob(filepath.Join(folder, pattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files matching '%s' in %s", pattern, folder)
+	}
+	slices.Sort(paths)
+
+	files := make([]batchFile, len(paths))
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		points, err := data.Parse(content)
+		if err == nil {
+			err = data.CheckErrors(points)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		files[i] = batchFile{Name: filepath.Base(path), Points: points}
+	}
+	return files, nil
+}
+
+// batchDialog asks for a folder of datasets and the batch settings, then fits every dataset with the selected sample
+func batchDialog() {
+	s := currentSample()
+	folder := widget.NewEntry()
+	folder.SetPlaceHolder("folder with the datasets")
+	btnFolder := widget.NewButton("Browse", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err == nil && uri != nil {
+				folder.SetText(uri.Path())
+			}
+		}, MainWindow)
+	})
+	pattern := widget.NewEntry()
+	pattern.SetText("*.dat")
+	keyPattern := widget.NewEntry()
+	keyPattern.SetText(defaultBatchKeyPattern)
+	keyPattern.Disable()
+	keyName := widget.NewEntry()
+	keyName.SetPlaceHolder("f.e. Temperature")
+	keyName.Disable()
+	key := widget.NewSelect([]string{batchKeyIndex, batchKeyFileName}, func(s string) {
+		if s == batchKeyFileName {
+			keyPattern.Enable()
+			keyName.Enable()
+		} else {
+			keyPattern.Disable()
+			keyName.Disable()
+		}
+	})
+	key.SetSelected(batchKeyIndex)
+	engine := widget.NewSelect(minimizer.EngineNames(), nil)
+	engine.SetSelected(minimizer.DefaultEngine)
+	maxCalls := widget.NewEntry()
+	maxCalls.SetText("5000")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Folder", container.NewBorder(nil, nil, nil, btnFolder, folder)),
+		widget.NewFormItem("Files", pattern),
+		widget.NewFormItem("X axis", key),
+		widget.NewFormItem("Number pattern", keyPattern),
+		widget.NewFormItem("X axis name", keyName),
+		widget.NewFormItem("Engine", engine),
+		widget.NewFormItem("Max. calls per file", maxCalls),
+	}
+	items[3].HintText = "regular expression, the first group is the number"
+
+	d := dialog.NewForm("Batch Fit", "Fit", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		files, err := readBatchFolder(folder.Text, pattern.Text)
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+
+		settings := batchSettings{Engine: engine.Selected}
+		if settings.MaxCalls, err = strconv.Atoi(maxCalls.Text); err != nil || settings.MaxCalls <= 0 {
+			dialog.ShowError(errors.New("max. calls must be a positive number"), MainWindow)
+			return
+		}
+
+		if key.Selected == batchKeyFileName {
+			re, err := regexp.Compile(keyPattern.Text)
+			if err != nil {
+				dialog.ShowError(err, MainWindow)
+				return
+			}
+			settings.KeyName = cmp.Or(keyName.Text, "Key")
+			settings.Keys = make([]float64, len(files))
+			for i, f := range files {
+				if settings.Keys[i], err = fileKey(f.Name, re); err != nil {
+					dialog.ShowError(err, MainWindow)
+					return
+				}
+			}
+			// fit in the order of the key, so every fit starts at the closest previous result
+			order := make([]int, len(files))
+			for i := range order {
+				order[i] = i
+			}
+			slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(settings.Keys[a], settings.Keys[b]) })
+			sortedFiles := make([]batchFile, len(files))
+			sortedKeys := make([]float64, len(files))
+			for i, o := range order {
+				sortedFiles[i] = files[o]
+				sortedKeys[i] = settings.Keys[o]
+			}
+			files, settings.Keys = sortedFiles, sortedKeys
+		}
+
+		startBatch(s, files, settings)
+	}, MainWindow)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+// fits the files in the background and shows the results afterwards
+func startBatch(s *sample, files []batchFile, settings batchSettings) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := widget.NewProgressBar()
+	status := widget.NewLabel(fmt.Sprintf("0 / %d files", len(files)))
+	btnCancel := widget.NewButton("Cancel", cancel)
+	d := dialog.NewCustomWithoutButtons("Batch Fit", container.NewVBox(status, progress, btnCancel), MainWindow)
+	d.Resize(fyne.NewSize(400, 120))
+	d.Show()
+
+	go func() {
+		defer cancel()
+		fit := runBatch(ctx, files, s.session, settings, func(done int) {
+			progress.SetValue(float64(done) / float64(len(files)))
+			status.SetText(fmt.Sprintf("%d / %d files", done, len(files)))
+		})
+		d.Hide()
+		showBatchResults(fit)
+	}()
+}
+
+// showBatchResults opens a window with the table of all fits and the trend of a parameter
+func showBatchResults(fit *batchFit) {
+	w := fyne.CurrentApp().NewWindow("Batch Fit Results")
+
+	header := append([]string{"File", fit.KeyName, "FVal", "Calls", "Converged"}, fit.Names...)
+	table := widget.NewTable(
+		func() (int, int) { return len(fit.Results), len(header) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			r := fit.Results[id.Row]
+			label := o.(*widget.Label)
+			switch {
+			case id.Col == 0:
+				label.SetText(r.File)
+			case id.Col == 1:
+				label.SetText(fmt.Sprintf("%g", r.Key))
+			case r.Values == nil:
+				label.SetText(r.Error)
+			case id.Col == 2:
+				label.SetText(fmt.Sprintf("%g", r.FVal))
+			case id.Col == 3:
+				label.SetText(strconv.Itoa(r.NFcn))
+			case id.Col == 4:
+				label.SetText(strconv.FormatBool(r.Converged))
+			default:
+				i := id.Col - 5
+				label.SetText(fmt.Sprintf("%g ± %g", r.Values[i], r.Errors[i]))
+			}
+		})
+	table.ShowHeaderRow = true
+	table.CreateHeader = func() fyne.CanvasObject { return widget.NewLabel("") }
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		if id.Col >= 0 {
+			o.(*widget.Label).SetText(header[id.Col])
+		}
+	}
+	table.StickyColumnCount = 2
+	for i := range header {
+		table.SetColumnWidth(i, 130)
+	}
+
+	trend := function.NewEmptyFunction()
+	trendGraph := graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:     "Trend",
+		Functions: function.Functions{trend},
+	})
+	selParam := widget.NewSelect(fit.Names, func(name string) {
+		trend.SetData(fit.trend(slices.Index(fit.Names, name)))
+		trendGraph.Config.Title = fmt.Sprintf("%s vs %s", name, fit.KeyName)
+		trendGraph.Refresh()
+	})
+	if len(fit.Names) > 0 {
+		selParam.SetSelected(fit.Names[0])
+	}
+
+	export := func(write func(*batchFit, io.Writer) error) func() {
+		return func() {
+			dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if writer == nil {
+					return // user abort
+				}
+				defer writer.Close()
+				if err := write(fit, writer); err != nil {
+					dialog.ShowError(err, w)
+				}
+			}, w)
+		}
+	}
+	btnCSV := widget.NewButton("Export CSV", export((*batchFit).WriteCSV))
+	btnJSON := widget.NewButton("Export JSON", export((*batchFit).WriteJSON))
+
+	content := container.NewVSplit(
+		table,
+		container.NewBorder(container.NewHBox(widget.NewLabel("Parameter"), selParam), nil, nil, nil, trendGraph),
+	)
+	w.SetContent(container.NewBorder(nil, container.NewHBox(btnCSV, btnJSON), nil, nil, content))
+	w.Resize(fyne.NewSize(1000, 700))
+	w.Show()
+}
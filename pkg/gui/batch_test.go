@@ -0,0 +1,111 @@
+package gui
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/physics"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKey(t *testing.T) {
+	re := regexp.MustCompile(defaultBatchKeyPattern)
+
+	key, err := fileKey("sample_run3_25.5K.dat", re)
+	assert.NoError(t, err)
+	assert.Equal(t, 25.5, key)
+
+	key, err = fileKey("/data/T-10.dat", re)
+	assert.NoError(t, err)
+	assert.Equal(t, -10.0, key)
+
+	_, err = fileKey("reference.dat", re)
+	assert.Error(t, err)
+}
+
+// creates a dataset of the model with the given parameter values
+func syntheticDataset(t *testing.T, values []float64) function.Points {
+	edenPoints, err := physics.GetEdensities(values[0:4], values[4:6], values[6:9])
+	assert.NoError(t, err)
+
+	qz := make([]float64, 0)
+	for q := 0.02; q < 0.3; q += 0.005 {
+		qz = append(qz, q)
+	}
+	points := physics.CalculateIntensityPointsOn(qz, edenPoints, values[9], &physics.IntensityOptions{
+		Background: values[10],
+		Scaling:    values[11],
+	})
+	for _, p := range points {
+		p.Error = 0.01 * p.Y
+	}
+	return points
+}
+
+func TestRunBatch(t *testing.T) {
+	TestSetup(t)
+	values := testSample.session.Values()
+
+	// only "Eden a" is checked, every dataset has another value of it
+	targets := []float64{values[0] + 0.02, values[0] + 0.03}
+	files := make([]batchFile, len(targets))
+	for i, target := range targets {
+		v := append([]float64{}, values...)
+		v[0] = target
+		files[i] = batchFile{Name: "file", Points: syntheticDataset(t, v)}
+	}
+
+	fit := runBatch(context.Background(), files, testSample.session, batchSettings{MaxCalls: 2000, KeyName: "Temperature", Keys: []float64{10, 20}}, nil)
+
+	assert.Len(t, fit.Results, 2)
+	for i, r := range fit.Results {
+		assert.Empty(t, r.Error)
+		assert.True(t, r.Converged)
+		assert.InDelta(t, targets[i], r.Values[0], 1e-3)
+		// the other parameters stay fixed
+		assert.Equal(t, values[1:], r.Values[1:])
+	}
+	assert.Equal(t, 20.0, fit.Results[1].Key)
+
+	// the parameters are not changed by a batch
+	assert.Equal(t, values, testSample.session.Values())
+	current, _ := testSample.fitParameters()[0].Get()
+	assert.Equal(t, values[0], current)
+
+	trend := fit.trend(0)
+	assert.Len(t, trend, 2)
+	assert.Equal(t, 10.0, trend[0].X)
+}
+
+func TestBatchExport(t *testing.T) {
+	fit := &batchFit{
+		KeyName: "Temperature",
+		Names:   []string{"a", "b"},
+		Results: []*batchResult{
+			{File: "1.dat", Key: 10, Values: []float64{1, 2}, Errors: []float64{0.1, 0.2}, FVal: 3, NFcn: 40, Converged: true},
+			{File: "2.dat", Key: 20, Error: "no data"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, fit.WriteCSV(buf))
+	records, err := csv.NewReader(buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"file", "Temperature", "fval", "nfcn", "converged", "error", "a", "a error", "b", "b error"}, records[0])
+	assert.Equal(t, []string{"1.dat", "10", "3", "40", "true", "", "1", "0.1", "2", "0.2"}, records[1])
+	assert.Equal(t, "no data", records[2][5])
+
+	buf.Reset()
+	assert.NoError(t, fit.WriteJSON(buf))
+	decoded := &batchFit{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), decoded))
+	assert.Equal(t, fit.Names, decoded.Names)
+	assert.Equal(t, fit.Results[0].Values, decoded.Results[0].Values)
+	assert.False(t, math.IsNaN(decoded.Results[0].FVal))
+}
@@ -0,0 +1,57 @@
+package gui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+func createParameterMenu() *fyne.Menu {
+	mnConstraints := fyne.NewMenuItem("Constraints", constraintEditor)
+	return fyne.NewMenu("Parameters", mnConstraints)
+}
+
+func createAnalysisMenu() *fyne.Menu {
+	mnBatch := fyne.NewMenuItem("Batch Fit", batchDialog)
+	mnMCMC := fyne.NewMenuItem("MCMC Uncertainties", mcmcDialog)
+	mnRecipe := fyne.NewMenuItem("Fit Recipe", recipeDialog)
+	return fyne.NewMenu("Analysis", mnBatch, mnMCMC, mnRecipe)
+}
+
+// constraintEditor shows a dialog to edit the parameter constraints of the selected sample
+// every line holds one constraint in the form "Parameter = Expression"
+func constraintEditor() {
+	s := currentSample()
+	lines := make([]string, 0)
+	for _, c := range s.params.GetConstraints() {
+		lines = append(lines, c.String())
+	}
+
+	editor := widget.NewMultiLineEntry()
+	editor.SetText(strings.Join(lines, "\n"))
+	editor.SetPlaceHolder("Roughness 1/2 = Roughness a/1\nThickness 2 = 2*Thickness 1\nEden b = 0.334")
+	editor.SetMinRowsVisible(8)
+
+	help := widget.NewLabel("One constraint per line: Parameter = Expression\n" +
+		"Expressions can use parameter names, numbers, + - * / ^, parentheses and sqrt, abs, exp, ln, log, sin, cos.\n" +
+		"Constrained parameters are derived from the expression and are never fitted.")
+
+	content := container.NewBorder(help, nil, nil, nil, editor)
+
+	d := dialog.NewCustomConfirm("Parameter Constraints", "Apply", "Cancel", content, func(apply bool) {
+		if !apply {
+			return
+		}
+		if err := s.params.SetConstraints(strings.Split(editor.Text, "\n")); err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		s.recalc.Request()
+		s.table.Refresh()
+	}, MainWindow)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
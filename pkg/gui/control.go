@@ -1,365 +1,289 @@
 package gui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math"
 	"physicsGUI/pkg/gui/helper"
-	"physicsGUI/pkg/gui/param"
 	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/recipe"
+	"physicsGUI/pkg/state"
+	"strconv"
 	"sync"
-	"time"
+	"sync/atomic"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	minuit "github.com/empack/minuit2go/pkg"
 )
 
-type MinimizerState int
-
-const (
-	MinimizerNotStarted = MinimizerState(0x1 << iota)
-	MinimizerRunning    = MinimizerState(0x1 << iota)
-	MinimizerPaused     = MinimizerState(0x1 << iota)
-	MinimizerFinished   = MinimizerState(0x1 << iota)
-	MinimizerFailed     = MinimizerState(0x1 << iota)
-)
-
-type SharedMinimizerData struct {
-	rw       sync.RWMutex
-	mnParams *minuit.MnUserParameters
-	mFunc    *minimizer.MinuitFunction
-	err      error
-}
-
-func (controlPanel *MinimizerControlPanel) MinuitUpdateHandler() {
-	var migrad *minuit.MnMigrad
-	var migrad2 *minuit.MnMigrad
-	lastError := math.MaxFloat64
-
-	stateReader := func() MinimizerState {
-		controlPanel.rw.RLock()
-		defer controlPanel.rw.RUnlock()
-		return controlPanel.state
-	}
-
-	for {
-		switch stateReader() {
-		case MinimizerRunning:
-			controlPanel.sharedStorage.rw.Lock()
-			if migrad == nil {
-				// create migrad
-				migrad = minuit.NewMnMigradWithParameters(controlPanel.sharedStorage.mFunc, controlPanel.sharedStorage.mnParams)
-			}
-
-			res, err := migrad.MinimizeWithMaxfcn(50)
-
-			if err != nil {
-				controlPanel.SetStats(err, 0, 0)
-				controlPanel.sharedStorage.err = err
-				migrad = nil
-				migrad2 = nil
-				controlPanel.sharedStorage.rw.Unlock()
-				controlPanel.Failed(err)
-				continue
-			}
-
-			if !res.IsValid() {
-				if migrad2 == nil {
-					migrad2 = minuit.NewMnMigradWithParameterStateStrategy(controlPanel.sharedStorage.mFunc, res.UserState(), minuit.NewMnStrategyWithStra(minuit.PreciseStrategy))
-				}
-				res, err = migrad2.MinimizeWithMaxfcn(50)
-				if err != nil {
-					controlPanel.SetStats(err, 0, 0)
-					controlPanel.sharedStorage.err = err
-					migrad = nil
-					migrad2 = nil
-					controlPanel.sharedStorage.rw.Unlock()
-					controlPanel.Failed(err)
-					continue
-				}
-			}
-			controlPanel.SetStats(err, res.Fval(), res.Nfcn())
-			_ = controlPanel.sharedStorage.mFunc.UpdateParameters(res.UserParameters().Params())
-			controlPanel.sharedStorage.rw.Unlock()
-			if res.Fval() == lastError {
-				migrad = nil
-				migrad2 = nil
-				lastError = math.MaxFloat64
-				controlPanel.Completed(res.UserParameters().Errors())
-			} else {
-				lastError = res.Fval()
-			}
-			continue
-		case MinimizerPaused:
-			time.Sleep(500 * time.Millisecond)
-			continue
-		case MinimizerFinished | MinimizerFailed | MinimizerNotStarted:
-			migrad = nil
-			migrad2 = nil
-			lastError = math.MaxFloat64
-			time.Sleep(1000 * time.Millisecond)
-			continue
-		}
-	}
-}
-
+// MinimizerControlPanel starts, pauses and stops the fit of a session and shows its state
+// it observes the session, so fits started elsewhere are shown as well
 type MinimizerControlPanel struct {
-	rw               sync.RWMutex
-	state            MinimizerState
-	btnPause         *widget.Button
-	btnContinue      *widget.Button
-	btnStop          *widget.Button
-	btnStart         *widget.Button
-	lblNCalls        *widget.Label
-	lblFVal          *widget.Label
-	lblError         *widget.Label
-	lblStatus        *widget.Label
-	oldMinimizerData []float64
-	sharedStorage    *SharedMinimizerData
+	rw          sync.RWMutex
+	session     *state.Session
+	btnPause    *widget.Button
+	btnContinue *widget.Button
+	btnStop     *widget.Button
+	btnStart    *widget.Button
+	lblNCalls   *widget.Label
+	lblFVal     *widget.Label
+	lblError    *widget.Label
+	lblStatus   *widget.Label
+	selEngine   *widget.Select
+	// strategy of the Minuit engines and analyses
+	selStrategy *widget.Select
+	// seed of the engines with random numbers, empty for a random seed
+	entSeed    *widget.Entry
+	btnScan    *widget.Button
+	btnMinos   *widget.Button
+	btnContour *widget.Button
+	progress   *fitProgress
+	// the last shown fit, its status and result
+	shown state.FitState
+	// no dialogs are shown at the end of fits, f.e. while a recipe runs its steps
+	quiet     atomic.Bool
+	unobserve func()
+	// called when the panel is no longer busy, f.e. at the end of a fit or a recipe
+	OnIdle func()
 }
 
-func NewMinimizerControlPanel() *MinimizerControlPanel {
+func NewMinimizerControlPanel(s *state.Session) *MinimizerControlPanel {
 	pnlControl := &MinimizerControlPanel{
-		rw:               sync.RWMutex{},
-		state:            MinimizerNotStarted,
-		btnPause:         nil,
-		btnContinue:      nil,
-		btnStop:          nil,
-		btnStart:         nil,
-		lblNCalls:        widget.NewLabel("Calls: -"),
-		lblFVal:          widget.NewLabel("FVal: -"),
-		lblError:         widget.NewLabel(""),
-		lblStatus:        widget.NewLabel("Not Initialized"),
-		oldMinimizerData: nil,
-		sharedStorage:    &SharedMinimizerData{},
+		session:   s,
+		lblNCalls: widget.NewLabel("Calls: -"),
+		lblFVal:   widget.NewLabel("FVal: -"),
+		lblError:  widget.NewLabel(""),
+		lblStatus: widget.NewLabel(state.FitIdle.String()),
+		selEngine: widget.NewSelect(minimizer.EngineNames(), nil),
+		progress:  newFitProgress(),
+	}
+	strategies := make([]string, len(minimizer.Strategies))
+	for i, strategy := range minimizer.Strategies {
+		strategies[i] = strategy.String()
 	}
-	go pnlControl.MinuitUpdateHandler()
+	pnlControl.selStrategy = widget.NewSelect(strategies, nil)
+	pnlControl.selStrategy.SetSelectedIndex(0)
+	pnlControl.entSeed = widget.NewEntry()
+	pnlControl.entSeed.SetPlaceHolder("random seed")
+	pnlControl.selEngine.OnChanged = pnlControl.engineChanged
+	pnlControl.selEngine.SetSelected(minimizer.DefaultEngine)
 	pnlControl.lblError.Hide()
 	pnlControl.btnPause = widget.NewButtonWithIcon("Pause", theme.MediaPauseIcon(), pnlControl.Pause)
-	pnlControl.btnPause.Disable()
-	pnlControl.btnPause.Hide()
 	pnlControl.btnContinue = widget.NewButtonWithIcon("Resume", theme.NavigateNextIcon(), pnlControl.Continue)
-	pnlControl.btnContinue.Disable()
-	pnlControl.btnContinue.Hide()
 	pnlControl.btnStart = widget.NewButtonWithIcon("Start", theme.MediaPlayIcon(), pnlControl.Start)
 	pnlControl.btnStop = widget.NewButtonWithIcon("Stop", theme.MediaStopIcon(), pnlControl.Stop)
-	pnlControl.btnStop.Disable()
-	pnlControl.btnStop.Hide()
+	pnlControl.btnScan = widget.NewButton("Scan", func() { scanDialog(s, pnlControl.minuitConfig()) })
+	pnlControl.btnMinos = widget.NewButton("Minos", func() { minosDialog(s, pnlControl.minuitConfig()) })
+	pnlControl.btnContour = widget.NewButton("Contour", func() { contourDialog(s, pnlControl.minuitConfig()) })
+
+	pnlControl.shown = s.Fit()
+	pnlControl.showButtons(pnlControl.shown.Status)
+	pnlControl.unobserve = s.Subscribe(func(e state.Event) {
+		if e.Kind == state.FitChanged {
+			pnlControl.update()
+		}
+	})
 	return pnlControl
 }
 
-func (controlPanel *MinimizerControlPanel) Widget() fyne.CanvasObject {
-	return container.NewHBox(controlPanel.btnStart, controlPanel.btnContinue, controlPanel.btnPause, controlPanel.btnStop, helper.CreateSeparator(), container.NewVBox(container.NewHBox(controlPanel.lblError, controlPanel.lblFVal, controlPanel.lblNCalls), helper.CreateSeparator(), controlPanel.lblStatus))
+// ProgressWidget shows the convergence and the parameter traces of the current or last fit
+func (controlPanel *MinimizerControlPanel) ProgressWidget() fyne.CanvasObject {
+	return controlPanel.progress.Widget()
 }
 
-func (controlPanel *MinimizerControlPanel) Pause() {
-	if controlPanel.state == MinimizerRunning {
-		controlPanel.btnStart.Disable()
-		controlPanel.btnStart.Hide()
-		controlPanel.btnPause.Disable()
-		controlPanel.btnPause.Hide()
-		controlPanel.btnContinue.Disable()
-		controlPanel.btnContinue.Hide()
-		controlPanel.btnStop.Disable()
-		controlPanel.btnStop.Hide()
-
-		controlPanel.state = MinimizerPaused
-
-		// this blocks until current cycle is completed
-		controlPanel.sharedStorage.rw.Lock()
-		controlPanel.sharedStorage.rw.Unlock()
+func (controlPanel *MinimizerControlPanel) Widget() fyne.CanvasObject {
+	return container.NewHBox(controlPanel.selEngine, controlPanel.selStrategy, container.NewGridWrap(fyne.NewSize(160, controlPanel.entSeed.MinSize().Height), controlPanel.entSeed), controlPanel.btnStart, controlPanel.btnContinue, controlPanel.btnPause, controlPanel.btnStop, helper.CreateSeparator(), controlPanel.btnScan, controlPanel.btnMinos, controlPanel.btnContour, helper.CreateSeparator(), container.NewVBox(container.NewHBox(controlPanel.lblError, controlPanel.lblFVal, controlPanel.lblNCalls), helper.CreateSeparator(), controlPanel.lblStatus))
+}
 
-		controlPanel.lblStatus.SetText("Paused")
+// State returns the status of the fit of the session
+func (controlPanel *MinimizerControlPanel) State() state.FitStatus {
+	return controlPanel.session.Fit().Status
+}
 
-		controlPanel.btnContinue.Enable()
-		controlPanel.btnContinue.Show()
-		controlPanel.btnStop.Enable()
-		controlPanel.btnStop.Show()
+// Close stops observing the session
+func (controlPanel *MinimizerControlPanel) Close() {
+	controlPanel.unobserve()
+}
 
+// shows the buttons of the actions which are possible in the status
+func (controlPanel *MinimizerControlPanel) showButtons(status state.FitStatus) {
+	buttons := map[*widget.Button]bool{
+		controlPanel.btnStart:    !status.Active(),
+		controlPanel.btnPause:    status == state.FitRunning,
+		controlPanel.btnContinue: status == state.FitPaused,
+		controlPanel.btnStop:     status.Active(),
+		controlPanel.btnScan:     !status.Active(),
+		controlPanel.btnMinos:    !status.Active(),
+		controlPanel.btnContour:  !status.Active(),
+	}
+	for button, visible := range buttons {
+		if visible {
+			button.Enable()
+			button.Show()
+		} else {
+			button.Disable()
+			button.Hide()
+		}
 	}
 }
 
-func (controlPanel *MinimizerControlPanel) Continue() {
-	if controlPanel.state == MinimizerPaused {
-		controlPanel.btnStart.Disable()
-		controlPanel.btnStart.Hide()
-		controlPanel.btnPause.Disable()
-		controlPanel.btnPause.Hide()
-		controlPanel.btnContinue.Disable()
-		controlPanel.btnContinue.Hide()
-		controlPanel.btnStop.Disable()
-		controlPanel.btnStop.Hide()
+// shows the state of the fit of the session, the end of a fit is reported by a dialog
+func (controlPanel *MinimizerControlPanel) update() {
+	fit := controlPanel.session.Fit()
 
-		controlPanel.state = MinimizerRunning
+	controlPanel.rw.Lock()
+	last := controlPanel.shown
+	controlPanel.shown = fit
+	controlPanel.rw.Unlock()
 
-		// this blocks until current cycle is completed
-		controlPanel.sharedStorage.rw.Lock()
-		controlPanel.sharedStorage.rw.Unlock()
-		controlPanel.lblStatus.SetText("Running")
+	if fit.Run != last.Run {
+		controlPanel.progress.reset(controlPanel.session.Names(), fit.Free)
+		if fit.Seed != 0 {
+			// the seed repeats the fit
+			controlPanel.entSeed.SetPlaceHolder(fmt.Sprintf("random, last %d", fit.Seed))
+		}
+	}
+	if fit.Result != nil && fit.Result != last.Result {
+		controlPanel.progress.record(fit.Result)
+	}
 
-		controlPanel.btnPause.Enable()
-		controlPanel.btnPause.Show()
-		controlPanel.btnStop.Enable()
-		controlPanel.btnStop.Show()
+	controlPanel.showButtons(fit.Status)
+	controlPanel.lblStatus.SetText(fit.Status.String())
+	switch {
+	case fit.Status == state.FitIdle:
+		controlPanel.SetStats(nil, 0, 0)
+	case fit.Err != nil && fit.Result == nil:
+		controlPanel.SetStats(fit.Err, 0, 0)
+	case fit.Result != nil:
+		controlPanel.SetStats(nil, fit.Result.FVal, fit.Result.NFcn)
+	default:
+		controlPanel.SetStats(nil, 0, 0)
 	}
+
+	if (fit.Status == last.Status && fit.Run == last.Run) || controlPanel.quiet.Load() {
+		return
+	}
+	controlPanel.showEnd(fit)
+	controlPanel.idle()
 }
 
-func (controlPanel *MinimizerControlPanel) Start() {
-	if controlPanel.state == MinimizerNotStarted || controlPanel.state == MinimizerFinished || controlPanel.state == MinimizerFailed {
-		err := controlPanel.minimizerProblemSetup()
-		if err != nil {
-			dialog.ShowError(err, MainWindow)
-			return
+// reports the end of a fit by a dialog
+func (controlPanel *MinimizerControlPanel) showEnd(fit state.FitState) {
+	switch fit.Status {
+	case state.FitConverged:
+		fitErrors := make([]float64, 0)
+		if fit.Result != nil {
+			fitErrors = fit.Result.Errors
 		}
-		controlPanel.sharedStorage.rw.RLock()
-		controlPanel.oldMinimizerData = controlPanel.sharedStorage.mnParams.Params()
-		controlPanel.sharedStorage.rw.RUnlock()
-
-		controlPanel.btnStart.Disable()
-		controlPanel.btnStart.Hide()
-		controlPanel.btnPause.Disable()
-		controlPanel.btnPause.Hide()
-		controlPanel.btnContinue.Disable()
-		controlPanel.btnContinue.Hide()
-		controlPanel.btnStop.Disable()
-		controlPanel.btnStop.Hide()
-
-		controlPanel.state = MinimizerPaused
-
-		// this blocks until current cycle is completed
-		controlPanel.sharedStorage.rw.Lock()
-		controlPanel.sharedStorage.rw.Unlock()
-		controlPanel.lblStatus.SetText("Ready")
-
-		controlPanel.Continue()
+		dialog.ShowInformation("Minimizer Completed", fmt.Sprintf("Minimizer finished. No further improvements found.\n Errors: %v", fitErrors), MainWindow)
+	case state.FitFailed:
+		//TODO ask user if he wants to use data?
+		dialog.ShowError(fit.Err, MainWindow)
 	}
 }
 
-func (controlPanel *MinimizerControlPanel) Stop() {
-	controlPanel.btnStart.Disable()
-	controlPanel.btnStart.Hide()
-	controlPanel.btnPause.Disable()
-	controlPanel.btnPause.Hide()
-	controlPanel.btnContinue.Disable()
-	controlPanel.btnContinue.Hide()
-	controlPanel.btnStop.Disable()
-	controlPanel.btnStop.Hide()
-
-	controlPanel.Reset()
-	controlPanel.SetStats(nil, 0, 0)
-
-	controlPanel.sharedStorage.rw.Lock()
-	_ = controlPanel.sharedStorage.mFunc.UpdateParameters(controlPanel.oldMinimizerData)
-	controlPanel.sharedStorage.rw.Unlock()
-	controlPanel.lblStatus.SetText("Not Initialized")
+// SetQuiet turns the dialogs at the end of fits off or on again
+func (controlPanel *MinimizerControlPanel) SetQuiet(quiet bool) {
+	controlPanel.quiet.Store(quiet)
+	if !quiet {
+		controlPanel.idle()
+	}
 }
 
-func (controlPanel *MinimizerControlPanel) Reset() {
-	controlPanel.btnStart.Disable()
-	controlPanel.btnStart.Hide()
-	controlPanel.btnPause.Disable()
-	controlPanel.btnPause.Hide()
-	controlPanel.btnContinue.Disable()
-	controlPanel.btnContinue.Hide()
-	controlPanel.btnStop.Disable()
-	controlPanel.btnStop.Hide()
-
-	controlPanel.state = MinimizerNotStarted
-
-	// this blocks until current cycle is completed
-	controlPanel.sharedStorage.rw.Lock()
-	controlPanel.sharedStorage.rw.Unlock()
-	controlPanel.lblStatus.SetText("Not Initialized")
-	controlPanel.btnStart.Enable()
-	controlPanel.btnStart.Show()
-
+// Busy reports whether a fit, a recipe or the stages of a fit are running
+func (controlPanel *MinimizerControlPanel) Busy() bool {
+	return controlPanel.quiet.Load() || controlPanel.session.Fit().Status.Active()
 }
 
-func (controlPanel *MinimizerControlPanel) Completed(errors []float64) {
-	controlPanel.Reset()
-	dialog.ShowInformation("Minimizer Completed", fmt.Sprintf("Minimizer finished. No further improvements found.\n Minuit Erros: %v", errors), MainWindow)
-	controlPanel.state = MinimizerFinished
-	// this blocks until current cycle is completed
-	controlPanel.sharedStorage.rw.Lock()
-	controlPanel.sharedStorage.rw.Unlock()
-	controlPanel.lblStatus.SetText("Completed")
+func (controlPanel *MinimizerControlPanel) idle() {
+	if controlPanel.OnIdle != nil && !controlPanel.Busy() {
+		controlPanel.OnIdle()
+	}
 }
 
-func (controlPanel *MinimizerControlPanel) Failed(err error) {
-	controlPanel.Reset()
-	dialog.ShowError(err, MainWindow)
-	//TODO ask user if he wants to use data?
-	controlPanel.state = MinimizerFailed
-	// this blocks until current cycle is completed
-	controlPanel.sharedStorage.rw.Lock()
-	controlPanel.sharedStorage.rw.Unlock()
-	controlPanel.lblStatus.SetText("Failed")
+// the strategy is only used by the engines running Migrad, the seed by the engines with random numbers
+func (controlPanel *MinimizerControlPanel) engineChanged(engine string) {
+	if minimizer.EngineUses(engine, minimizer.SettingStrategy) {
+		controlPanel.selStrategy.Enable()
+	} else {
+		controlPanel.selStrategy.Disable()
+	}
+	if minimizer.EngineUses(engine, minimizer.SettingSeed) {
+		controlPanel.entSeed.Enable()
+	} else {
+		controlPanel.entSeed.Disable()
+	}
 }
 
-func (controlPanel *MinimizerControlPanel) minimize(parameters ...*param.Parameter[float64]) error {
-	mnParams := minuit.NewEmptyMnUserParameters()
-
-	var freeToChangeCnt int = 0
-
-	for i, p := range parameters {
-		if p == nil {
-			return fmt.Errorf("minimizer: parameter %d is nil", i)
-		}
-
-		par, err := p.Get()
-		if err != nil {
-			return err
-		}
-
-		id := fmt.Sprintf("p%d", i)
-
-		// if not checked, add as constant parameter
-		if !p.IsChecked() {
-			mnParams.Add(id, par)
-			continue
-		}
-
-		min := p.GetRelative("min")
-		max := p.GetRelative("max")
-
-		// if min or max is nil, add as free parameter
-		if min == nil || max == nil {
-			mnParams.AddFree(id, par, 0.1)
-			freeToChangeCnt++
-			continue
-		}
-
-		// if min and max are set, add as limited parameter
-		minV, err := min.Get()
-		if err != nil {
-			return err
-		}
+// settings of the Scan, Minos and Contour analyses
+func (controlPanel *MinimizerControlPanel) minuitConfig() minimizer.MinuitConfig {
+	return minimizer.MinuitConfig{Strategy: minimizer.Strategies[controlPanel.selStrategy.SelectedIndex()]}
+}
 
-		maxV, err := max.Get()
+// Start fits the checked parameters with the selected engine and strategy
+// parameters of different fit orders are fitted in stages, starting with the lowest order
+func (controlPanel *MinimizerControlPanel) Start() {
+	if controlPanel.quiet.Load() {
+		return // a recipe or the stages of a fit are running
+	}
+	config := minimizer.FitConfig{Engine: controlPanel.selEngine.Selected, Strategy: controlPanel.minuitConfig().Strategy}
+	if text := controlPanel.entSeed.Text; text != "" && !controlPanel.entSeed.Disabled() {
+		seed, err := strconv.ParseUint(text, 10, 64)
 		if err != nil {
-			return err
+			dialog.ShowError(fmt.Errorf("seed: %w", err), MainWindow)
+			return
 		}
-
-		mnParams.AddLimited(id, par, 0.1, minV, maxV)
-		freeToChangeCnt++
+		config.Seed = seed
+	}
+	template := recipe.Step{Engine: config.Engine, Strategy: config.Strategy.String(), Seed: config.Seed}
+	if r := recipe.FromOrder(controlPanel.session.Parameters(), template); r != nil {
+		controlPanel.startStages(r)
+		return
 	}
+	err := controlPanel.session.StartFit(context.Background(), config)
+	if err != nil && !errors.Is(err, state.ErrFitRunning) {
+		dialog.ShowError(err, MainWindow)
+	}
+}
 
-	if freeToChangeCnt == 0 {
-		return fmt.Errorf("minimizer: No parameter(s) selected to be minimized")
+// fits the steps of the fit order in the background, the panel shows every step and reports the end of the last one
+func (controlPanel *MinimizerControlPanel) startStages(r *recipe.Recipe) {
+	if controlPanel.session.Fit().Status.Active() {
+		return
 	}
+	objective := controlPanel.session.Objective()
+	merits := func(string) (state.Objective, error) { return objective, nil }
+
+	controlPanel.SetQuiet(true)
+	go func() {
+		_, err := recipe.Run(context.Background(), controlPanel.session, r, merits, nil)
+		controlPanel.SetQuiet(false)
+		switch {
+		case errors.Is(err, recipe.ErrStopped):
+		case err != nil:
+			dialog.ShowError(err, MainWindow)
+		default:
+			controlPanel.showEnd(controlPanel.session.Fit())
+		}
+	}()
+}
 
-	// create minuit setup
-	mFunc := minimizer.NewMinuitFcn(penaltyFunction, parameters)
+// Pause stops the fit after the current iteration
+func (controlPanel *MinimizerControlPanel) Pause() {
+	_ = controlPanel.session.PauseFit()
+}
 
-	controlPanel.sharedStorage.rw.Lock()
-	controlPanel.sharedStorage.mFunc = mFunc
-	controlPanel.sharedStorage.mnParams = mnParams
-	controlPanel.sharedStorage.rw.Unlock()
+// Continue resumes a paused fit
+func (controlPanel *MinimizerControlPanel) Continue() {
+	_ = controlPanel.session.ResumeFit()
+}
 
-	return nil
+// Stop cancels the fit and restores the parameters it started with
+func (controlPanel *MinimizerControlPanel) Stop() {
+	controlPanel.session.StopFit()
 }
 
 func (controlPanel *MinimizerControlPanel) SetStats(err error, fVal float64, nCalls int) {
@@ -1,9 +1,11 @@
 package gui
 
 import (
+	"context"
+	"math"
 	"physicsGUI/pkg/function"
-	"physicsGUI/pkg/gui/param"
-	"runtime"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
 	"testing"
 	"time"
 
@@ -11,141 +13,234 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-var once bool = false
+// sample of the tests, its recalculation is not started so the tests recalculate themselves
+var testSample *sample
 
 func TestSetup(t *testing.T) {
-	if once {
+	if testSample != nil {
 		return
 	}
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	MainWindow = test.NewWindow(pnlMinimizerUUt.Widget())
-	registerParams()
-	param.GetFloatGroup("eden").GetParam("Eden a").SetCheck(true)
-	registerFunctions()
-	registerGraphs()
-	graphMap["intensity"].AddDataTrack(function.NewEmptyFunction())
-	// Set GODEBUG to enable scheduler trace for debugging
-	t.Setenv("GODEBUG", "schedtrace=1000")
-	// Limit to a single OS thread to control goroutines
-	runtime.GOMAXPROCS(1)
-	once = true
+	MainWindow = test.NewWindow(nil)
+	s, err := newSample("Sample 1")
+	assert.NoError(t, err)
+	assert.NoError(t, s.session.UpdateParameter("eden", "Eden a", func(p *state.Parameter) { p.Fit = true }))
+	s.graphMap["intensity"].AddDataTrack(function.NewEmptyFunction())
+	testSample = s
 }
 
-func TestNewMinimizerControlPanel(t *testing.T) {
+// creates a panel of its own session, the slow penalty keeps a fit running for a while
+func newTestPanel(t *testing.T) (*MinimizerControlPanel, *state.Session) {
 	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	s := state.NewSession(func(values []float64, datasets []function.Points) (float64, error) {
+		time.Sleep(time.Millisecond)
+		return math.Pow(values[0]-1, 2), nil
+	})
+	assert.NoError(t, s.AddParameter(state.Parameter{Group: "test", Name: "x", Fit: true}))
+	pnlMinimizerUUt := NewMinimizerControlPanel(s)
+	t.Cleanup(func() {
+		s.StopFit()
+		pnlMinimizerUUt.Close()
+	})
+	return pnlMinimizerUUt, s
+}
+
+func TestNewMinimizerControlPanel(t *testing.T) {
+	pnlMinimizerUUt, _ := newTestPanel(t)
 	assert.NotNil(t, pnlMinimizerUUt)
-	assert.Equal(t, MinimizerNotStarted, pnlMinimizerUUt.state)
-	assert.NotNil(t, pnlMinimizerUUt.sharedStorage)
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
+	assert.True(t, pnlMinimizerUUt.btnStart.Visible())
+	assert.False(t, pnlMinimizerUUt.btnPause.Visible())
+	assert.Equal(t, "Not Initialized", pnlMinimizerUUt.lblStatus.Text)
 }
 
 func TestMinimizerControlPanel_Start(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	pnlMinimizerUUt, _ := newTestPanel(t)
 	pnlMinimizerUUt.Start()
-	assert.Equal(t, MinimizerRunning, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
+	assert.True(t, pnlMinimizerUUt.btnPause.Visible())
+	assert.True(t, pnlMinimizerUUt.btnStop.Visible())
+	assert.False(t, pnlMinimizerUUt.btnStart.Visible())
+	assert.Equal(t, "Running", pnlMinimizerUUt.lblStatus.Text)
 }
 
 func TestMinimizerControlPanel_Pause(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	pnlMinimizerUUt.state = MinimizerRunning
+	pnlMinimizerUUt, _ := newTestPanel(t)
+	pnlMinimizerUUt.Start()
 	pnlMinimizerUUt.Pause()
-	assert.Equal(t, MinimizerPaused, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitPaused, pnlMinimizerUUt.State())
+	assert.True(t, pnlMinimizerUUt.btnContinue.Visible())
+	assert.False(t, pnlMinimizerUUt.btnPause.Visible())
 }
 
 func TestMinimizerControlPanel_Continue(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	pnlMinimizerUUt.state = MinimizerPaused
+	pnlMinimizerUUt, _ := newTestPanel(t)
+	pnlMinimizerUUt.Start()
+	pnlMinimizerUUt.Pause()
 	pnlMinimizerUUt.Continue()
-	assert.Equal(t, MinimizerRunning, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
 }
 
 func TestMinimizerControlPanel_Stop(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	pnlMinimizerUUt, s := newTestPanel(t)
 	pnlMinimizerUUt.Start()
 	pnlMinimizerUUt.Stop()
-	assert.Equal(t, MinimizerNotStarted, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
+	assert.Equal(t, []float64{0}, s.Values())
+	assert.True(t, pnlMinimizerUUt.btnStart.Visible())
 }
 
 func TestMinimizerControlPanel_Completed(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	pnlMinimizerUUt.Completed(make([]float64, 0))
-	assert.Equal(t, MinimizerFinished, pnlMinimizerUUt.state)
+	pnlMinimizerUUt, s := newTestPanel(t)
+	pnlMinimizerUUt.Start()
+	assert.Equal(t, state.FitConverged, s.WaitFit().Status)
+	assert.Equal(t, state.FitConverged, pnlMinimizerUUt.State())
+	assert.Equal(t, "Completed", pnlMinimizerUUt.lblStatus.Text)
+	assert.NotEqual(t, "FVal: -", pnlMinimizerUUt.lblFVal.Text)
+	assert.InDelta(t, 1, s.Values()[0], 1e-4)
 }
 
 func TestMinimizerControlPanel_Failed(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	err := pnlMinimizerUUt.minimize(nil)
-	pnlMinimizerUUt.Failed(err)
-	assert.Equal(t, MinimizerFailed, pnlMinimizerUUt.state)
+	pnlMinimizerUUt, s := newTestPanel(t)
+
+	// fits started elsewhere are shown as well
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{Engine: minimizer.EngineDE, MaxCalls: 10, IterationCalls: 5}))
+	assert.Equal(t, state.FitFailed, s.WaitFit().Status)
+	assert.Equal(t, state.FitFailed, pnlMinimizerUUt.State())
+	assert.Equal(t, "Failed", pnlMinimizerUUt.lblStatus.Text)
+
+	// without free parameters the fit is not started
+	assert.NoError(t, s.UpdateParameter("test", "x", func(p *state.Parameter) { p.Fit = false }))
+	pnlMinimizerUUt.Stop()
+	pnlMinimizerUUt.Start()
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
 }
 
 func TestMinimizerControlPanel_SetStats(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	pnlMinimizerUUt, _ := newTestPanel(t)
 	pnlMinimizerUUt.SetStats(nil, 10.5, 5)
 	assert.Equal(t, "FVal: 10.5", pnlMinimizerUUt.lblFVal.Text)
 	assert.Equal(t, "Calls: 5", pnlMinimizerUUt.lblNCalls.Text)
 }
 
 func TestButtonStart(t *testing.T) {
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	pnlMinimizerUUt, _ := newTestPanel(t)
 	test.Tap(pnlMinimizerUUt.btnStart)
-	assert.Equal(t, MinimizerRunning, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
 }
 
 func TestButtonPause(t *testing.T) {
-	t.Skipf("Cant test in with dummy function, because minimizer is instant finished and sharedStorage is not set up") //TODO when better id or changed structure fo control.go and main.go
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	pnlMinimizerUUt, _ := newTestPanel(t)
 	pnlMinimizerUUt.Start()
 	test.Tap(pnlMinimizerUUt.btnPause)
-	time.Sleep(500 * time.Millisecond)
-	assert.Equal(t, MinimizerPaused, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitPaused, pnlMinimizerUUt.State())
 }
 
 func TestButtonContinue(t *testing.T) {
-	t.Skipf("Cant test in with dummy function, because minimizer is instant finished and sharedStorage is not set up") //TODO when better id or changed structure fo control.go and main.go
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	pnlMinimizerUUt.state = MinimizerPaused
+	pnlMinimizerUUt, _ := newTestPanel(t)
+	pnlMinimizerUUt.Start()
+	pnlMinimizerUUt.Pause()
 	test.Tap(pnlMinimizerUUt.btnContinue)
-	time.Sleep(500 * time.Millisecond)
-	assert.Equal(t, MinimizerRunning, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
 }
 
 func TestButtonStop(t *testing.T) {
-	t.Skipf("Cant test in with dummy function, because minimizer is instant finished and sharedStorage is not set up") //TODO when better id or changed structure fo control.go and main.go
+	pnlMinimizerUUt, _ := newTestPanel(t)
+	pnlMinimizerUUt.Start()
+	test.Tap(pnlMinimizerUUt.btnStop)
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
+}
 
+func TestMinimizerControlPanel_Restart(t *testing.T) {
+	pnlMinimizerUUt, s := newTestPanel(t)
+
+	// a finished or failed minimizer can be started again
+	pnlMinimizerUUt.Start()
+	assert.Equal(t, state.FitConverged, s.WaitFit().Status)
+	pnlMinimizerUUt.Start()
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
+	assert.Equal(t, 2, s.Fit().Run)
+	pnlMinimizerUUt.Stop()
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
+
+	// a paused minimizer is not started again
+	pnlMinimizerUUt.Start()
+	pnlMinimizerUUt.Pause()
+	pnlMinimizerUUt.Start()
+	assert.Equal(t, state.FitPaused, pnlMinimizerUUt.State())
+	assert.Equal(t, 3, s.Fit().Run)
+}
+
+func TestMinimizerControlPanel_StopRestoresParameters(t *testing.T) {
 	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
-	pnlMinimizerUUt.state = MinimizerRunning
-	test.Tap(pnlMinimizerUUt.btnStop)
-	time.Sleep(500 * time.Millisecond)
-	assert.Equal(t, MinimizerNotStarted, pnlMinimizerUUt.state)
+	pnlMinimizerUUt := NewMinimizerControlPanel(testSample.session)
+	defer pnlMinimizerUUt.Close()
+	p := testSample.params.GetFloatGroup("eden").GetParam("Eden a")
+	before, _ := p.Get()
+
+	pnlMinimizerUUt.Start()
+	pnlMinimizerUUt.Stop()
+
+	after, _ := p.Get()
+	assert.Equal(t, before, after)
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
 }
 
 func TestButtonSequence(t *testing.T) {
-	t.Skipf("Cant test in with dummy function, because minimizer is instant finished and sharedStorage is not set up") //TODO when better id or changed structure fo control.go and main.go
-	TestSetup(t)
-	pnlMinimizerUUt := NewMinimizerControlPanel()
+	pnlMinimizerUUt, _ := newTestPanel(t)
 
 	// try trigger continue, should be ignored
 	test.Tap(pnlMinimizerUUt.btnContinue)
-	time.Sleep(500 * time.Millisecond)
-	assert.Equal(t, MinimizerNotStarted, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
 
 	// trigger start and pause afterwards
 	test.Tap(pnlMinimizerUUt.btnStart)
-	time.Sleep(500 * time.Millisecond)
-	assert.Equal(t, MinimizerRunning, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
 	test.Tap(pnlMinimizerUUt.btnPause)
-	time.Sleep(500 * time.Millisecond)
-	assert.Equal(t, MinimizerPaused, pnlMinimizerUUt.state)
+	assert.Equal(t, state.FitPaused, pnlMinimizerUUt.State())
+	test.Tap(pnlMinimizerUUt.btnContinue)
+	assert.Equal(t, state.FitRunning, pnlMinimizerUUt.State())
+	test.Tap(pnlMinimizerUUt.btnStop)
+	assert.Equal(t, state.FitIdle, pnlMinimizerUUt.State())
+}
+
+func TestMinimizerControlPanel_Strategy(t *testing.T) {
+	pnlMinimizerUUt, _ := newTestPanel(t)
+	assert.Equal(t, minimizer.StrategyAuto.String(), pnlMinimizerUUt.selStrategy.Selected)
+	pnlMinimizerUUt.selStrategy.SetSelected(minimizer.StrategyPrecise.String())
+	assert.Equal(t, minimizer.StrategyPrecise, pnlMinimizerUUt.minuitConfig().Strategy)
+
+	// the strategy is only used by engines with Minuit
+	pnlMinimizerUUt.selEngine.SetSelected(minimizer.EngineDE)
+	assert.True(t, pnlMinimizerUUt.selStrategy.Disabled())
+	pnlMinimizerUUt.selEngine.SetSelected(minimizer.EngineDEMigrad)
+	assert.False(t, pnlMinimizerUUt.selStrategy.Disabled())
+
+	// the analyses are hidden while a fit runs
+	assert.True(t, pnlMinimizerUUt.btnContour.Visible())
+	pnlMinimizerUUt.Start()
+	assert.False(t, pnlMinimizerUUt.btnScan.Visible())
+	assert.False(t, pnlMinimizerUUt.btnMinos.Visible())
+}
+
+func TestMinimizerControlPanel_Order(t *testing.T) {
+	TestSetup(t)
+	s := state.NewSession(func(values []float64, datasets []function.Points) (float64, error) {
+		return math.Pow(values[0]-1, 2) + math.Pow(values[1]-2, 2), nil
+	})
+	assert.NoError(t, s.AddParameter(state.Parameter{Group: "test", Name: "x", Fit: true}))
+	assert.NoError(t, s.AddParameter(state.Parameter{Group: "test", Name: "y", Fit: true, Order: 1}))
+	pnlMinimizerUUt := NewMinimizerControlPanel(s)
+	t.Cleanup(pnlMinimizerUUt.Close)
+
+	// x is fitted first, then x and y together
+	pnlMinimizerUUt.Start()
+	assert.Eventually(t, func() bool {
+		return !pnlMinimizerUUt.quiet.Load() && s.Fit().Status == state.FitConverged
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []int{0, 1}, s.Fit().Free)
+	values := s.Values()
+	assert.InDelta(t, 1, values[0], 1e-6)
+	assert.InDelta(t, 2, values[1], 1e-6)
+	x, _ := s.Parameter("test", "x")
+	assert.True(t, x.Fit)
 }
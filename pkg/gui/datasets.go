@@ -0,0 +1,331 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/state"
+	"physicsGUI/pkg/watch"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// datasetInfo is the metadata of a data track which isn't part of the graph
+type datasetInfo struct {
+	// file the data was imported from and the time of the import, empty if unknown
+	Source   string
+	Imported time.Time
+	Notes    string
+	// excluded data tracks are shown but not fitted
+	Excluded bool
+}
+
+// dataset returns a copy of the metadata of a data track
+func (s *sample) dataset(track *function.Function) datasetInfo {
+	s.datasetsLock.Lock()
+	defer s.datasetsLock.Unlock()
+	if info := s.datasets[track]; info != nil {
+		return *info
+	}
+	return datasetInfo{}
+}
+
+// updateDataset changes the metadata of a data track, it is created on first use
+// the metadata is also changed by the reloads of the recalculation outside the UI thread
+func (s *sample) updateDataset(track *function.Function, update func(info *datasetInfo)) {
+	s.datasetsLock.Lock()
+	defer s.datasetsLock.Unlock()
+	info, ok := s.datasets[track]
+	if !ok {
+		info = &datasetInfo{}
+		s.datasets[track] = info
+	}
+	update(info)
+}
+
+// addDataTrack adds the points imported from the file to a graph, the track is named by the file
+func (s *sample) addDataTrack(graphName string, points function.Points, source string) {
+	track := function.NewFunction(points)
+	s.updateDataset(track, func(info *datasetInfo) {
+		*info = datasetInfo{Source: source, Imported: time.Now()}
+	})
+	s.graphMap[graphName].AddStyledDataTrack(track, graph.TrackStyle{Name: filepath.Base(source)})
+}
+
+// reloadDataTrack reads the points of a data track of the intensity graph again from its file
+func (s *sample) reloadDataTrack(track *function.Function) error {
+	info := s.dataset(track)
+	if info.Source == "" {
+		return errors.New("the data track was not imported from a file")
+	}
+	bytes, err := os.ReadFile(info.Source)
+	if err != nil {
+		return err
+	}
+	points, err := data.Parse(bytes)
+	if err == nil {
+		err = data.CheckErrors(points)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", info.Source, err)
+	}
+	if len(points) == 0 {
+		return errors.New("no data")
+	}
+	s.updateDataset(track, func(info *datasetInfo) { info.Imported = time.Now() })
+	s.graphMap["intensity"].SetDataTrackPoints(track, points)
+	return nil
+}
+
+// duplicateDataTrack adds a copy of the points, the style and the metadata of the data track with the index
+func (s *sample) duplicateDataTrack(i int) {
+	g := s.graphMap["intensity"]
+	track := g.GetDataTracks()[i]
+	duplicate := function.NewFunction(track.GetData().Copy())
+	info := s.dataset(track)
+	s.updateDataset(duplicate, func(copied *datasetInfo) { *copied = info })
+	style := g.DataTrackStyle(i)
+	style.Name += " (copy)"
+	g.AddStyledDataTrack(duplicate, style)
+}
+
+// rescaleDataTrack multiplies the intensities and their errors of a data track by the factor
+func (s *sample) rescaleDataTrack(track *function.Function, factor float64) error {
+	if factor <= 0 {
+		return errors.New("the factor needs to be positive")
+	}
+	points := track.GetData().Copy()
+	for _, p := range points {
+		p.Y *= factor
+		p.Error *= factor
+	}
+	s.graphMap["intensity"].SetDataTrackPoints(track, points)
+	return nil
+}
+
+// excludeDataTrack removes a data track from the fits or adds it again, the track stays in the graph
+func (s *sample) excludeDataTrack(track *function.Function, excluded bool) {
+	s.updateDataset(track, func(info *datasetInfo) { info.Excluded = excluded })
+	s.syncDatasets()
+}
+
+// watchFiles reloads the data tracks of the intensity graph when their files change on disk
+func (s *sample) watchFiles(enabled bool) error {
+	s.datasetsLock.Lock()
+	defer s.datasetsLock.Unlock()
+	if !enabled {
+		if s.watcher == nil {
+			return nil
+		}
+		err := s.watcher.Close()
+		s.watcher = nil
+		return err
+	}
+	if s.watcher != nil {
+		return nil
+	}
+	w, err := watch.New(watch.DefaultDelay, s.fileChanged)
+	if err != nil {
+		return err
+	}
+	s.watcher = w
+	return s.watchSources()
+}
+
+// watches the files of the data tracks and no other files, the lock must be held
+func (s *sample) watchSources() error {
+	if s.watcher == nil {
+		return nil
+	}
+	sources := make(map[string]bool)
+	for _, info := range s.datasets {
+		if info.Source != "" {
+			sources[filepath.Clean(info.Source)] = true
+		}
+	}
+	for _, path := range s.watcher.Files() {
+		if !sources[path] {
+			if err := s.watcher.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	for path := range sources {
+		if err := s.watcher.Add(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileChanged queues the reload of a changed file for the recalculation, it is called by the watcher
+// so the data tracks are changed by the recalculation only and never under a running fit
+func (s *sample) fileChanged(path string) {
+	s.datasetsLock.Lock()
+	if s.reloads == nil {
+		s.reloads = make(map[string]bool)
+	}
+	s.reloads[path] = true
+	s.datasetsLock.Unlock()
+	s.recalc.Request()
+}
+
+// reloadsPending reports whether changed files wait for their reload
+func (s *sample) reloadsPending() bool {
+	s.datasetsLock.Lock()
+	defer s.datasetsLock.Unlock()
+	return len(s.reloads) > 0
+}
+
+// applyReloads reloads the data tracks of the changed files and fits them if selected,
+// while a fit, a recipe or the stages of a fit are running the reloads are kept until they end
+func (s *sample) applyReloads() {
+	if s.control.Busy() {
+		return
+	}
+	s.datasetsLock.Lock()
+	paths := s.reloads
+	s.reloads = nil
+	s.datasetsLock.Unlock()
+	if len(paths) == 0 {
+		return
+	}
+
+	reloaded := false
+	for _, track := range s.graphMap["intensity"].GetDataTracks() {
+		if !paths[filepath.Clean(s.dataset(track).Source)] {
+			continue
+		}
+		// the file may be incomplete while the next points are written
+		if err := s.reloadDataTrack(track); err != nil {
+			log.Println("Error while reloading data track:", err)
+			continue
+		}
+		reloaded = true
+	}
+	if reloaded && s.refit.Load() {
+		s.control.Start()
+	}
+}
+
+// datasetPanel lists the data tracks of the intensity graph with their metadata and statistics
+type datasetPanel struct {
+	sample *sample
+	rows   *fyne.Container
+}
+
+func newDatasetPanel(s *sample) *datasetPanel {
+	p := &datasetPanel{sample: s, rows: container.NewVBox()}
+	p.refresh()
+	// the data sets of the session follow the data tracks
+	s.session.Subscribe(func(e state.Event) {
+		if e.Kind == state.DatasetsChanged {
+			p.refresh()
+		}
+	})
+	return p
+}
+
+func (p *datasetPanel) Widget() fyne.CanvasObject {
+	s := p.sample
+	watching := widget.NewCheck("Reload changed files", func(b bool) {
+		if err := s.watchFiles(b); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	})
+	refit := widget.NewCheck("Fit after reload", func(b bool) { s.refit.Store(b) })
+	return container.NewBorder(container.NewHBox(watching, refit), nil, nil, nil, container.NewVScroll(p.rows))
+}
+
+// refresh creates the rows of the data tracks again
+func (p *datasetPanel) refresh() {
+	p.rows.RemoveAll()
+	p.rows.Add(container.NewGridWithColumns(8,
+		widget.NewLabel("Name"), widget.NewLabel("File"), widget.NewLabel("Points"), widget.NewLabel("q range [Å⁻¹]"),
+		widget.NewLabel("Imported"), widget.NewLabel("Notes"), widget.NewLabel("Fit"), widget.NewLabel("")))
+	tracks := p.sample.graphMap["intensity"].GetDataTracks()
+	if len(tracks) == 0 {
+		p.rows.Add(widget.NewLabel("No data tracks loaded, drop a data file on the intensity graph"))
+	}
+	for i := range tracks {
+		p.rows.Add(p.row(i))
+	}
+}
+
+// widgets showing the data track with the index and its actions
+func (p *datasetPanel) row(i int) fyne.CanvasObject {
+	s := p.sample
+	g := s.graphMap["intensity"]
+	track := g.GetDataTracks()[i]
+	info := s.dataset(track)
+
+	source := widget.NewLabel("-")
+	if info.Source != "" {
+		source.SetText(filepath.Base(info.Source))
+	}
+	imported := widget.NewLabel("-")
+	if !info.Imported.IsZero() {
+		imported.SetText(info.Imported.Format("2006-01-02 15:04"))
+	}
+	qRange := widget.NewLabel("-")
+	if points := track.GetData(); len(points) > 0 {
+		minX, maxX, _, _ := points.MinMaxXY()
+		qRange.SetText(fmt.Sprintf("%.4g – %.4g", minX, maxX))
+	}
+
+	notes := widget.NewEntry()
+	notes.SetText(info.Notes)
+	notes.OnChanged = func(text string) {
+		s.updateDataset(track, func(info *datasetInfo) { info.Notes = text })
+	}
+
+	fit := widget.NewCheck("", nil)
+	fit.SetChecked(!info.Excluded)
+	fit.OnChanged = func(b bool) { s.excludeDataTrack(track, !b) }
+
+	reload := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if err := s.reloadDataTrack(track); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	})
+	reload.Disable()
+	if info.Source != "" {
+		reload.Enable()
+	}
+	duplicate := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() { s.duplicateDataTrack(i) })
+	rescale := widget.NewButton("Rescale", func() { rescaleDialog(s, track) })
+
+	return container.NewGridWithColumns(8,
+		widget.NewLabel(g.DataTrackStyle(i).Name), source, widget.NewLabel(strconv.Itoa(track.GetDataCount())), qRange,
+		imported, notes, fit, container.NewHBox(reload, duplicate, rescale))
+}
+
+// rescaleDialog asks for the factor the intensities of a data track are multiplied with
+func rescaleDialog(s *sample, track *function.Function) {
+	factor := widget.NewEntry()
+	factor.SetText("1")
+	factor.Validator = func(text string) error {
+		_, err := strconv.ParseFloat(text, 64)
+		return err
+	}
+	dialog.ShowForm("Rescale Data Track", "Rescale", "Cancel", []*widget.FormItem{widget.NewFormItem("Factor", factor)}, func(ok bool) {
+		if !ok {
+			return
+		}
+		value, _ := strconv.ParseFloat(factor.Text, 64)
+		if err := s.rescaleDataTrack(track, value); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	}, MainWindow)
+}
@@ -0,0 +1,125 @@
+package gui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatasetManagement(t *testing.T) {
+	TestSetup(t)
+	s, err := newSample("Data")
+	assert.NoError(t, err)
+	t.Cleanup(s.close)
+	g := s.graphMap["intensity"]
+
+	file := filepath.Join(t.TempDir(), "sample.dat")
+	assert.NoError(t, os.WriteFile(file, []byte("2\n0.02 0.5 0.1\n0.05 0.01 0.002\n"), 0644))
+	s.addDataTrack("intensity", function.Points{{X: 0.02, Y: 0.5, Error: 0.1}}, file)
+	track := g.GetDataTracks()[0]
+	assert.Equal(t, "sample.dat", g.DataTrackStyle(0).Name)
+	datasets := s.session.Datasets()
+	assert.Len(t, datasets, 1)
+	assert.Equal(t, file, datasets[0].Source)
+	assert.False(t, datasets[0].Imported.IsZero())
+	// header and one row
+	assert.Len(t, s.data.rows.Objects, 2)
+
+	// reload reads the changed file
+	assert.NoError(t, s.reloadDataTrack(track))
+	assert.Equal(t, 2, track.GetDataCount())
+	assert.Equal(t, []float64{0.02, 0.05}, s.qzAxis())
+
+	assert.NoError(t, s.rescaleDataTrack(track, 10))
+	assert.Equal(t, function.Point{X: 0.05, Y: 0.1, Error: 0.02}, *track.GetData()[1])
+	assert.Error(t, s.rescaleDataTrack(track, 0))
+
+	s.updateDataset(track, func(info *datasetInfo) { info.Notes = "annealed" })
+	s.duplicateDataTrack(0)
+	duplicate := g.GetDataTracks()[1]
+	assert.Equal(t, "sample.dat (copy)", g.DataTrackStyle(1).Name)
+	assert.Equal(t, track.GetData(), duplicate.GetData())
+	assert.NotSame(t, track.GetData()[0], duplicate.GetData()[0])
+	assert.Equal(t, "annealed", s.dataset(duplicate).Notes)
+	assert.Len(t, s.data.rows.Objects, 3)
+
+	// excluded tracks stay in the graph but are not fitted
+	assert.NoError(t, s.session.UpdateParameter("general", "scaling", func(p *state.Parameter) { p.Fit = true }))
+	s.excludeDataTrack(duplicate, true)
+	assert.Len(t, g.GetDataTracks(), 2)
+	assert.True(t, s.session.Datasets()[1].Excluded)
+	problem, err := s.session.FitProblem(track.GetData())
+	assert.NoError(t, err)
+	excluded, err := s.session.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, problem.Function(problem.Values), excluded.Function(excluded.Values))
+
+	// the metadata is stored in the project files
+	config, err := s.CreateConfig()
+	assert.NoError(t, err)
+	loaded, err := newSample("Loaded")
+	assert.NoError(t, err)
+	t.Cleanup(loaded.close)
+	assert.NoError(t, loaded.LoadConfig(config, false))
+	tracks := loaded.graphMap["intensity"].GetDataTracks()
+	assert.Len(t, tracks, 2)
+	stored := loaded.dataset(tracks[0])
+	assert.Equal(t, file, stored.Source)
+	assert.Equal(t, "annealed", stored.Notes)
+	assert.WithinDuration(t, s.dataset(track).Imported, stored.Imported, time.Second)
+	assert.True(t, loaded.dataset(tracks[1]).Excluded)
+
+	// the metadata of removed tracks is dropped
+	g.RemoveDataTrack(duplicate)
+	assert.NotContains(t, s.datasets, duplicate)
+	s.updateDataset(track, func(info *datasetInfo) { info.Source = "" })
+	assert.Error(t, s.reloadDataTrack(track))
+}
+
+func TestWatchDataFiles(t *testing.T) {
+	TestSetup(t)
+	s, err := newSample("Watched")
+	assert.NoError(t, err)
+	t.Cleanup(s.close)
+	s.recalc.Start()
+	assert.NoError(t, s.session.UpdateParameter("general", "scaling", func(p *state.Parameter) { p.Fit = true }))
+
+	file := filepath.Join(t.TempDir(), "running.dat")
+	assert.NoError(t, os.WriteFile(file, []byte("1\n0.02 0.5 0.1\n"), 0644))
+	s.addDataTrack("intensity", function.Points{{X: 0.02, Y: 0.5, Error: 0.1}}, file)
+	track := s.graphMap["intensity"].GetDataTracks()[0]
+	assert.NoError(t, s.watchFiles(true))
+	assert.Equal(t, []string{file}, s.watcher.Files())
+	s.refit.Store(true)
+
+	// new points of a running measurement are loaded and fitted
+	assert.NoError(t, os.WriteFile(file, []byte("2\n0.02 0.5 0.1\n0.05 0.01 0.002\n"), 0644))
+	assert.Eventually(t, func() bool { return track.GetDataCount() == 2 }, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return s.session.Fit().Run == 1 }, 2*time.Second, 10*time.Millisecond)
+	s.session.WaitFit()
+	assert.Equal(t, []float64{0.02, 0.05}, s.qzAxis())
+
+	// the data of a running fit is not changed, the reload follows at its end
+	s.refit.Store(false)
+	assert.NoError(t, s.session.StartFit(context.Background(), minimizer.FitConfig{}))
+	assert.NoError(t, s.session.PauseFit())
+	assert.NoError(t, os.WriteFile(file, []byte("3\n0.02 0.5 0.1\n0.05 0.01 0.002\n0.08 0.001 0.0002\n"), 0644))
+	assert.Eventually(t, s.reloadsPending, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, track.GetDataCount())
+	s.session.StopFit()
+	assert.Eventually(t, func() bool { return track.GetDataCount() == 3 }, 2*time.Second, 10*time.Millisecond)
+
+	// files of removed tracks aren't watched
+	s.graphMap["intensity"].RemoveDataTrack(track)
+	assert.Empty(t, s.watcher.Files())
+	assert.NoError(t, s.watchFiles(false))
+	assert.Nil(t, s.watcher)
+}
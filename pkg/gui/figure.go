@@ -0,0 +1,124 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"physicsGUI/pkg/gui/graph"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// formats of exported figures
+const (
+	figureSVG = "SVG"
+	figurePNG = "PNG"
+	figurePDF = "PDF"
+)
+
+// figureSettings are the options of an exported figure
+type figureSettings struct {
+	Format string
+	Width  float32
+	Height float32
+	// resolution of PNG figures
+	DPI float64
+}
+
+// writeFigure draws the graph with the print theme and writes it in the format of the settings
+func writeFigure(w io.Writer, g *graph.GraphCanvas, settings figureSettings) error {
+	plot := g.Plot(fyne.NewSize(settings.Width, settings.Height), graph.PrintTheme)
+	switch settings.Format {
+	case figureSVG:
+		return plot.WriteSVG(w)
+	case figurePNG:
+		return plot.WritePNG(w, settings.DPI)
+	case figurePDF:
+		return plot.WritePDF(w)
+	default:
+		return fmt.Errorf("unknown figure format %s", settings.Format)
+	}
+}
+
+// figureDialog asks for a graph of the selected sample and the format and saves the figure
+func figureDialog() {
+	s := currentSample()
+	names := make([]string, 0, len(s.graphMap))
+	for name := range s.graphMap {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	graphSelect := widget.NewSelect(names, nil)
+	graphSelect.SetSelected("intensity")
+	width := widget.NewEntry()
+	width.SetText("800")
+	height := widget.NewEntry()
+	height.SetText("500")
+	dpi := widget.NewEntry()
+	dpi.SetText("300")
+	format := widget.NewSelect([]string{figureSVG, figurePNG, figurePDF}, func(s string) {
+		if s == figurePNG {
+			dpi.Enable()
+		} else {
+			dpi.Disable()
+		}
+	})
+	format.SetSelected(figureSVG)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Graph", graphSelect),
+		widget.NewFormItem("Format", format),
+		widget.NewFormItem("Width", width),
+		widget.NewFormItem("Height", height),
+		widget.NewFormItem("DPI", dpi),
+	}
+	items[2].HintText = "in pixels at 96 DPI"
+
+	d := dialog.NewForm("Export Figure", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		settings := figureSettings{Format: format.Selected}
+		w, errW := strconv.ParseFloat(width.Text, 32)
+		h, errH := strconv.ParseFloat(height.Text, 32)
+		if errW != nil || errH != nil || w < 100 || h < 100 {
+			dialog.ShowError(errors.New("width and height must be at least 100 pixels"), MainWindow)
+			return
+		}
+		settings.Width, settings.Height = float32(w), float32(h)
+		if settings.DPI, _ = strconv.ParseFloat(dpi.Text, 64); settings.Format == figurePNG && settings.DPI <= 0 {
+			dialog.ShowError(errors.New("the resolution must be a positive number"), MainWindow)
+			return
+		}
+		g := s.graphMap[graphSelect.Selected]
+		if g == nil {
+			dialog.ShowError(errors.New("select a graph"), MainWindow)
+			return
+		}
+
+		fileDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, MainWindow)
+				return
+			}
+			if writer == nil {
+				return // user abort
+			}
+			defer writer.Close()
+			if err := writeFigure(writer, g, settings); err != nil {
+				dialog.ShowError(err, MainWindow)
+			}
+		}, MainWindow)
+		fileDialog.SetFileName(graphSelect.Selected + "." + strings.ToLower(settings.Format))
+		fileDialog.Show()
+	}, MainWindow)
+	d.Resize(fyne.NewSize(400, 300))
+	d.Show()
+}
@@ -0,0 +1,21 @@
+package gui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFigure(t *testing.T) {
+	TestSetup(t)
+	testSample.RecalculateData()
+
+	for _, format := range []string{figureSVG, figurePNG, figurePDF} {
+		out := &bytes.Buffer{}
+		err := writeFigure(out, testSample.graphMap["intensity"], figureSettings{Format: format, Width: 600, Height: 400, DPI: 150})
+		assert.NoError(t, err, format)
+		assert.NotEmpty(t, out.Bytes(), format)
+	}
+	assert.Error(t, writeFigure(&bytes.Buffer{}, testSample.graphMap["intensity"], figureSettings{Format: "TIFF", Width: 600, Height: 400}))
+}
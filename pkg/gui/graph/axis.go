@@ -0,0 +1,251 @@
+package graph
+
+import (
+	"math"
+	"slices"
+)
+
+// ScaleMode is the scaling of a graph axis
+type ScaleMode int
+
+const (
+	ScaleLinear ScaleMode = iota
+	// ScaleLog is the log10 of the values, values <= 0 are not drawn
+	ScaleLog
+	// ScaleSymlog is logarithmic for large absolute values and linear around zero, so negative values can be drawn
+	ScaleSymlog
+)
+
+// ScaleModes are all scale modes in the order of the graph menu
+var ScaleModes = []ScaleMode{ScaleLinear, ScaleLog, ScaleSymlog}
+
+func (m ScaleMode) String() string {
+	switch m {
+	case ScaleLog:
+		return "Log10"
+	case ScaleSymlog:
+		return "Symlog"
+	default:
+		return "Linear"
+	}
+}
+
+// axisView maps the values of an axis to the pixels of the drawing area
+type axisView struct {
+	mode ScaleMode
+	// absolute value below which a symlog axis is linear
+	threshold float64
+	// visible range in transformed coordinates (log10 of the values on log axes)
+	min float64
+	max float64
+	// length of the drawing area
+	pixels float32
+}
+
+// newAxisView fits the values with pretty bounds: whole decades on log axes, the threshold times a power of ten
+// on symlog axes and the order of the range on linear axes
+// the range of a log axis must be positive, the threshold is only used by symlog axes
+func newAxisView(minV, maxV float64, mode ScaleMode, threshold float64, pixels float32) axisView {
+	a := axisView{mode: mode, threshold: threshold, pixels: pixels}
+	switch mode {
+	case ScaleLog:
+		a.min = math.Floor(a.transform(minV))
+		a.max = math.Ceil(a.transform(maxV))
+	case ScaleSymlog:
+		a.min = a.transform(-symlogCeil(-minV, threshold))
+		a.max = a.transform(symlogCeil(maxV, threshold))
+	default:
+		order := int(math.Floor(math.Log10(math.Abs(maxV - minV))))
+		a.min = floorInOrder(minV, order)
+		a.max = ceilInOrder(maxV, order)
+	}
+	if a.min == a.max {
+		a.max = a.min + 1
+	}
+	return a
+}
+
+// fitAxis fits an axis to the values, values that cannot be drawn on the scale are ignored
+// returns false if no value can be drawn
+func fitAxis(values []float64, mode ScaleMode, pixels float32) (axisView, bool) {
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) || (mode == ScaleLog && v <= 0) {
+			continue
+		}
+		minV, maxV = min(minV, v), max(maxV, v)
+	}
+	if minV > maxV {
+		return axisView{}, false
+	}
+	if minV == maxV && mode != ScaleLog {
+		minV, maxV = minV-smallestGraphScope, maxV+smallestGraphScope
+	}
+	return newAxisView(minV, maxV, mode, symlogThreshold(values), pixels), true
+}
+
+// symlogCeil rounds up to zero or plus or minus the threshold times a power of ten
+func symlogCeil(v, threshold float64) float64 {
+	switch {
+	case v <= -threshold:
+		return -threshold * math.Pow10(int(math.Floor(math.Log10(-v/threshold))))
+	case v <= 0:
+		return 0
+	case v <= threshold:
+		return threshold
+	default:
+		return threshold * math.Pow10(int(math.Ceil(math.Log10(v/threshold))))
+	}
+}
+
+// symlogThreshold is the power of ten below the smallest absolute value that is not zero,
+// but at most six decades below the largest one
+func symlogThreshold(values []float64) float64 {
+	smallest, largest := math.Inf(1), 0.0
+	for _, v := range values {
+		if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		smallest = min(smallest, math.Abs(v))
+		largest = max(largest, math.Abs(v))
+	}
+	if largest == 0 {
+		return 1
+	}
+	return math.Pow10(int(math.Floor(math.Log10(max(smallest, largest*1e-6)))))
+}
+
+// transform maps a value into the coordinates of the axis
+func (a axisView) transform(v float64) float64 {
+	switch a.mode {
+	case ScaleLog:
+		if v <= 0 {
+			return math.NaN()
+		}
+		return math.Log10(v)
+	case ScaleSymlog:
+		return math.Copysign(math.Log10(1+math.Abs(v)/a.threshold), v)
+	default:
+		return v
+	}
+}
+
+// inverse maps coordinates of the axis back to a value
+func (a axisView) inverse(t float64) float64 {
+	switch a.mode {
+	case ScaleLog:
+		return math.Pow(10, t)
+	case ScaleSymlog:
+		return math.Copysign(a.threshold*(math.Pow(10, math.Abs(t))-1), t)
+	default:
+		return t
+	}
+}
+
+// toPixel returns the distance of a value from the start of the axis in pixels
+func (a axisView) toPixel(v float64) float32 {
+	return float32((a.transform(v) - a.min) / (a.max - a.min) * float64(a.pixels))
+}
+
+// fromPixel returns the value at a distance from the start of the axis
+func (a axisView) fromPixel(p float32) float64 {
+	return a.inverse(a.coordinate(p))
+}
+
+// coordinate of the axis at a distance from its start
+func (a axisView) coordinate(p float32) float64 {
+	return a.min + float64(p/a.pixels)*(a.max-a.min)
+}
+
+// zoom scales the visible range around the coordinate at pixel p, factors below 1 zoom in
+func (a axisView) zoom(p float32, factor float64) axisView {
+	center := a.coordinate(p)
+	a.min = center - (center-a.min)*factor
+	a.max = center + (a.max-center)*factor
+	return a
+}
+
+// pan moves the visible range by a distance in pixels
+func (a axisView) pan(p float32) axisView {
+	d := float64(p/a.pixels) * (a.max - a.min)
+	a.min -= d
+	a.max -= d
+	return a
+}
+
+// between returns the visible range from pixel p1 to p2
+func (a axisView) between(p1, p2 float32) axisView {
+	a.min, a.max = a.coordinate(min(p1, p2)), a.coordinate(max(p1, p2))
+	return a
+}
+
+// pixel of coordinates of the axis, f.e. of a tick
+func (a axisView) pixelOf(t float64) float32 {
+	return float32((t - a.min) / (a.max - a.min) * float64(a.pixels))
+}
+
+// ticks returns the coordinates of the major grid lines in the visible range
+// log axes have one per decade, linear axes one per power of ten of the range (halved for short ranges),
+// symlog axes one at zero and at the decades above the threshold
+func (a axisView) ticks() []float64 {
+	if a.mode == ScaleSymlog {
+		return a.symlogTicks()
+	}
+
+	// at most about ten decades are labeled
+	step := max(1, math.Ceil((a.max-a.min)/10))
+	if a.mode == ScaleLinear {
+		step = math.Pow10(int(math.Floor(math.Log10(a.max - a.min))))
+		if (a.max-a.min)/step < 4 {
+			step /= 2
+		}
+	}
+
+	ticks := make([]float64, 0)
+	first := math.Ceil(a.min/step - 1e-9)
+	for k := first; k*step <= a.max+step*1e-9; k++ {
+		// adding zero turns -0 into 0
+		ticks = append(ticks, k*step+0)
+	}
+	return ticks
+}
+
+// minorTicks returns the coordinates of the minor grid lines of a log axis, 2 to 9 times a decade
+func (a axisView) minorTicks() []float64 {
+	ticks := make([]float64, 0)
+	if a.mode != ScaleLog || a.max-a.min > 10 {
+		return ticks
+	}
+	for decade := math.Floor(a.min); decade < a.max; decade++ {
+		for j := 2; j < 10; j++ {
+			t := decade + math.Log10(float64(j))
+			if t > a.min && t < a.max {
+				ticks = append(ticks, t)
+			}
+		}
+	}
+	return ticks
+}
+
+// ticks of a symlog axis at zero and at plus and minus the threshold times the powers of ten
+func (a axisView) symlogTicks() []float64 {
+	ticks := make([]float64, 0)
+	if a.min <= 0 && a.max >= 0 {
+		ticks = append(ticks, 0)
+	}
+
+	// at most about five decades on each side of zero are labeled
+	largest := max(math.Abs(a.min), math.Abs(a.max))
+	decades := math.Ceil(math.Log10(math.Abs(a.inverse(largest)) / a.threshold))
+	step := max(1, math.Ceil(decades/5))
+	for k := 0.0; k <= decades; k += step {
+		t := a.transform(a.threshold * math.Pow(10, k))
+		for _, tick := range []float64{-t, t} {
+			if tick >= a.min && tick <= a.max {
+				ticks = append(ticks, tick)
+			}
+		}
+	}
+	slices.Sort(ticks)
+	return ticks
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAxisViewLinear(t *testing.T) {
+	a := newAxisView(0.3, 4.2, ScaleLinear, 0, 100)
+	assert.Equal(t, 0.0, a.min)
+	assert.Equal(t, 5.0, a.max)
+	assert.InDelta(t, 50, a.toPixel(2.5), 1e-4)
+	assert.InDelta(t, 2.5, a.fromPixel(50), 1e-6)
+	assert.Equal(t, []float64{0, 1, 2, 3, 4, 5}, a.ticks())
+
+	// zooming keeps the value under the pixel in place
+	zoomed := a.zoom(20, 0.5)
+	assert.InDelta(t, a.fromPixel(20), zoomed.fromPixel(20), 1e-9)
+	assert.InDelta(t, 2.5, zoomed.max-zoomed.min, 1e-9)
+
+	// dragging to the right shows smaller values
+	panned := a.pan(10)
+	assert.InDelta(t, -0.5, panned.min, 1e-6)
+
+	box := a.between(80, 20)
+	assert.InDelta(t, 1, box.min, 1e-6)
+	assert.InDelta(t, 4, box.max, 1e-6)
+}
+
+func TestAxisViewLog(t *testing.T) {
+	a := newAxisView(2e-5, 0.8, ScaleLog, 0, 100)
+	assert.Equal(t, -5.0, a.min)
+	assert.Equal(t, 0.0, a.max)
+	assert.InDelta(t, 20, a.toPixel(1e-4), 1e-4)
+	assert.InDelta(t, 1e-4, a.fromPixel(20), 1e-10)
+	assert.Equal(t, []float64{-5, -4, -3, -2, -1, 0}, a.ticks())
+	assert.Len(t, a.minorTicks(), 5*8)
+
+	// values <= 0 are not drawn instead of shifting all values
+	assert.True(t, math.IsNaN(float64(a.toPixel(0))))
+	fitted, ok := fitAxis([]float64{-1, 0, 1e-3, 10}, ScaleLog, 100)
+	assert.True(t, ok)
+	assert.Equal(t, -3.0, fitted.min)
+	assert.Equal(t, 1.0, fitted.max)
+	_, ok = fitAxis([]float64{-1, 0}, ScaleLog, 100)
+	assert.False(t, ok)
+}
+
+func TestAxisViewSymlog(t *testing.T) {
+	assert.Equal(t, 0.01, symlogThreshold([]float64{-5, 0, 0.02, 3}))
+
+	a, ok := fitAxis([]float64{-5, 0, 0.02, 3}, ScaleSymlog, 100)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, a.transform(0))
+	assert.InDelta(t, -a.transform(2), a.transform(-2), 1e-12)
+	assert.InDelta(t, -5, a.inverse(a.transform(-5)), 1e-9)
+	assert.Less(t, a.min, a.transform(-5))
+	assert.Greater(t, a.max, a.transform(3))
+
+	ticks := a.ticks()
+	assert.Contains(t, ticks, 0.0)
+	labels := make([]float64, len(ticks))
+	for i, tick := range ticks {
+		labels[i] = a.inverse(tick)
+	}
+	assert.InDeltaSlice(t, []float64{-10, -1, -0.1, -0.01, 0, 0.01, 0.1, 1, 10}, labels, 1e-9)
+}
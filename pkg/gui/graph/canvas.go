@@ -1,30 +1,36 @@
 package graph
 
 import (
+	"fmt"
 	"image/color"
 	"math"
 	"physicsGUI/pkg/function"
 	"physicsGUI/pkg/minimizer"
 	"slices"
-
-	"fyne.io/fyne/v2/theme"
-
-	"fyne.io/fyne/v2/container"
+	"sync"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/widget"
 )
 
 // GraphCanvas represents the graphical representation of a graph
 type GraphCanvas struct {
 	widget.BaseWidget
-	Config     *GraphConfig
-	background *canvas.Rectangle
+	Config *GraphConfig
+
+	functions  function.Functions
+	loadedData function.Functions
+	// names and looks of the loaded data tracks
+	dataStyles []TrackStyle
 
-	functions         function.Functions
-	loadedData        function.Functions
-	dataRemoveButtons []*fyne.Container
+	// called after a data track was added or removed
+	OnDataTracksChanged func()
+	// called after the style of a data track changed
+	OnStylesChanged func()
+
+	// zoom, pan and cursor of the mouse navigation
+	lock sync.Mutex
+	nav  interaction
 }
 
 // NewGraphCanvas creates a new canvas instance with a provided config
@@ -37,8 +43,7 @@ func NewGraphCanvas(config *GraphConfig) *GraphCanvas {
 		}
 	}
 	g := &GraphCanvas{
-		Config:     config,
-		background: canvas.NewRectangle(color.Black),
+		Config: config,
 
 		functions:  config.Functions,
 		loadedData: make(function.Functions, 0),
@@ -68,24 +73,26 @@ func (g *GraphCanvas) CreateRenderer() fyne.WidgetRenderer {
 		graph:   g,
 		objects: make([]fyne.CanvasObject, 0),
 		size:    &fyne.Size{},
-		margin:  float32(50),
+		margin:  graphMargin,
+		theme:   ScreenTheme,
 	}
 }
 
+// AddDataTrack adds a data track with the default style, named by its number
 func (g *GraphCanvas) AddDataTrack(dataTrack *function.Function) {
-	i := len(g.loadedData)
-	g.loadedData = append(g.loadedData, dataTrack)
+	g.AddStyledDataTrack(dataTrack, TrackStyle{Name: fmt.Sprintf("Data %d", len(g.loadedData)+1)})
+}
 
-	// create remove button
-	btnRemove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
-		g.RemoveDataTrack(dataTrack)
-	})
-	btnRemove.Resize(fyne.NewSize(20, 20))
-	btnColor := DataTrackColors[i%len(DataTrackColors)]
-	g.dataRemoveButtons = append(g.dataRemoveButtons, container.NewStack(canvas.NewRectangle(btnColor), btnRemove))
+// AddStyledDataTrack adds a data track with a name and a look, f.e. the file name of the data
+func (g *GraphCanvas) AddStyledDataTrack(dataTrack *function.Function, style TrackStyle) {
+	g.loadedData = append(g.loadedData, dataTrack)
+	g.dataStyles = append(g.dataStyles, style)
 
 	_ = minimizer.State.Set(1)
 	g.Refresh()
+	if g.OnDataTracksChanged != nil {
+		g.OnDataTracksChanged()
+	}
 }
 
 func (g *GraphCanvas) GetDataTracks() function.Functions {
@@ -96,10 +103,101 @@ func (g *GraphCanvas) RemoveDataTrack(dataTrack *function.Function) {
 	i := slices.Index(g.loadedData, dataTrack)
 	if i != -1 {
 		g.loadedData = append(g.loadedData[:i], g.loadedData[i+1:]...)
-		g.dataRemoveButtons = append(g.dataRemoveButtons[:i], g.dataRemoveButtons[i+1:]...)
+		g.dataStyles = append(g.dataStyles[:i], g.dataStyles[i+1:]...)
 		g.Refresh()
 	}
 	if len(g.loadedData) == 0 {
 		_ = minimizer.State.Set(0)
 	}
+	if i != -1 && g.OnDataTracksChanged != nil {
+		g.OnDataTracksChanged()
+	}
+}
+
+// SetDataTrackPoints replaces the points of a loaded data track, f.e. after reloading its file
+func (g *GraphCanvas) SetDataTrackPoints(dataTrack *function.Function, points function.Points) {
+	if !slices.Contains(g.loadedData, dataTrack) {
+		return
+	}
+	dataTrack.SetData(points)
+	g.Refresh()
+	if g.OnDataTracksChanged != nil {
+		g.OnDataTracksChanged()
+	}
+}
+
+// DataTrackStyle returns the name and the look of the data track with the index
+func (g *GraphCanvas) DataTrackStyle(i int) TrackStyle {
+	return g.dataStyles[i]
+}
+
+// SetDataTrackStyle changes the name and the look of the data track with the index
+func (g *GraphCanvas) SetDataTrackStyle(i int, style TrackStyle) {
+	g.dataStyles[i] = style
+	g.Refresh()
+	if g.OnStylesChanged != nil {
+		g.OnStylesChanged()
+	}
+}
+
+// DataTrackColor returns the color of the data track with the index as drawn on the screen
+func (g *GraphCanvas) DataTrackColor(i int) color.Color {
+	if c := g.dataStyles[i].Color; c != nil {
+		return c
+	}
+	return ScreenTheme.dataTrackColor(i)
+}
+
+// SetFunctions replaces the functions shown in the graph
+func (g *GraphCanvas) SetFunctions(functions function.Functions) {
+	for _, f := range functions {
+		if f == nil {
+			panic("function cannot be nil. Make sure to provide a function (even an empty one)")
+		}
+	}
+	g.functions = functions
+	g.Config.Functions = functions
+	g.Refresh()
+}
+
+// SetXScale changes the scaling of the x axis and shows all data
+func (g *GraphCanvas) SetXScale(mode ScaleMode) {
+	g.Config.XScale = mode
+	g.ResetView()
+}
+
+// SetYScale changes the scaling of the y axis and shows all data
+func (g *GraphCanvas) SetYScale(mode ScaleMode) {
+	g.Config.YScale = mode
+	g.ResetView()
+}
+
+// SetQuantity selects the quantity with the index in the config and shows all data
+func (g *GraphCanvas) SetQuantity(i int) {
+	g.Config.Quantity = i
+	g.ResetView()
+}
+
+// style of the function with the index, the default color is the one of the theme
+func (g *GraphCanvas) functionStyle(i int, theme Theme) TrackStyle {
+	style := TrackStyle{}
+	if i < len(g.Config.FunctionStyles) {
+		style = g.Config.FunctionStyles[i]
+	}
+	if style.Color == nil {
+		style.Color = theme.Function
+		if g.Config.DataTrackColors && i >= g.Config.ModelFunctions {
+			style.Color = theme.dataTrackColor(i - g.Config.ModelFunctions)
+		}
+	}
+	return style
+}
+
+// style of the data track with the index, the default color is the one of the theme
+func (g *GraphCanvas) dataStyle(i int, theme Theme) TrackStyle {
+	style := g.dataStyles[i]
+	if style.Color == nil {
+		style.Color = theme.dataTrackColor(i)
+	}
+	return style
 }
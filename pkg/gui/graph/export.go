@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+)
+
+// pixels per inch of the plot coordinates
+const plotDPI = 96
+
+// Plot draws the graph like on the screen but with the theme and at the size,
+// without the remove buttons and the mouse navigation, a zoomed or panned range is kept
+func (g *GraphCanvas) Plot(size fyne.Size, theme Theme) *Plot {
+	r := &GraphRenderer{
+		graph:  g,
+		size:   &fyne.Size{},
+		margin: graphMargin,
+		theme:  theme,
+		export: true,
+	}
+	r.draw(size)
+	return r.plot
+}
+
+// red, green and blue of a color from 0 to 255 and its opacity from 0 to 1
+func rgba(c color.Color) (uint8, uint8, uint8, float64) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R, n.G, n.B, float64(n.A) / 255
+}
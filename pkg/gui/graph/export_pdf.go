@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// points of a PDF per pixel of the plot
+const pdfScale = 72.0 / plotDPI
+
+// control point distance of the bezier curves approximating a quarter circle
+const bezierCircle = 0.5523
+
+// WritePDF writes the plot as a single page PDF with the standard Helvetica fonts
+// characters outside of the WinAnsi encoding are replaced, f.e. ρ by rho
+func (p *Plot) WritePDF(w io.Writer) error {
+	content := &bytes.Buffer{}
+	opacities := make([]float64, 0)
+	// selects the graphics state with the opacity, one state per opacity is added to the resources
+	setOpacity := func(a float64) {
+		i := -1
+		for j, o := range opacities {
+			if o == a {
+				i = j
+			}
+		}
+		if i < 0 {
+			i = len(opacities)
+			opacities = append(opacities, a)
+		}
+		fmt.Fprintf(content, "/GS%d gs\n", i)
+	}
+	// sets the fill or stroke color, returns false for nil colors
+	setColor := func(c color.Color, stroke bool) bool {
+		if c == nil {
+			return false
+		}
+		r, g, b, a := rgba(c)
+		operator := "rg"
+		if stroke {
+			operator = "RG"
+		}
+		setOpacity(a)
+		fmt.Fprintf(content, "%.3f %.3f %.3f %s\n", float64(r)/255, float64(g)/255, float64(b)/255, operator)
+		return true
+	}
+	// pdf coordinates from the bottom left in points
+	x := func(v float32) float64 { return float64(v) * pdfScale }
+	y := func(v float32) float64 { return float64(p.Height-v) * pdfScale }
+	paint := func(fill, stroke bool) {
+		switch {
+		case fill && stroke:
+			content.WriteString("B\n")
+		case fill:
+			content.WriteString("f\n")
+		case stroke:
+			content.WriteString("S\n")
+		default:
+			content.WriteString("n\n")
+		}
+	}
+
+	for _, item := range p.Items {
+		switch i := item.(type) {
+		case PlotLine:
+			if setColor(i.Color, true) {
+				dash := make([]string, len(i.Dash))
+				for j, d := range i.Dash {
+					dash[j] = fmt.Sprintf("%.2f", x(d))
+				}
+				if len(dash) > 0 {
+					fmt.Fprintf(content, "[%s] %.2f d\n", strings.Join(dash, " "), x(i.DashOffset))
+				}
+				fmt.Fprintf(content, "%.3f w %.2f %.2f m %.2f %.2f l S\n", x(i.Width), x(i.X1), y(i.Y1), x(i.X2), y(i.Y2))
+				if len(dash) > 0 {
+					content.WriteString("[] 0 d\n")
+				}
+			}
+		case PlotRect:
+			// the opacity of the stroke is used for both, as one graphics state sets both
+			fill := setColor(i.Fill, false)
+			stroke := setColor(i.Stroke, true)
+			fmt.Fprintf(content, "%.3f w %.2f %.2f %.2f %.2f re\n", x(i.StrokeWidth), x(i.X), y(i.Y+i.Height), x(i.Width), x(i.Height))
+			paint(fill, stroke)
+		case PlotCircle:
+			fill := setColor(i.Fill, false)
+			stroke := setColor(i.Stroke, true)
+			cx, cy, r := x(i.X), y(i.Y), x(i.Radius)
+			k := r * bezierCircle
+			fmt.Fprintf(content, "%.3f w %.2f %.2f m\n", x(i.StrokeWidth), cx+r, cy)
+			fmt.Fprintf(content, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx+r, cy+k, cx+k, cy+r, cx, cy+r)
+			fmt.Fprintf(content, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx-k, cy+r, cx-r, cy+k, cx-r, cy)
+			fmt.Fprintf(content, "%.2f %.2f %.2f %.2f %.2f %.2f c\n", cx-r, cy-k, cx-k, cy-r, cx, cy-r)
+			fmt.Fprintf(content, "%.2f %.2f %.2f %.2f %.2f %.2f c h\n", cx+k, cy-r, cx+r, cy-k, cx+r, cy)
+			paint(fill, stroke)
+		case PlotText:
+			if !setColor(i.Color, false) {
+				continue
+			}
+			font := "F1"
+			if i.Bold {
+				font = "F2"
+			}
+			fmt.Fprintf(content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, x(i.Size), x(i.X), y(i.Y+i.Baseline), pdfText(i.Text))
+		}
+	}
+
+	states := &strings.Builder{}
+	for i, a := range opacities {
+		fmt.Fprintf(states, "/GS%d << /CA %.3f /ca %.3f >> ", i, a, a)
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Contents 4 0 R "+
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> /ExtGState << %s>> >> >>",
+			x(p.Width), x(p.Height), states.String()),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// replacements of characters the standard fonts do not have
+var pdfReplacements = map[rune]string{
+	'⁻': "-",
+	'⁰': "0",
+	'⁴': "4",
+	'⁵': "5",
+	'⁶': "6",
+	'⁷': "7",
+	'⁸': "8",
+	'⁹': "9",
+	'ρ': "rho",
+	'σ': "sigma",
+	'χ': "chi",
+	'Δ': "Delta",
+	'μ': "\\265",
+}
+
+// encodes a text as escaped WinAnsi string
+func pdfText(text string) string {
+	b := &strings.Builder{}
+	for _, r := range text {
+		if replacement, ok := pdfReplacements[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x80:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			// latin-1 characters have the same codes in WinAnsi, f.e. Å, ±, ·, ¹
+			fmt.Fprintf(b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package graph
+
+import (
+	"errors"
+	"image/png"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/software"
+)
+
+// WritePNG writes the plot as PNG drawn like on the screen, the size of the plot is scaled from 96 to the dpi
+func (p *Plot) WritePNG(w io.Writer, dpi float64) error {
+	if dpi <= 0 {
+		return errors.New("the resolution must be positive")
+	}
+
+	c := software.NewCanvas()
+	c.SetPadded(false)
+	c.SetScale(float32(dpi / plotDPI))
+	c.SetContent(container.NewWithoutLayout(p.CanvasObjects()...))
+	c.Resize(fyne.NewSize(p.Width, p.Height))
+	return png.Encode(w, c.Capture())
+}
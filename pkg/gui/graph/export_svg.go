@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// WriteSVG writes the plot as SVG, one pixel of the plot is one user unit
+func (p *Plot) WriteSVG(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`+"\n",
+		p.Width, p.Height, p.Width, p.Height)
+	for _, item := range p.Items {
+		switch i := item.(type) {
+		case PlotLine:
+			dash := ""
+			if len(i.Dash) > 0 {
+				values := make([]string, len(i.Dash))
+				for j, d := range i.Dash {
+					values[j] = fmt.Sprintf("%g", d)
+				}
+				dash = fmt.Sprintf(` stroke-dasharray="%s" stroke-dashoffset="%g"`, strings.Join(values, " "), i.DashOffset)
+			}
+			fmt.Fprintf(b, `<line x1="%g" y1="%g" x2="%g" y2="%g" %s%s/>`+"\n",
+				i.X1, i.Y1, i.X2, i.Y2, svgPaint("stroke", i.Color, i.Width), dash)
+		case PlotCircle:
+			fmt.Fprintf(b, `<circle cx="%g" cy="%g" r="%g" %s %s/>`+"\n",
+				i.X, i.Y, i.Radius, svgPaint("fill", i.Fill, 0), svgPaint("stroke", i.Stroke, i.StrokeWidth))
+		case PlotRect:
+			fmt.Fprintf(b, `<rect x="%g" y="%g" width="%g" height="%g" %s %s/>`+"\n",
+				i.X, i.Y, i.Width, i.Height, svgPaint("fill", i.Fill, 0), svgPaint("stroke", i.Stroke, i.StrokeWidth))
+		case PlotText:
+			weight := "normal"
+			if i.Bold {
+				weight = "bold"
+			}
+			fmt.Fprintf(b, `<text x="%g" y="%g" font-family="sans-serif" font-size="%g" font-weight="%s" %s>%s</text>`+"\n",
+				i.X, i.Y+i.Baseline, i.Size, weight, svgPaint("fill", i.Color, 0), svgEscape(i.Text))
+		}
+	}
+	fmt.Fprintln(b, "</svg>")
+	return b.Flush()
+}
+
+// fill or stroke attributes of a color, nil colors are not painted
+func svgPaint(attribute string, c color.Color, width float32) string {
+	if c == nil {
+		return attribute + `="none"`
+	}
+	r, g, b, a := rgba(c)
+	paint := fmt.Sprintf(`%s="#%02x%02x%02x" %s-opacity="%.3g"`, attribute, r, g, b, attribute, a)
+	if attribute == "stroke" {
+		paint += fmt.Sprintf(` stroke-width="%g"`, width)
+	}
+	return paint
+}
+
+var svgEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func svgEscape(text string) string {
+	return svgEscaper.Replace(text)
+}
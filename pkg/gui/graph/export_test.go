@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlot(t *testing.T) {
+	g := newTestGraph(t)
+	g.Config.XLabel = "qz [Å⁻¹]"
+	plot := g.Plot(fyne.NewSize(400, 300), PrintTheme)
+
+	assert.Equal(t, float32(400), plot.Width)
+	assert.Equal(t, PlotRect{Width: 400, Height: 300, Fill: PrintTheme.Background}, plot.Items[0])
+
+	texts := make([]string, 0)
+	for _, item := range plot.Items {
+		if text, ok := item.(PlotText); ok {
+			texts = append(texts, text.Text)
+			assert.Equal(t, PrintTheme.Labels, text.Color)
+		}
+	}
+	assert.Contains(t, texts, "Test")
+	assert.Contains(t, texts, "qz [Å⁻¹]")
+
+	// the mouse navigation of the screen is not drawn and not changed
+	before := g.navigation()
+	g.MouseMoved(&desktop.MouseEvent{PointEvent: fyne.PointEvent{Position: fyne.NewPos(200, 150)}})
+	assert.Len(t, g.Plot(fyne.NewSize(400, 300), PrintTheme).Items, len(plot.Items))
+	assert.Equal(t, before.x, g.navigation().x)
+}
+
+func TestPlotExport(t *testing.T) {
+	g := newTestGraph(t)
+	g.Config.XLabel = "qz [Å⁻¹] <1>"
+	plot := g.Plot(fyne.NewSize(400, 300), PrintTheme)
+
+	svg := &bytes.Buffer{}
+	assert.NoError(t, plot.WriteSVG(svg))
+	assert.True(t, strings.HasPrefix(svg.String(), `<svg xmlns="http://www.w3.org/2000/svg" width="400" height="300"`))
+	assert.Contains(t, svg.String(), `<rect x="0" y="0" width="400" height="300" fill="#ffffff" fill-opacity="1" stroke="none"/>`)
+	assert.Contains(t, svg.String(), ">qz [Å⁻¹] &lt;1&gt;</text>")
+
+	image := &bytes.Buffer{}
+	assert.NoError(t, plot.WritePNG(image, 192))
+	decoded, err := png.Decode(image)
+	assert.NoError(t, err)
+	assert.Equal(t, 800, decoded.Bounds().Dx())
+	assert.Equal(t, 600, decoded.Bounds().Dy())
+	r, g2, b, _ := decoded.At(1, 1).RGBA()
+	assert.Equal(t, []uint32{0xffff, 0xffff, 0xffff}, []uint32{r, g2, b})
+
+	pdf := &bytes.Buffer{}
+	assert.NoError(t, plot.WritePDF(pdf))
+	assert.True(t, strings.HasPrefix(pdf.String(), "%PDF-1.4"))
+	assert.Contains(t, pdf.String(), "/MediaBox [0 0 300.00 225.00]")
+	assert.Contains(t, pdf.String(), `(qz [\305-\271] <1>) Tj`)
+	assert.True(t, strings.HasSuffix(pdf.String(), "%%EOF\n"))
+}
+
+func TestPDFText(t *testing.T) {
+	assert.Equal(t, `R\(q\) rho \261 ?`, pdfText("R(q) ρ ± 日"))
+}
@@ -0,0 +1,239 @@
+package graph
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// smallest box in pixels that zooms, smaller boxes are treated as a click
+const minBoxSize = 5
+
+// graphView is the visible range of a zoomed or panned graph
+type graphView struct {
+	x axisView
+	y axisView
+}
+
+// interaction is the state of the mouse navigation of a graph
+type interaction struct {
+	// visible range, nil fits all data
+	view *graphView
+	// axes of the last layout
+	x axisView
+	y axisView
+
+	// position of the mouse, nil if it is outside the graph
+	cursor *fyne.Position
+	// box zoom is selected by dragging with shift pressed, the box starts where the button was pressed
+	boxZoom  bool
+	boxStart fyne.Position
+	boxEnd   fyne.Position
+	dragging bool
+}
+
+var (
+	_ fyne.Scrollable        = (*GraphCanvas)(nil)
+	_ fyne.Draggable         = (*GraphCanvas)(nil)
+	_ fyne.DoubleTappable    = (*GraphCanvas)(nil)
+	_ desktop.Mouseable      = (*GraphCanvas)(nil)
+	_ desktop.Hoverable      = (*GraphCanvas)(nil)
+	_ fyne.SecondaryTappable = (*GraphCanvas)(nil)
+)
+
+// applyView replaces the range of the axes fitting all data by the visible range
+// the result is remembered for the navigation if the graph is drawn on the screen
+func (g *GraphCanvas) applyView(x, y axisView, remember bool) (axisView, axisView) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if v := g.nav.view; v != nil && v.x.mode == x.mode && v.y.mode == y.mode {
+		x.min, x.max, x.threshold = v.x.min, v.x.max, v.x.threshold
+		y.min, y.max, y.threshold = v.y.min, v.y.max, v.y.threshold
+	}
+	if remember {
+		g.nav.x, g.nav.y = x, y
+	}
+	return x, y
+}
+
+// ResetView shows all data again after zooming or panning
+func (g *GraphCanvas) ResetView() {
+	g.lock.Lock()
+	g.nav.view = nil
+	g.lock.Unlock()
+	g.Refresh()
+}
+
+// IsZoomed reports whether the graph shows a zoomed or panned range
+func (g *GraphCanvas) IsZoomed() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.nav.view != nil
+}
+
+// position in pixels from the bottom left of the drawing area
+func (g *GraphCanvas) plotPixel(pos fyne.Position) (float32, float32) {
+	return pos.X - graphMargin, g.Size().Height - graphMargin - pos.Y
+}
+
+// changes the visible range, does nothing before the first layout
+func (g *GraphCanvas) changeView(change func(x, y axisView) (axisView, axisView)) {
+	g.lock.Lock()
+	if g.nav.x.pixels <= 0 || g.nav.y.pixels <= 0 {
+		g.lock.Unlock()
+		return
+	}
+	x, y := change(g.nav.x, g.nav.y)
+	if !validRange(x) || !validRange(y) {
+		g.lock.Unlock()
+		return
+	}
+	g.nav.view = &graphView{x: x, y: y}
+	g.lock.Unlock()
+	g.Refresh()
+}
+
+// ranges that cannot be drawn, f.e. after zooming in too far
+func validRange(a axisView) bool {
+	d := a.max - a.min
+	return d > 1e-12*max(math.Abs(a.min), math.Abs(a.max), 1) && !math.IsInf(d, 0) && !math.IsNaN(d)
+}
+
+// Scrolled zooms around the mouse, scrolling up zooms in
+func (g *GraphCanvas) Scrolled(e *fyne.ScrollEvent) {
+	factor := math.Pow(0.9, float64(e.Scrolled.DY)/10)
+	px, py := g.plotPixel(e.Position)
+	g.changeView(func(x, y axisView) (axisView, axisView) {
+		return x.zoom(px, factor), y.zoom(py, factor)
+	})
+}
+
+// Dragged pans the graph or spans the zoom box while shift is pressed
+func (g *GraphCanvas) Dragged(e *fyne.DragEvent) {
+	g.lock.Lock()
+	g.nav.cursor = &e.Position
+	if g.nav.boxZoom {
+		g.nav.dragging = true
+		g.nav.boxEnd = e.Position
+		g.lock.Unlock()
+		g.Refresh()
+		return
+	}
+	g.lock.Unlock()
+
+	g.changeView(func(x, y axisView) (axisView, axisView) {
+		return x.pan(e.Dragged.DX), y.pan(-e.Dragged.DY)
+	})
+}
+
+// DragEnd zooms into the box
+func (g *GraphCanvas) DragEnd() {
+	g.lock.Lock()
+	box, start, end := g.nav.boxZoom && g.nav.dragging, g.nav.boxStart, g.nav.boxEnd
+	g.nav.boxZoom, g.nav.dragging = false, false
+	g.lock.Unlock()
+
+	if !box || math.Abs(float64(end.X-start.X)) < minBoxSize || math.Abs(float64(end.Y-start.Y)) < minBoxSize {
+		g.Refresh()
+		return
+	}
+	x1, y1 := g.plotPixel(start)
+	x2, y2 := g.plotPixel(end)
+	g.changeView(func(x, y axisView) (axisView, axisView) {
+		return x.between(x1, x2), y.between(y1, y2)
+	})
+}
+
+// DoubleTapped shows all data again
+func (g *GraphCanvas) DoubleTapped(*fyne.PointEvent) {
+	g.ResetView()
+}
+
+// TappedSecondary opens the menu of the graph with the scales, quantities and data tracks
+func (g *GraphCanvas) TappedSecondary(e *fyne.PointEvent) {
+	c := fyne.CurrentApp().Driver().CanvasForObject(g)
+	if c == nil {
+		return
+	}
+	widget.ShowPopUpMenuAtPosition(g.menu(), c, e.AbsolutePosition)
+}
+
+// menu with the runtime options of the graph
+func (g *GraphCanvas) menu() *fyne.Menu {
+	scaleItems := func(selected ScaleMode, set func(ScaleMode)) []*fyne.MenuItem {
+		items := make([]*fyne.MenuItem, len(ScaleModes))
+		for i, mode := range ScaleModes {
+			items[i] = fyne.NewMenuItem(mode.String(), func() { set(mode) })
+			items[i].Checked = mode == selected
+		}
+		return items
+	}
+
+	xAxis := fyne.NewMenuItem("X Axis", nil)
+	xAxis.ChildMenu = fyne.NewMenu("", scaleItems(g.Config.XScale, g.SetXScale)...)
+	yAxis := fyne.NewMenuItem("Y Axis", nil)
+	yAxis.ChildMenu = fyne.NewMenu("", scaleItems(g.Config.YScale, g.SetYScale)...)
+	items := []*fyne.MenuItem{xAxis, yAxis}
+
+	if len(g.Config.Quantities) > 0 {
+		quantities := make([]*fyne.MenuItem, len(g.Config.Quantities))
+		for i, q := range g.Config.Quantities {
+			quantities[i] = fyne.NewMenuItem(q.Name, func() { g.SetQuantity(i) })
+			quantities[i].Checked = i == g.Config.Quantity
+		}
+		show := fyne.NewMenuItem("Show", nil)
+		show.ChildMenu = fyne.NewMenu("", quantities...)
+		items = append(items, show)
+	}
+
+	items = append(items, fyne.NewMenuItem("Data Tracks…", g.ShowTrackEditor))
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Reset View", g.ResetView))
+	return fyne.NewMenu("", items...)
+}
+
+// MouseDown selects the box zoom if shift is pressed
+func (g *GraphCanvas) MouseDown(e *desktop.MouseEvent) {
+	g.lock.Lock()
+	g.nav.boxZoom = e.Modifier&fyne.KeyModifierShift != 0
+	g.nav.boxStart = e.Position
+	g.nav.dragging = false
+	g.lock.Unlock()
+}
+
+// MouseUp (needs to be here to satisfy the interface)
+func (g *GraphCanvas) MouseUp(*desktop.MouseEvent) {}
+
+// MouseIn shows the crosshair
+func (g *GraphCanvas) MouseIn(e *desktop.MouseEvent) {
+	g.MouseMoved(e)
+}
+
+// MouseMoved moves the crosshair and updates the readout
+func (g *GraphCanvas) MouseMoved(e *desktop.MouseEvent) {
+	g.lock.Lock()
+	g.nav.cursor = &e.Position
+	g.lock.Unlock()
+	g.Refresh()
+}
+
+// MouseOut hides the crosshair
+func (g *GraphCanvas) MouseOut() {
+	g.lock.Lock()
+	g.nav.cursor = nil
+	g.lock.Unlock()
+	g.Refresh()
+}
+
+// state of the navigation for drawing
+func (g *GraphCanvas) navigation() interaction {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	nav := g.nav
+	if nav.cursor != nil {
+		cursor := *nav.cursor
+		nav.cursor = &cursor
+	}
+	return nav
+}
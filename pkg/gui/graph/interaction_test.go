@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGraph(t *testing.T) *GraphCanvas {
+	test.NewApp()
+	g := NewGraphCanvas(&GraphConfig{
+		Title: "Test",
+		Functions: function.Functions{function.NewFunction(function.Points{
+			{X: 1, Y: 0},
+			{X: 5, Y: 10},
+		})},
+	})
+	w := test.NewWindow(g)
+	t.Cleanup(w.Close)
+	w.Resize(fyne.NewSize(600, 400))
+	g.Resize(fyne.NewSize(600, 400))
+	g.Refresh()
+	return g
+}
+
+// texts drawn by the renderer
+func graphTexts(g *GraphCanvas) []string {
+	texts := make([]string, 0)
+	for _, o := range test.WidgetRenderer(g).Objects() {
+		if text, ok := o.(*canvas.Text); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return texts
+}
+
+func TestGraphZoomAndReset(t *testing.T) {
+	g := newTestGraph(t)
+	full := g.navigation()
+	assert.False(t, g.IsZoomed())
+
+	g.Scrolled(&fyne.ScrollEvent{
+		PointEvent: fyne.PointEvent{Position: fyne.NewPos(300, 200)},
+		Scrolled:   fyne.NewDelta(0, 10),
+	})
+	assert.True(t, g.IsZoomed())
+	zoomed := g.navigation()
+	assert.Less(t, zoomed.x.max-zoomed.x.min, full.x.max-full.x.min)
+	assert.Less(t, zoomed.y.max-zoomed.y.min, full.y.max-full.y.min)
+
+	// panning to the right shows smaller x values
+	g.Dragged(&fyne.DragEvent{
+		PointEvent: fyne.PointEvent{Position: fyne.NewPos(320, 200)},
+		Dragged:    fyne.NewDelta(20, 0),
+	})
+	g.DragEnd()
+	panned := g.navigation()
+	assert.Less(t, panned.x.min, zoomed.x.min)
+	assert.Equal(t, zoomed.y.min, panned.y.min)
+
+	g.DoubleTapped(&fyne.PointEvent{})
+	assert.False(t, g.IsZoomed())
+	assert.Equal(t, full.x, g.navigation().x)
+}
+
+func TestGraphBoxZoom(t *testing.T) {
+	g := newTestGraph(t)
+	g.MouseDown(&desktop.MouseEvent{
+		PointEvent: fyne.PointEvent{Position: fyne.NewPos(100, 100)},
+		Modifier:   fyne.KeyModifierShift,
+	})
+	g.Dragged(&fyne.DragEvent{
+		PointEvent: fyne.PointEvent{Position: fyne.NewPos(200, 250)},
+		Dragged:    fyne.NewDelta(100, 150),
+	})
+	// the box does not change the view before the drag ends
+	assert.False(t, g.IsZoomed())
+	g.DragEnd()
+
+	nav := g.navigation()
+	x1, y1 := g.plotPixel(fyne.NewPos(100, 100))
+	x2, y2 := g.plotPixel(fyne.NewPos(200, 250))
+	fullX := newAxisView(1, 5, ScaleLinear, 0, nav.x.pixels)
+	fullY := newAxisView(0, 10, ScaleLinear, 0, nav.y.pixels)
+	assert.InDelta(t, fullX.fromPixel(x1), nav.x.min, 1e-9)
+	assert.InDelta(t, fullX.fromPixel(x2), nav.x.max, 1e-9)
+	// the bottom of the box is the lower end of the y axis
+	assert.InDelta(t, fullY.fromPixel(y2), nav.y.min, 1e-9)
+	assert.InDelta(t, fullY.fromPixel(y1), nav.y.max, 1e-9)
+}
+
+func TestGraphCursorReadout(t *testing.T) {
+	g := newTestGraph(t)
+	nav := g.navigation()
+
+	// the mouse at the second point
+	pos := fyne.NewPos(graphMargin+nav.x.toPixel(5), g.Size().Height-graphMargin-nav.y.toPixel(10))
+	g.MouseMoved(&desktop.MouseEvent{PointEvent: fyne.PointEvent{Position: pos}})
+	assert.Contains(t, graphTexts(g), "nearest: (5, 10 ± 0)")
+
+	g.MouseOut()
+	for _, text := range graphTexts(g) {
+		assert.NotContains(t, text, "nearest")
+	}
+}
+
+func TestGraphScaleAndQuantity(t *testing.T) {
+	g := newTestGraph(t)
+	g.Config.YLabel = "y"
+	g.Config.Quantities = []Quantity{
+		{Name: "y"},
+		{Name: "2y", Label: "2y", Transform: func(points function.Points) {
+			for _, p := range points {
+				p.Y *= 2
+			}
+		}},
+	}
+
+	// the point at y = 0 cannot be drawn on a log scale
+	g.SetYScale(ScaleLog)
+	assert.Contains(t, graphTexts(g), "1 points <= 0 not shown on the log scale")
+	assert.Contains(t, graphTexts(g), "y")
+
+	g.SetQuantity(1)
+	assert.Contains(t, graphTexts(g), "2y")
+
+	// the menu shows the current settings
+	menu := g.menu()
+	assert.Equal(t, "Y Axis", menu.Items[1].Label)
+	assert.True(t, menu.Items[1].ChildMenu.Items[1].Checked)
+	assert.True(t, menu.Items[2].ChildMenu.Items[1].Checked)
+}
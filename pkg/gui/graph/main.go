@@ -4,6 +4,8 @@ import (
 	"image/color"
 	"physicsGUI/pkg/function"
 
+	"fyne.io/fyne/v2"
+
 	"golang.org/x/image/colornames"
 )
 
@@ -14,45 +16,70 @@ var (
 		colornames.Blue,
 		colornames.Brown,
 	}
-	RemoveButtonTopPadding float32 = 5
-	smallestGraphScope             = 1e-12
+	smallestGraphScope = 1e-12
+
+	// margin around the drawing area for the title and the labels
+	graphMargin float32 = 50
 )
 
 var (
-	// color of the title
-	titleColor = &color.White
-
-	// color of the axes
-	axesColor = &color.White
-
-	// color of the legend at the axis
-	legendColor = &color.White
-
-	// colors for the graph
-	lineColor = &color.NRGBA{R: 0, G: 0, B: 255, A: 255}
-
-	// colors for the points
-	pointColor = &color.NRGBA{R: 0, G: 255, B: 0, A: 255}
-
-	// colors for the error
-	errorColor = &color.NRGBA{R: 255, G: 0, B: 0, A: 128}
-
-	// color for the gridlines
-	gridColor = &color.NRGBA{R: 128, G: 128, B: 128, A: 64}
-
-	// color for the minor gridlines (for log scale)
-	gridMinorColor = &color.NRGBA{R: 128, G: 128, B: 128, A: 48}
-
 	// size of the points
 	pointRadius = float32(0.5)
 )
 
 // GraphConfig configures the basic struct for a graph
 type GraphConfig struct {
-	Title        string
-	IsLog        bool
-	AdaptDraw    bool
+	Title string
+	// scaling of the axes, can be changed in the menu of the graph
+	XScale ScaleMode
+	YScale ScaleMode
+	// axis titles with units, f.e. "qz [Å⁻¹]"
+	XLabel string
+	YLabel string
+	// quantities to show instead of the y values, selected by Quantity (none shows the y values)
+	Quantities []Quantity
+	Quantity   int
+
 	Resolution   int
 	Functions    []*function.Function
 	DisplayRange *GraphRange
+
+	// draw the functions as points without connecting lines
+	Scatter bool
+	// minimum size of the graph, zero uses the default size
+	MinSize fyne.Size
+	// names and looks of the functions by index, missing entries use the default style
+	FunctionStyles []TrackStyle
+	// functions without a color use the colors of the data tracks by index, f.e. the residuals of each data track,
+	// except the first ModelFunctions functions
+	DataTrackColors bool
+	ModelFunctions  int
+	// half widths of bands around y = 0, f.e. 1 and 2 for ±1σ and ±2σ bands (not on a log scale)
+	Bands []float64
+}
+
+// Quantity transforms the points of a graph for drawing, f.e. R to R·q⁴
+type Quantity struct {
+	// name in the menu of the graph
+	Name string
+	// y axis title, empty uses the one of the config
+	Label string
+	// changes the y values and errors of copied points, nil keeps them
+	Transform func(points function.Points)
+}
+
+// selected quantity, nil shows the y values
+func (c *GraphConfig) quantity() *Quantity {
+	if c.Quantity < 0 || c.Quantity >= len(c.Quantities) {
+		return nil
+	}
+	return &c.Quantities[c.Quantity]
+}
+
+// y axis title of the selected quantity
+func (c *GraphConfig) yLabel() string {
+	if q := c.quantity(); q != nil && q.Label != "" {
+		return q.Label
+	}
+	return c.YLabel
 }
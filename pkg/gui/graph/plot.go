@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+)
+
+// Plot describes a drawn graph independent of the backend, f.e. the screen or an SVG file
+// positions are in pixels from the top left corner, items are drawn in order
+type Plot struct {
+	Width  float32
+	Height float32
+	Items  []PlotItem
+}
+
+// PlotItem is one of PlotLine, PlotCircle, PlotRect and PlotText
+type PlotItem interface {
+	plotItem()
+}
+
+// PlotLine is a straight line
+type PlotLine struct {
+	X1, Y1, X2, Y2 float32
+	Color          color.Color
+	Width          float32
+	// lengths of the drawn and the skipped parts of a dashed line, nil is solid
+	Dash []float32
+	// distance into the dash pattern at the start, continues the pattern of the previous line
+	DashOffset float32
+}
+
+// PlotCircle is a circle, f.e. a data point
+type PlotCircle struct {
+	X, Y, Radius float32
+	Fill         color.Color
+	Stroke       color.Color
+	StrokeWidth  float32
+}
+
+// PlotRect is a rectangle, a nil fill or stroke is not drawn
+type PlotRect struct {
+	X, Y, Width, Height float32
+	Fill                color.Color
+	Stroke              color.Color
+	StrokeWidth         float32
+}
+
+// PlotText is a single line of text with its top left corner at the position
+type PlotText struct {
+	X, Y  float32
+	Text  string
+	Size  float32
+	Bold  bool
+	Color color.Color
+	// distance of the baseline from the top
+	Baseline float32
+}
+
+func (PlotLine) plotItem()   {}
+func (PlotCircle) plotItem() {}
+func (PlotRect) plotItem()   {}
+func (PlotText) plotItem()   {}
+
+func (p *Plot) add(item PlotItem) {
+	p.Items = append(p.Items, item)
+}
+
+// measures a text like it is drawn on the screen, returns its width and the distance of the baseline from the top
+func measureText(text string, size float32, bold bool) (float32, float32) {
+	if fyne.CurrentApp() == nil {
+		return float32(len([]rune(text))) * size * 0.6, size * 0.8
+	}
+	textSize, baseline := fyne.CurrentApp().Driver().RenderedTextSize(text, size, fyne.TextStyle{Bold: bold}, nil)
+	return textSize.Width, baseline
+}
+
+// CanvasObjects converts the plot to Fyne canvas objects
+func (p *Plot) CanvasObjects() []fyne.CanvasObject {
+	objects := make([]fyne.CanvasObject, 0, len(p.Items))
+	for _, item := range p.Items {
+		switch i := item.(type) {
+		case PlotLine:
+			for _, d := range i.dashes() {
+				objects = append(objects, &canvas.Line{
+					StrokeColor: i.Color,
+					StrokeWidth: i.Width,
+					Position1:   fyne.NewPos(d.X1, d.Y1),
+					Position2:   fyne.NewPos(d.X2, d.Y2),
+				})
+			}
+		case PlotCircle:
+			objects = append(objects, &canvas.Circle{
+				FillColor:   i.Fill,
+				StrokeColor: i.Stroke,
+				StrokeWidth: i.StrokeWidth,
+				Position1:   fyne.NewPos(i.X-i.Radius, i.Y-i.Radius),
+				Position2:   fyne.NewPos(i.X+i.Radius, i.Y+i.Radius),
+			})
+		case PlotRect:
+			rect := &canvas.Rectangle{
+				FillColor:   i.Fill,
+				StrokeColor: i.Stroke,
+				StrokeWidth: i.StrokeWidth,
+			}
+			rect.Move(fyne.NewPos(i.X, i.Y))
+			rect.Resize(fyne.NewSize(i.Width, i.Height))
+			objects = append(objects, rect)
+		case PlotText:
+			text := &canvas.Text{
+				Text:      i.Text,
+				Color:     i.Color,
+				TextSize:  i.Size,
+				TextStyle: fyne.TextStyle{Bold: i.Bold},
+			}
+			text.Move(fyne.NewPos(i.X, i.Y))
+			objects = append(objects, text)
+		}
+	}
+	return objects
+}
+
+// length of the line in pixels
+func (l PlotLine) length() float32 {
+	return float32(math.Hypot(float64(l.X2-l.X1), float64(l.Y2-l.Y1)))
+}
+
+// splits a dashed line into its drawn parts, a solid line is returned as it is
+func (l PlotLine) dashes() []PlotLine {
+	period := float32(0)
+	for _, d := range l.Dash {
+		period += d
+	}
+	length := l.length()
+	if period <= 0 || length == 0 {
+		return []PlotLine{l}
+	}
+
+	dashes := make([]PlotLine, 0)
+	point := func(t float32) (float32, float32) {
+		return l.X1 + (l.X2-l.X1)*t/length, l.Y1 + (l.Y2-l.Y1)*t/length
+	}
+	// start of the current part of the pattern relative to the start of the line
+	start := -float32(math.Mod(float64(l.DashOffset), float64(period)))
+	for i := 0; start < length; i = (i + 1) % len(l.Dash) {
+		end := start + l.Dash[i]
+		if i%2 == 0 && end > 0 {
+			dash := l
+			dash.Dash = nil
+			dash.X1, dash.Y1 = point(max(start, 0))
+			dash.X2, dash.Y2 = point(min(end, length))
+			dashes = append(dashes, dash)
+		}
+		start = end
+	}
+	return dashes
+}
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"physicsGUI/pkg/function"
+)
+
+// readoutPoint is a point as drawn together with the point it shows
+type readoutPoint struct {
+	shown    *function.Point
+	original *function.Point
+}
+
+// remembers the points of a track for the cursor readout, shown points are the original ones
+// after the selected quantity was applied
+func (r *GraphRenderer) addReadout(shown, original function.Points) {
+	for i := range min(len(shown), len(original)) {
+		r.readout = append(r.readout, readoutPoint{shown: shown[i], original: original[i]})
+	}
+}
+
+// draw the zoom box, the crosshair at the mouse and the readout of the cursor position and the nearest point
+func (r *GraphRenderer) DrawInteraction() {
+	nav := r.graph.navigation()
+
+	if nav.boxZoom && nav.dragging {
+		r.plot.add(PlotRect{
+			X:           min(nav.boxStart.X, nav.boxEnd.X),
+			Y:           min(nav.boxStart.Y, nav.boxEnd.Y),
+			Width:       float32(math.Abs(float64(nav.boxEnd.X - nav.boxStart.X))),
+			Height:      float32(math.Abs(float64(nav.boxEnd.Y - nav.boxStart.Y))),
+			Fill:        r.theme.Band,
+			Stroke:      r.theme.Cursor,
+			StrokeWidth: 1,
+		})
+	}
+
+	if nav.cursor == nil {
+		return
+	}
+	px, py := nav.cursor.X-r.margin, r.size.Height-r.margin-nav.cursor.Y
+	if !r.inside(px, py) {
+		return
+	}
+
+	// crosshair
+	x, y := r.normalize(px, py)
+	r.addLine(x, r.size.Height-r.margin, x, r.size.Height-r.margin-r.y.pixels, r.theme.Cursor, 1)
+	r.addLine(r.margin, y, r.margin+r.x.pixels, y, r.theme.Cursor, 1)
+
+	lines := []string{fmt.Sprintf("x = %.4g, y = %.4g", r.x.fromPixel(px), r.y.fromPixel(py))}
+	if nearest := r.nearest(px, py); nearest != nil {
+		nx, ny := r.normalize(r.x.toPixel(nearest.shown.X), r.y.toPixel(nearest.shown.Y))
+		r.plot.add(PlotCircle{X: nx, Y: ny, Radius: 4, Stroke: r.theme.Cursor, StrokeWidth: 1})
+		lines = append(lines, fmt.Sprintf("nearest: (%.4g, %.4g ± %.2g)", nearest.original.X, nearest.original.Y, nearest.original.Error))
+	}
+	r.DrawReadout(lines)
+}
+
+// nearest visible point to a position in pixels from the bottom left of the drawing area
+func (r *GraphRenderer) nearest(px, py float32) *readoutPoint {
+	var nearest *readoutPoint
+	best := math.Inf(1)
+	for i, p := range r.readout {
+		x, y := r.x.toPixel(p.shown.X), r.y.toPixel(p.shown.Y)
+		if !r.inside(x, y) {
+			continue
+		}
+		d := math.Hypot(float64(x-px), float64(y-py))
+		if d < best {
+			best = d
+			nearest = &r.readout[i]
+		}
+	}
+	return nearest
+}
+
+// draw lines of text in the top left corner of the drawing area
+func (r *GraphRenderer) DrawReadout(lines []string) {
+	const lineHeight = 16
+	width := float32(0)
+	for _, line := range lines {
+		lineWidth, _ := measureText(line, 12, false)
+		width = max(width, lineWidth)
+	}
+
+	x, y := r.margin+5, r.size.Height-r.margin-r.y.pixels+5
+	r.plot.add(PlotRect{X: x, Y: y, Width: width + 10, Height: float32(len(lines))*lineHeight + 6, Fill: r.theme.ReadoutBackground})
+	for i, line := range lines {
+		r.addText(line, x+5, y+3+float32(i)*lineHeight, 12, false, r.theme.Labels)
+	}
+}
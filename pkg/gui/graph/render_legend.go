@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"image/color"
+)
+
+// half of the size of the markers except the dot
+const markerSize = 3
+
+// draw the marker of a point, dots have the radius
+func (r *GraphRenderer) DrawMarker(x, y float32, marker MarkerStyle, markerColor color.Color, radius float32) {
+	switch marker {
+	case MarkerAuto, MarkerDot:
+		r.plot.add(PlotCircle{X: x, Y: y, Radius: radius, Fill: markerColor})
+	case MarkerCircle:
+		r.plot.add(PlotCircle{X: x, Y: y, Radius: markerSize, Stroke: markerColor, StrokeWidth: 1})
+	case MarkerSquare:
+		r.plot.add(PlotRect{X: x - markerSize, Y: y - markerSize, Width: 2 * markerSize, Height: 2 * markerSize, Stroke: markerColor, StrokeWidth: 1})
+	case MarkerTriangle:
+		r.addLine(x-markerSize, y+markerSize, x+markerSize, y+markerSize, markerColor, 1)
+		r.addLine(x+markerSize, y+markerSize, x, y-markerSize, markerColor, 1)
+		r.addLine(x, y-markerSize, x-markerSize, y+markerSize, markerColor, 1)
+	case MarkerCross:
+		r.addLine(x-markerSize, y-markerSize, x+markerSize, y+markerSize, markerColor, 1)
+		r.addLine(x-markerSize, y+markerSize, x+markerSize, y-markerSize, markerColor, 1)
+	}
+}
+
+// draw the names and samples of the visible tracks with a name in the top right corner of the drawing area,
+// the first styles belong to the functions
+func (r *GraphRenderer) DrawLegend(styles []TrackStyle, functions int) {
+	const (
+		lineHeight   = 16
+		sampleLength = 20
+	)
+
+	entries := make([]int, 0, len(styles))
+	width := float32(0)
+	for i, style := range styles {
+		if style.Hidden || style.Name == "" {
+			continue
+		}
+		entries = append(entries, i)
+		nameWidth, _ := measureText(style.Name, 12, false)
+		width = max(width, nameWidth)
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	width += sampleLength + 20
+	x, y := r.margin+r.x.pixels-width-5, r.size.Height-r.margin-r.y.pixels+5
+	r.plot.add(PlotRect{
+		X: x, Y: y, Width: width, Height: float32(len(entries))*lineHeight + 6,
+		Fill: r.theme.ReadoutBackground, Stroke: r.theme.Grid, StrokeWidth: 1,
+	})
+	for row, i := range entries {
+		style := styles[i]
+		isDataSet := i >= functions
+		center := y + 3 + float32(row)*lineHeight + lineHeight/2
+		if line := r.lineStyle(style, isDataSet); line != LineNone {
+			r.plot.add(PlotLine{X1: x + 5, Y1: center, X2: x + 5 + sampleLength, Y2: center, Color: style.Color, Width: 1, Dash: line.dash()})
+		}
+		// dots are too small to be seen in the legend
+		r.DrawMarker(x+5+sampleLength/2, center, style.Marker, style.Color, 2)
+		r.addText(style.Name, x+10+sampleLength, center-lineHeight/2, 12, false, r.theme.Labels)
+	}
+}
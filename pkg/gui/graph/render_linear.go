@@ -2,82 +2,11 @@ package graph
 
 import (
 	"fmt"
-	"image/color"
 	"math"
-	"physicsGUI/pkg/function"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 )
 
-// draw a linear graph
-func (r *GraphRenderer) DrawGraphLinear(scope *function.Scope, points, iPoints function.Points, pointColor color.Color, isDataSet bool) {
-	// calc available space
-	availableWidth := r.size.Width - (1.5 * r.margin)
-	availableHeight := r.size.Height - (1.5 * r.margin)
-
-	orderY := int(math.Floor(math.Log10(math.Abs(scope.MaxY - scope.MinY))))
-	orderX := int(math.Floor(math.Log10(math.Abs(scope.MaxX - scope.MinX))))
-	minX := floorInOrder(scope.MinX, orderX)
-	minY := floorInOrder(scope.MinY, orderY)
-	maxX := ceilInOrder(scope.MaxX, orderX)
-	maxY := ceilInOrder(scope.MaxY, orderY)
-
-	// complete range
-	xRange := math.Abs(maxX - minX)
-	yRange := math.Abs(maxY - minY)
-
-	oX, oY := float32(0), float32(0)
-
-	// draw line based on interpolated (resolution) points
-	if !isDataSet {
-		for i, point := range iPoints {
-			// scale x value to available width
-			x := float32((point.X-minX)/xRange) * availableWidth
-			y := float32((point.Y-minY)/yRange) * availableHeight
-
-			// first point is the origin
-			if i == 0 {
-				oX, oY = r.normalize(x, y)
-				continue
-			}
-
-			xt, yt := r.normalize(x, y)
-
-			// draw line
-			r.AddObject(&canvas.Line{
-				StrokeColor: pointColor,
-				StrokeWidth: 1,
-				Position1:   fyne.NewPos(oX, oY),
-				Position2:   fyne.NewPos(xt, yt),
-			})
-
-			oX, oY = xt, yt
-		}
-	}
-
-	// draw data points
-	for _, point := range points {
-		// scale x value to available width
-		x := float32((point.X-minX)/xRange) * availableWidth
-		y := float32((point.Y-minY)/yRange) * availableHeight
-
-		xt, yt := r.normalize(x, y)
-
-		if isDataSet {
-			// error correction
-			yE1 := float32((point.Y+point.Error-minY)/yRange) * availableHeight
-			yE2 := float32((point.Y-point.Error-minY)/yRange) * availableHeight
-
-			_, e1 := r.normalize(x, yE1)
-			_, e2 := r.normalize(x, yE2)
-
-			r.DrawError(xt, e1, e2, errorColor)
-		}
-		r.DrawPoint(xt, yt, pointColor)
-	}
-}
-
 // needed for pretty grids
 func floorInOrder(num float64, order int) float64 {
 	return math.Floor(num*math.Pow10(-order)) * math.Pow10(order)
@@ -88,68 +17,57 @@ func ceilInOrder(num float64, order int) float64 {
 	return math.Ceil(num*math.Pow10(-order)) * math.Pow10(order)
 }
 
-// draw grid lines and labels for linear scale
-func (r *GraphRenderer) DrawGridLinear(scope *function.Scope) {
-
-	//
-	orderY := int(math.Floor(math.Log10(math.Abs(scope.MaxY - scope.MinY))))
-	orderX := int(math.Floor(math.Log10(math.Abs(scope.MaxX - scope.MinX))))
-	minX := floorInOrder(scope.MinX, orderX)
-	minY := floorInOrder(scope.MinY, orderY)
-	maxX := ceilInOrder(scope.MaxX, orderX)
-	maxY := ceilInOrder(scope.MaxY, orderY)
+// format of small labels of an axis, f.e. 1e-05 on log axes
+func labelFormat(mode ScaleMode) string {
+	if mode == ScaleLinear {
+		return "%.1e"
+	}
+	return "%.0e"
+}
 
+// draw the major grid lines with their labels
+func (r *GraphRenderer) DrawGrid() {
 	// horizontal grid-lines + y-labels
-	yGridCount := int(math.Ceil((maxY - minY) * math.Pow10(-orderY)))
-	yStep := (maxY - minY) / float64(yGridCount)
-
-	for i := 0; i <= yGridCount; i++ {
-		value := minY + yStep*float64(i)
-		yPos := r.size.Height - r.margin - float32(i)*float32(r.size.Height-1.5*r.margin)/float32(yGridCount)
-
-		if i > 0 {
+	for _, t := range r.y.ticks() {
+		p := r.y.pixelOf(t)
+		yPos := r.size.Height - r.margin - p
+		if p > 0.5 {
 			r.DrawGridLine(fyne.NewPos(r.margin, yPos), false, false)
 		}
 
-		text := fmt.Sprintf("%.3f", value)
-		if value < 0.01 {
-			text = fmt.Sprintf("%.1e", value)
-		}
-
-		// label
-		label := &canvas.Text{
-			Text:     text,
-			Color:    legendColor,
-			TextSize: 12,
-		}
-		label.Move(fyne.NewPos(r.margin-45, yPos-10))
-		r.AddObject(label)
+		r.addText(gridLabel(r.y.inverse(t), labelFormat(r.y.mode)), r.margin-45, yPos-10, 12, false, r.theme.Labels)
 	}
 
-	// vertical grid-lines + x-labels
-	xGridCount := int(math.Ceil((maxX - minX) * math.Pow10(-orderX)))
-	xStep := math.Abs(maxX-minX) / float64(xGridCount)
-
-	for i := 0; i <= xGridCount; i++ {
-		xPos := r.margin + float32(i)*float32(r.size.Width-1.5*r.margin)/float32(xGridCount)
-
-		if i > 0 {
+	// vertical grid-lines + x-labels, longer labels of log axes are moved further left
+	labelShift := float32(15)
+	if r.x.mode != ScaleLinear {
+		labelShift = 25
+	}
+	for _, t := range r.x.ticks() {
+		p := r.x.pixelOf(t)
+		xPos := r.margin + p
+		if p > 0.5 {
 			r.DrawGridLine(fyne.NewPos(xPos, r.margin/2), true, false)
 		}
-		v := minX + xStep*float64(i)
-		text := fmt.Sprintf("%.3f", v)
-		if v < 0.01 {
-			text = fmt.Sprintf("%.1e", v)
-		}
 
-		// label
-		label := &canvas.Text{
-			Text:     text,
-			Color:    legendColor,
-			TextSize: 12,
-		}
+		r.addText(gridLabel(r.x.inverse(t), labelFormat(r.x.mode)), xPos-labelShift, r.size.Height-r.margin+10, 12, false, r.theme.Labels)
+	}
+}
+
+// draw the axis titles and a note about points that cannot be drawn on a log axis
+func (r *GraphRenderer) DrawAxisLabels(hidden int) {
+	if xLabel := r.graph.Config.XLabel; xLabel != "" {
+		width, _ := measureText(xLabel, 12, true)
+		r.addText(xLabel, r.margin+r.x.pixels/2-width/2, r.size.Height-r.margin+25, 12, true, r.theme.Labels)
+	}
+
+	// above the y axis, as texts cannot be rotated
+	if yLabel := r.graph.Config.yLabel(); yLabel != "" {
+		r.addText(yLabel, 4, 0, 12, true, r.theme.Labels)
+	}
 
-		label.Move(fyne.NewPos(xPos-15, r.size.Height-r.margin+10))
-		r.AddObject(label)
+	if hidden > 0 {
+		note := fmt.Sprintf("%d points <= 0 not shown on the log scale", hidden)
+		r.addText(note, r.margin+5, r.size.Height-r.margin-16, 10, false, r.theme.Labels)
 	}
 }
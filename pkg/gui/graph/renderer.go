@@ -1,18 +1,34 @@
 package graph
 
 import (
+	"fmt"
 	"image/color"
 	"math"
 	"physicsGUI/pkg/function"
+	"slices"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 )
 
 type GraphRenderer struct {
+	lock    sync.Mutex
 	graph   *GraphCanvas
 	objects []fyne.CanvasObject
 
+	// description of the drawn graph, converted to the objects
+	plot  *Plot
+	theme Theme
+	// exported graphs are drawn without the mouse navigation
+	export bool
+
+	// axes of the last layout
+	x axisView
+	y axisView
+	// points shown in the cursor readout
+	readout []readoutPoint
+
 	// size of canvas
 	size *fyne.Size
 
@@ -27,53 +43,46 @@ type GraphRange struct {
 
 // returns the minimum size needed for the graph
 func (r *GraphRenderer) MinSize() fyne.Size {
+	if !r.graph.Config.MinSize.IsZero() {
+		return r.graph.Config.MinSize
+	}
 	return fyne.NewSize(500, 200)
 }
 
 // initializes the base strcuture for every graph
 func (r *GraphRenderer) base() {
 	// background
-	r.graph.background.Resize(*r.size)
-	r.graph.background.Move(fyne.NewPos(0, 0))
-	r.AddObject(r.graph.background)
+	r.plot.add(PlotRect{Width: r.size.Width, Height: r.size.Height, Fill: r.theme.Background})
 
 	// title
-	title := &canvas.Text{
-		Text:      r.graph.Config.Title,
-		Color:     titleColor,
-		TextSize:  16,
-		TextStyle: fyne.TextStyle{Bold: true},
-	}
-	title.Move(fyne.NewPos(r.size.Width/2-float32(len(title.Text)*4), 0))
-	r.AddObject(title)
+	r.addText(r.graph.Config.Title, r.size.Width/2-float32(len(r.graph.Config.Title)*4), 0, 16, true, r.theme.Title)
 
 	// x-axis
-	r.AddObject(&canvas.Line{
-		StrokeColor: axesColor,
-		StrokeWidth: 1,
-		Position1:   fyne.NewPos(r.margin-2, r.size.Height-r.margin+2),
-		Position2:   fyne.NewPos(r.size.Width-r.margin/2, r.size.Height-r.margin+2),
-	})
+	r.addLine(r.margin-2, r.size.Height-r.margin+2, r.size.Width-r.margin/2, r.size.Height-r.margin+2, r.theme.Axes, 1)
 
 	// y-axis
-	r.AddObject(&canvas.Line{
-		StrokeColor: axesColor,
-		StrokeWidth: 1,
-		Position1:   fyne.NewPos(r.margin-2, r.size.Height-r.margin+2),
-		Position2:   fyne.NewPos(r.margin-2, 0.5*r.margin),
-	})
+	r.addLine(r.margin-2, r.size.Height-r.margin+2, r.margin-2, 0.5*r.margin, r.theme.Axes, 1)
 }
 
 // draws the whole graph
 func (r *GraphRenderer) Layout(size fyne.Size) {
-	// clear objects
-	r.objects = make([]fyne.CanvasObject, 0)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.draw(size)
+	r.objects = r.plot.CanvasObjects()
+}
+
+// draws the graph into a new plot
+func (r *GraphRenderer) draw(size fyne.Size) {
+	r.plot = &Plot{Width: size.Width, Height: size.Height}
+	r.readout = r.readout[:0]
 
 	// size of the graph
 	r.size = &size
 
 	// About layout when size is zero and therefor component is not visible
-	if r.size.Width < r.MinSize().Width || r.size.Height < r.MinSize().Height {
+	if !r.export && (r.size.Width < r.MinSize().Width || r.size.Height < r.MinSize().Height) {
 		return
 	}
 
@@ -89,134 +98,214 @@ func (r *GraphRenderer) Layout(size fyne.Size) {
 		}
 	}
 
-	// calculate the maximum scope
-	var scope = &function.Scope{
-		MinX: math.MaxFloat64,
-		MinY: math.MaxFloat64,
-		MaxX: -math.MaxFloat64,
-		MaxY: -math.MaxFloat64,
+	if (len(r.graph.functions) == 0 || r.graph.functions[0].GetDataCount() < 1) && len(r.graph.loadedData) == 0 {
+		r.DrawErrorMessage("No data available")
+		return
+	}
+
+	// points as drawn, the selected quantity and the offset change copies of the data, hidden tracks stay empty
+	tracks := append(slices.Clone(r.graph.functions), r.graph.loadedData...)
+	styles := make([]TrackStyle, len(tracks))
+	for i := range tracks {
+		if i < len(r.graph.functions) {
+			styles[i] = r.graph.functionStyle(i, r.theme)
+		} else {
+			styles[i] = r.graph.dataStyle(i-len(r.graph.functions), r.theme)
+		}
 	}
-	funcCount := len(r.graph.functions)
-	var magicPoints []function.Points
-	if r.graph.Config.AdaptDraw {
-		magicPoints = make([]function.Points, funcCount+len(r.graph.loadedData))
-		for i, f := range r.graph.functions {
-			magicPoints[i] = f.GetData().Copy()
-			tempScope := magicPoints[i].Magie()
-			scope.CombineScope(&tempScope)
+	quantity := r.graph.Config.quantity()
+	shown := make([]function.Points, len(tracks))
+	for i, f := range tracks {
+		if styles[i].Hidden {
+			continue
 		}
-		for i, f := range r.graph.loadedData {
-			l := i + funcCount
-			magicPoints[l] = f.GetData().Copy()
-			tempScope := magicPoints[l].Magie()
-			scope.CombineScope(&tempScope)
+		shown[i] = f.GetData().Copy().Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max)
+		if quantity != nil && quantity.Transform != nil {
+			quantity.Transform(shown[i])
 		}
-
-	} else {
-		scope = function.GetMaximumScope(append(r.graph.functions, r.graph.loadedData...)...)
+		styles[i].applyOffset(shown[i], r.graph.Config.YScale)
 	}
-	if scope.MinX == scope.MaxX {
-		scope.MinX = scope.MinX - smallestGraphScope
-		scope.MaxX = scope.MaxX + smallestGraphScope
+
+	// collect the values to fit the axes to, points with values <= 0 cannot be drawn on a log axis
+	xValues, yValues := make([]float64, 0), make([]float64, 0)
+	hidden := 0
+	for _, points := range shown {
+		for _, p := range points {
+			xValues = append(xValues, p.X)
+			yValues = append(yValues, p.Y)
+			if (r.graph.Config.XScale == ScaleLog && p.X <= 0) || (r.graph.Config.YScale == ScaleLog && p.Y <= 0) {
+				hidden++
+			}
+		}
 	}
-	if scope.MinY == scope.MaxY {
-		scope.MinY = scope.MinY - smallestGraphScope
-		scope.MaxY = scope.MaxY + smallestGraphScope
+	// show the widest band completely
+	if len(r.graph.Config.Bands) > 0 && r.graph.Config.YScale != ScaleLog {
+		widest := slices.Max(r.graph.Config.Bands)
+		yValues = append(yValues, -widest, widest)
 	}
 
-	if scope == nil {
-		r.DrawErrorMessage("Scope error")
+	// axes fitting all data, replaced by the view while zoomed or panned
+	var okX, okY bool
+	r.x, okX = fitAxis(xValues, r.graph.Config.XScale, r.size.Width-1.5*r.margin)
+	r.y, okY = fitAxis(yValues, r.graph.Config.YScale, r.size.Height-1.5*r.margin)
+	if !okX || !okY {
+		r.DrawErrorMessage("No data to show on this scale")
 		return
 	}
+	r.x, r.y = r.graph.applyView(r.x, r.y, !r.export)
 
-	if (len(r.graph.functions) == 0 || r.graph.functions[0].GetDataCount() < 1) && len(r.graph.loadedData) == 0 {
-		r.DrawErrorMessage("No data available")
-		return
+	if r.y.mode != ScaleLog {
+		r.DrawBands()
 	}
 
-	// Add Remove Buttons
-	r.DrawRemoveButtons()
-
-	// draw model lines
-	if r.graph.Config.IsLog {
-		for i, f := range r.graph.functions {
-			var points function.Points
-			if r.graph.Config.AdaptDraw {
-				points = magicPoints[i]
-			} else {
-				points = f.GetData().Copy()
-			}
-			r.DrawGraphLog(scope, points, points, pointColor, false)
+	// draw model lines and data tracks
+	for i, points := range shown {
+		if styles[i].Hidden {
+			continue
 		}
-		for i, d := range r.graph.loadedData {
-			d.Range(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max)
-			var points function.Points
-			if r.graph.Config.AdaptDraw {
-				points = magicPoints[funcCount+i]
-			} else {
-				points = d.GetData().Copy()
+		isDataSet := i >= len(r.graph.functions)
+		r.DrawGraph(points, styles[i], isDataSet)
+
+		// the readout shows the nearest data point, or the nearest point of a function if there is no data
+		if isDataSet || len(r.graph.loadedData) == 0 {
+			r.addReadout(points, tracks[i].GetData().Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max))
+		}
+	}
+
+	r.DrawMinorGrid()
+	r.DrawGrid()
+	r.DrawAxisLabels(hidden)
+	r.DrawLegend(styles, len(r.graph.functions))
+
+	if !r.export {
+		r.DrawInteraction()
+	}
+}
+
+// draw the points of a function or data set with their style, the lines between the points are clipped to the drawing area
+func (r *GraphRenderer) DrawGraph(points function.Points, style TrackStyle, isDataSet bool) {
+	line := r.lineStyle(style, isDataSet)
+
+	// draw lines between the points, the dash pattern continues from line to line
+	if line != LineNone {
+		dashOffset := float32(0)
+		for i := 1; i < len(points); i++ {
+			x1, y1, x2, y2, ok := r.clipLine(
+				r.x.toPixel(points[i-1].X), r.y.toPixel(points[i-1].Y),
+				r.x.toPixel(points[i].X), r.y.toPixel(points[i].Y))
+			if !ok {
+				continue
 			}
-			dataColor := DataTrackColors[i%len(DataTrackColors)]
-			r.DrawGraphLog(scope, points, points, dataColor, true)
+			x1, y1 = r.normalize(x1, y1)
+			x2, y2 = r.normalize(x2, y2)
+			segment := PlotLine{X1: x1, Y1: y1, X2: x2, Y2: y2, Color: style.Color, Width: 1, Dash: line.dash(), DashOffset: dashOffset}
+			r.plot.add(segment)
+			dashOffset += segment.length()
 		}
-		r.DrawGridLog(scope)
-		return
 	}
 
-	for i, f := range r.graph.functions {
-		var points function.Points
-		if r.graph.Config.AdaptDraw {
-			points = magicPoints[i]
-		} else {
-			points = f.GetData().Copy()
+	// draw data points
+	for _, point := range points {
+		x, y := r.x.toPixel(point.X), r.y.toPixel(point.Y)
+		if !r.inside(x, y) {
+			continue
 		}
-		r.DrawGraphLinear(scope,
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			pointColor, false)
+
+		xt, yt := r.normalize(x, y)
+		if isDataSet {
+			// error bars end at the border of the drawing area
+			_, e1 := r.normalize(x, r.clampY(r.y.toPixel(point.Y+point.Error)))
+			_, e2 := r.normalize(x, r.clampY(r.y.toPixel(point.Y-point.Error)))
+			r.DrawError(xt, e1, e2, r.theme.Error)
+		}
+		r.DrawMarker(xt, yt, style.Marker, style.Color, pointRadius)
 	}
-	for i, d := range r.graph.loadedData {
-		var points function.Points
-		if r.graph.Config.AdaptDraw {
-			points = magicPoints[funcCount+i]
-		} else {
-			points = d.GetData().Copy()
+}
+
+// line style of a track, automatic lines are drawn for functions of graphs that are no scatter graphs
+func (r *GraphRenderer) lineStyle(style TrackStyle, isDataSet bool) LineStyle {
+	if style.Line != LineAuto {
+		return style.Line
+	}
+	if isDataSet || r.graph.Config.Scatter {
+		return LineNone
+	}
+	return LineSolid
+}
+
+// draw the bands around y = 0, the widest band first
+func (r *GraphRenderer) DrawBands() {
+	bands := slices.Clone(r.graph.Config.Bands)
+	slices.Sort(bands)
+	slices.Reverse(bands)
+	for _, b := range bands {
+		x, yTop := r.normalize(0, r.clampY(r.y.toPixel(b)))
+		_, yBottom := r.normalize(0, r.clampY(r.y.toPixel(-b)))
+		if yBottom <= yTop {
+			continue
 		}
-		dataColor := DataTrackColors[i%len(DataTrackColors)]
-		r.DrawGraphLinear(scope,
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			points.Filter(r.graph.Config.DisplayRange.Min, r.graph.Config.DisplayRange.Max),
-			dataColor, true)
+
+		r.plot.add(PlotRect{X: x, Y: yTop, Width: r.x.pixels, Height: yBottom - yTop, Fill: r.theme.Band})
+	}
+}
+
+// label text of a grid line
+func gridLabel(value float64, format string) string {
+	if math.Abs(value) < 0.01 && value != 0 {
+		return fmt.Sprintf(format, value)
+	}
+	return fmt.Sprintf("%.3f", value)
+}
+
+// reports whether a point in pixels from the bottom left lies in the drawing area
+func (r *GraphRenderer) inside(x, y float32) bool {
+	return x >= 0 && x <= r.x.pixels && y >= 0 && y <= r.y.pixels
+}
+
+// limits a height in pixels to the drawing area, NaN (f.e. the log of a negative value) is the bottom
+func (r *GraphRenderer) clampY(y float32) float32 {
+	if y != y {
+		return 0
 	}
-	r.DrawGridLinear(scope)
+	return min(max(y, 0), r.y.pixels)
 }
 
-// display remove buttons at the right border
-func (r *GraphRenderer) DrawRemoveButtons() {
-	offsetY := float32(0)
-	startY := float32(0)
-	startX := r.size.Width - r.margin
-	for _, d := range r.graph.dataRemoveButtons {
-		offsetY += d.Size().Height + RemoveButtonTopPadding
-		for _, o := range d.Objects {
-			o.Move(fyne.NewPos(startX, startY+offsetY))
-			r.AddObject(o)
+// clips the line between two points in pixels to the drawing area (Liang-Barsky),
+// returns false if the line lies outside
+func (r *GraphRenderer) clipLine(x1, y1, x2, y2 float32) (float32, float32, float32, float32, bool) {
+	// NaN or infinite points are not drawn
+	for _, v := range []float32{x1, y1, x2, y2} {
+		if v != v || math.IsInf(float64(v), 0) {
+			return 0, 0, 0, 0, false
 		}
 	}
+
+	dx, dy := x2-x1, y2-y1
+	t0, t1 := float32(0), float32(1)
+	for _, edge := range [][2]float32{{-dx, x1}, {dx, r.x.pixels - x1}, {-dy, y1}, {dy, r.y.pixels - y1}} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return 0, 0, 0, 0, false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = max(t0, t)
+		} else {
+			t1 = min(t1, t)
+		}
+		if t0 > t1 {
+			return 0, 0, 0, 0, false
+		}
+	}
+	return x1 + t0*dx, y1 + t0*dy, x1 + t1*dx, y1 + t1*dy, true
 }
 
 // draw an error message onto the graph
 func (r *GraphRenderer) DrawErrorMessage(message string) {
-	errorMsg := &canvas.Text{
-		Text:     message,
-		Color:    titleColor,
-		TextSize: 16,
-	}
-	errorMsg.Move(fyne.NewPos(
-		r.size.Width/2-float32(len(errorMsg.Text)*4),
-		r.size.Height/2-errorMsg.Size().Height/2-r.margin/2))
-
-	r.AddObject(errorMsg)
+	r.addText(message, r.size.Width/2-float32(len(message)*4), r.size.Height/2-r.margin/2, 16, false, r.theme.Title)
 }
 
 // normalizes the coodinates from the bottom left of the canvas
@@ -224,59 +313,58 @@ func (r *GraphRenderer) normalize(x float32, y float32) (float32, float32) {
 	return x + r.margin, r.size.Height - r.margin - y
 }
 
-// draw a grid point
-func (r *GraphRenderer) DrawPoint(x float32, y float32, pointColor color.Color) {
-	r.AddObject(&canvas.Circle{
-		FillColor: pointColor,
-		Position1: fyne.NewPos(x-pointRadius, y-pointRadius),
-		Position2: fyne.NewPos(x+pointRadius, y+pointRadius),
-	})
-}
-
 // draw error correction lines within bounds of graph
 func (r *GraphRenderer) DrawError(x, y1, y2 float32, errorColor color.Color) {
-	r.AddObject(&canvas.Line{
-		StrokeColor: errorColor,
-		StrokeWidth: 1,
-		Position1:   fyne.NewPos(x, min(max(y1, 0), r.size.Height)),
-		Position2:   fyne.NewPos(x, min(max(y2, 0), r.size.Height)),
-	})
+	r.addLine(x, min(max(y1, 0), r.size.Height), x, min(max(y2, 0), r.size.Height), errorColor, 1)
 }
 
 // helper function for the grid lines
 func (r *GraphRenderer) DrawGridLine(pos fyne.Position, isVertical, isMinor bool) {
-	line := &canvas.Line{
-		StrokeColor: gridColor,
-		StrokeWidth: 1,
-		Position1:   pos,
-	}
+	line := PlotLine{X1: pos.X, Y1: pos.Y, X2: pos.X, Y2: pos.Y, Color: r.theme.Grid, Width: 1}
 
 	if isVertical {
-		line.Position2 = fyne.NewPos(pos.X, pos.Y+r.size.Height-1.5*r.margin)
+		line.Y2 += r.size.Height - 1.5*r.margin
 	} else {
-		line.Position2 = fyne.NewPos(pos.X+r.size.Width-1.5*r.margin, pos.Y)
+		line.X2 += r.size.Width - 1.5*r.margin
 	}
 
 	if isMinor {
-		line.StrokeColor = gridMinorColor
-		line.StrokeWidth = 0.5
+		line.Color = r.theme.GridMinor
+		line.Width = 0.5
 	}
 
-	r.AddObject(line)
+	r.plot.add(line)
 }
 
 // returns the objects of the graph
 func (r *GraphRenderer) Objects() []fyne.CanvasObject {
+	r.lock.Lock()
+	defer r.lock.Unlock()
 	return r.objects
 }
 
 // destroy function (needs to be here to satisfy the interface)
 func (r *GraphRenderer) Destroy() {}
 
-// refresh function (needs to be here to satisfy the interface)
-func (r *GraphRenderer) Refresh() {}
+// redraws the graph, f.e. after the data, the view or the cursor changed
+func (r *GraphRenderer) Refresh() {
+	r.Layout(r.graph.Size())
+	canvas.Refresh(r.graph)
+}
 
 // add an object to the graph renderer
 func (r *GraphRenderer) AddObject(object fyne.CanvasObject) {
 	r.objects = append(r.objects, object)
 }
+
+// add a line to the plot
+func (r *GraphRenderer) addLine(x1, y1, x2, y2 float32, lineColor color.Color, width float32) {
+	r.plot.add(PlotLine{X1: x1, Y1: y1, X2: x2, Y2: y2, Color: lineColor, Width: width})
+}
+
+// add a text with its top left corner at the position to the plot, returns the width of the text
+func (r *GraphRenderer) addText(text string, x, y, size float32, bold bool, textColor color.Color) float32 {
+	width, baseline := measureText(text, size, bold)
+	r.plot.add(PlotText{X: x, Y: y, Text: text, Size: size, Bold: bold, Color: textColor, Baseline: baseline})
+	return width
+}
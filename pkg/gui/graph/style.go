@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"physicsGUI/pkg/function"
+	"strconv"
+	"strings"
+)
+
+// MarkerStyle is the symbol drawn at the points of a track
+type MarkerStyle int
+
+const (
+	// MarkerAuto is a small dot
+	MarkerAuto MarkerStyle = iota
+	MarkerNone
+	MarkerDot
+	MarkerCircle
+	MarkerSquare
+	MarkerTriangle
+	MarkerCross
+)
+
+// MarkerStyles are all marker styles in the order of the track editor
+var MarkerStyles = []MarkerStyle{MarkerAuto, MarkerNone, MarkerDot, MarkerCircle, MarkerSquare, MarkerTriangle, MarkerCross}
+
+func (m MarkerStyle) String() string {
+	return [...]string{"Auto", "None", "Dot", "Circle", "Square", "Triangle", "Cross"}[m]
+}
+
+// ParseMarkerStyle returns the marker style with the name, an empty name is MarkerAuto
+func ParseMarkerStyle(name string) (MarkerStyle, error) {
+	if name == "" {
+		return MarkerAuto, nil
+	}
+	for _, m := range MarkerStyles {
+		if strings.EqualFold(m.String(), name) {
+			return m, nil
+		}
+	}
+	return MarkerAuto, fmt.Errorf("unknown marker %q", name)
+}
+
+// LineStyle is the line between the points of a track
+type LineStyle int
+
+const (
+	// LineAuto is solid for functions and none for data tracks or scatter graphs
+	LineAuto LineStyle = iota
+	LineNone
+	LineSolid
+	LineDashed
+	LineDotted
+)
+
+// LineStyles are all line styles in the order of the track editor
+var LineStyles = []LineStyle{LineAuto, LineNone, LineSolid, LineDashed, LineDotted}
+
+func (l LineStyle) String() string {
+	return [...]string{"Auto", "None", "Solid", "Dashed", "Dotted"}[l]
+}
+
+// ParseLineStyle returns the line style with the name, an empty name is LineAuto
+func ParseLineStyle(name string) (LineStyle, error) {
+	if name == "" {
+		return LineAuto, nil
+	}
+	for _, l := range LineStyles {
+		if strings.EqualFold(l.String(), name) {
+			return l, nil
+		}
+	}
+	return LineAuto, fmt.Errorf("unknown line style %q", name)
+}
+
+// lengths of the drawn and the skipped parts of the line
+func (l LineStyle) dash() []float32 {
+	switch l {
+	case LineDashed:
+		return []float32{6, 4}
+	case LineDotted:
+		return []float32{1.5, 3}
+	default:
+		return nil
+	}
+}
+
+// TrackStyle is the name and the look of a data track or function
+type TrackStyle struct {
+	// name in the legend, functions without a name are not listed
+	Name string
+	// nil uses the color of the theme
+	Color  color.Color
+	Marker MarkerStyle
+	Line   LineStyle
+	Hidden bool
+	// vertical offset for stacked tracks, added on linear axes and in decades (a factor of 10^Offset) on log axes
+	Offset float64
+}
+
+// moves the points by the offset of the style on the y axis
+func (s TrackStyle) applyOffset(points function.Points, mode ScaleMode) {
+	if s.Offset == 0 {
+		return
+	}
+	factor := math.Pow(10, s.Offset)
+	for _, p := range points {
+		if mode == ScaleLog {
+			p.Y *= factor
+			p.Error *= factor
+		} else {
+			p.Y += s.Offset
+		}
+	}
+}
+
+// ColorHex formats a color as #rrggbbaa
+func ColorHex(c color.Color) string {
+	r, g, b, a := rgba(c)
+	return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, uint8(math.Round(a*255)))
+}
+
+// ParseColorHex parses a color formatted as #rrggbb or #rrggbbaa
+func ParseColorHex(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, errors.New("colors must be formatted as #rrggbb or #rrggbbaa")
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"bytes"
+	"image/color"
+	"physicsGUI/pkg/function"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStyles(t *testing.T) {
+	for _, m := range MarkerStyles {
+		parsed, err := ParseMarkerStyle(strings.ToLower(m.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, m, parsed)
+	}
+	for _, l := range LineStyles {
+		parsed, err := ParseLineStyle(l.String())
+		assert.NoError(t, err)
+		assert.Equal(t, l, parsed)
+	}
+	_, err := ParseMarkerStyle("star")
+	assert.Error(t, err)
+	_, err = ParseLineStyle("wavy")
+	assert.Error(t, err)
+
+	c, err := ParseColorHex(ColorHex(color.NRGBA{R: 18, G: 52, B: 86, A: 128}))
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 18, G: 52, B: 86, A: 128}, c)
+	c, err = ParseColorHex("#ff0000")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, c)
+	_, err = ParseColorHex("#ff00")
+	assert.Error(t, err)
+	_, err = ParseColorHex("#gg0000")
+	assert.Error(t, err)
+}
+
+func TestTrackOffset(t *testing.T) {
+	points := function.Points{{X: 1, Y: 2, Error: 0.5}}
+	TrackStyle{Offset: 1}.applyOffset(points, ScaleLinear)
+	assert.Equal(t, 3.0, points[0].Y)
+	assert.Equal(t, 0.5, points[0].Error)
+
+	// decades on log axes
+	TrackStyle{Offset: -1}.applyOffset(points, ScaleLog)
+	assert.InDelta(t, 0.3, points[0].Y, 1e-12)
+	assert.InDelta(t, 0.05, points[0].Error, 1e-12)
+}
+
+func TestDashes(t *testing.T) {
+	line := PlotLine{X1: 0, Y1: 0, X2: 20, Y2: 0, Dash: []float32{6, 4}}
+	dashes := line.dashes()
+	assert.Len(t, dashes, 2)
+	assert.Equal(t, float32(0), dashes[0].X1)
+	assert.Equal(t, float32(6), dashes[0].X2)
+	assert.Equal(t, float32(10), dashes[1].X1)
+	assert.Equal(t, float32(16), dashes[1].X2)
+
+	// the pattern continues from the previous line
+	line.DashOffset = 8
+	dashes = line.dashes()
+	assert.Equal(t, float32(2), dashes[0].X1)
+	assert.Equal(t, float32(8), dashes[0].X2)
+
+	line.Dash = nil
+	assert.Equal(t, []PlotLine{line}, line.dashes())
+}
+
+func TestTrackStyles(t *testing.T) {
+	g := newTestGraph(t)
+	g.Config.FunctionStyles = []TrackStyle{{Name: "Model"}}
+	dataTrack := function.NewFunction(function.Points{{X: 2, Y: 4, Error: 1}, {X: 3, Y: 5, Error: 1}})
+	g.AddDataTrack(dataTrack)
+	g.AddStyledDataTrack(function.NewFunction(function.Points{{X: 2, Y: 1}}), TrackStyle{Name: "sample.dat"})
+	assert.Equal(t, "Data 1", g.DataTrackStyle(0).Name)
+	assert.Equal(t, ScreenTheme.dataTrackColor(1), g.DataTrackColor(1))
+
+	texts := graphTexts(g)
+	assert.Contains(t, texts, "Model")
+	assert.Contains(t, texts, "Data 1")
+	assert.Contains(t, texts, "sample.dat")
+
+	changed := 0
+	g.OnStylesChanged = func() { changed++ }
+	red := color.NRGBA{R: 255, A: 255}
+	g.SetDataTrackStyle(1, TrackStyle{Name: "sample.dat", Color: red, Marker: MarkerSquare, Hidden: true})
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, red, g.DataTrackColor(1))
+	assert.NotContains(t, graphTexts(g), "sample.dat")
+
+	// squares and dashed lines in the data color
+	g.SetDataTrackStyle(1, TrackStyle{Name: "sample.dat", Color: red, Marker: MarkerSquare, Line: LineDashed})
+	squares := 0
+	for _, o := range test.WidgetRenderer(g).Objects() {
+		if rect, ok := o.(*canvas.Rectangle); ok && rect.StrokeColor == red {
+			squares++
+		}
+	}
+	// the point and the sample in the legend
+	assert.Equal(t, 2, squares)
+
+	// the offset shifts the drawn points but not the data
+	g.SetDataTrackStyle(0, TrackStyle{Name: "Data 1", Offset: 100})
+	assert.Equal(t, 4.0, dataTrack.GetData()[0].Y)
+	assert.Greater(t, g.navigation().y.max, 100.0)
+
+	g.RemoveDataTrack(dataTrack)
+	assert.Equal(t, "sample.dat", g.DataTrackStyle(0).Name)
+	assert.NotContains(t, graphTexts(g), "Data 1")
+}
+
+func TestDashedExport(t *testing.T) {
+	test.NewApp()
+	g := NewGraphCanvas(&GraphConfig{
+		Functions:      function.Functions{function.NewFunction(function.Points{{X: 1, Y: 1}, {X: 2, Y: 2}})},
+		FunctionStyles: []TrackStyle{{Name: "Model", Line: LineDashed}},
+	})
+	plot := g.Plot(fyne.NewSize(600, 400), PrintTheme)
+
+	svg := &bytes.Buffer{}
+	assert.NoError(t, plot.WriteSVG(svg))
+	assert.Contains(t, svg.String(), `stroke-dasharray="6 4"`)
+	assert.Contains(t, svg.String(), ">Model</text>")
+
+	pdf := &bytes.Buffer{}
+	assert.NoError(t, plot.WritePDF(pdf))
+	assert.Contains(t, pdf.String(), "[4.50 3.00] 0.00 d")
+}
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"image/color"
+
+	"golang.org/x/image/colornames"
+)
+
+// Theme holds the colors of a graph
+type Theme struct {
+	Background color.Color
+	Title      color.Color
+	Axes       color.Color
+	// labels at the axes and other texts
+	Labels color.Color
+	// default color of the functions
+	Function   color.Color
+	DataTracks []color.Color
+	Error      color.Color
+	Grid       color.Color
+	// minor grid lines between the decades of log axes
+	GridMinor color.Color
+	// bands around y = 0, nested bands add up
+	Band color.Color
+	// crosshair and zoom box
+	Cursor            color.Color
+	ReadoutBackground color.Color
+}
+
+// ScreenTheme is the dark theme of the graphs in the GUI
+var ScreenTheme = Theme{
+	Background:        color.Black,
+	Title:             color.White,
+	Axes:              color.White,
+	Labels:            color.White,
+	Function:          &color.NRGBA{R: 0, G: 255, B: 0, A: 255},
+	DataTracks:        DataTrackColors,
+	Error:             &color.NRGBA{R: 255, G: 0, B: 0, A: 128},
+	Grid:              &color.NRGBA{R: 128, G: 128, B: 128, A: 64},
+	GridMinor:         &color.NRGBA{R: 128, G: 128, B: 128, A: 48},
+	Band:              &color.NRGBA{R: 255, G: 255, B: 0, A: 28},
+	Cursor:            &color.NRGBA{R: 255, G: 255, B: 255, A: 128},
+	ReadoutBackground: &color.NRGBA{R: 0, G: 0, B: 0, A: 192},
+}
+
+// PrintTheme is the white theme of exported graphs
+var PrintTheme = Theme{
+	Background: color.White,
+	Title:      color.Black,
+	Axes:       color.Black,
+	Labels:     color.Black,
+	Function:   &color.NRGBA{R: 0, G: 128, B: 0, A: 255},
+	DataTracks: []color.Color{
+		colornames.Black,
+		colornames.Purple,
+		colornames.Blue,
+		colornames.Brown,
+	},
+	Error:             &color.NRGBA{R: 200, G: 0, B: 0, A: 160},
+	Grid:              &color.NRGBA{R: 128, G: 128, B: 128, A: 96},
+	GridMinor:         &color.NRGBA{R: 128, G: 128, B: 128, A: 48},
+	Band:              &color.NRGBA{R: 255, G: 200, B: 0, A: 48},
+	Cursor:            &color.NRGBA{R: 0, G: 0, B: 0, A: 128},
+	ReadoutBackground: &color.NRGBA{R: 255, G: 255, B: 255, A: 192},
+}
+
+// color of a data track by its index
+func (t Theme) dataTrackColor(i int) color.Color {
+	return t.DataTracks[i%len(t.DataTracks)]
+}
@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"image/color"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// window showing the graph, nil if it is not shown
+func (g *GraphCanvas) window() fyne.Window {
+	c := fyne.CurrentApp().Driver().CanvasForObject(g)
+	for _, w := range fyne.CurrentApp().Driver().AllWindows() {
+		if c != nil && w.Canvas() == c {
+			return w
+		}
+	}
+	return nil
+}
+
+// ShowTrackEditor opens a dialog to rename, style, hide, offset and remove the data tracks
+func (g *GraphCanvas) ShowTrackEditor() {
+	parent := g.window()
+	if parent == nil {
+		return
+	}
+
+	rows := container.NewVBox()
+	var update func()
+	update = func() {
+		rows.RemoveAll()
+		rows.Add(container.NewGridWithColumns(7,
+			widget.NewLabel("Name"), widget.NewLabel("Color"), widget.NewLabel("Marker"), widget.NewLabel("Line"),
+			widget.NewLabel("Visible"), widget.NewLabel("Offset"), widget.NewLabel("")))
+		if len(g.loadedData) == 0 {
+			rows.Add(widget.NewLabel("No data tracks loaded"))
+		}
+		for i := range g.loadedData {
+			rows.Add(g.trackEditorRow(i, parent, update))
+		}
+	}
+	update()
+
+	d := dialog.NewCustom("Data Tracks", "Close", container.NewVScroll(rows), parent)
+	d.Resize(fyne.NewSize(900, 400))
+	d.Show()
+}
+
+// widgets changing the style of the data track with the index, removing the track calls update
+func (g *GraphCanvas) trackEditorRow(i int, parent fyne.Window, update func()) fyne.CanvasObject {
+	dataTrack := g.loadedData[i]
+	change := func(set func(style *TrackStyle)) {
+		style := g.DataTrackStyle(i)
+		set(&style)
+		g.SetDataTrackStyle(i, style)
+	}
+
+	name := widget.NewEntry()
+	name.SetText(g.dataStyles[i].Name)
+	name.OnChanged = func(s string) { change(func(style *TrackStyle) { style.Name = s }) }
+
+	swatch := canvas.NewRectangle(g.DataTrackColor(i))
+	swatch.SetMinSize(fyne.NewSize(20, 20))
+	pick := widget.NewButton("Change", func() {
+		picker := dialog.NewColorPicker("Color", g.dataStyles[i].Name, func(c color.Color) {
+			change(func(style *TrackStyle) { style.Color = c })
+			swatch.FillColor = c
+			swatch.Refresh()
+		}, parent)
+		picker.Advanced = true
+		picker.SetColor(g.DataTrackColor(i))
+		picker.Show()
+	})
+
+	markerNames := make([]string, len(MarkerStyles))
+	for j, m := range MarkerStyles {
+		markerNames[j] = m.String()
+	}
+	marker := widget.NewSelect(markerNames, func(s string) {
+		m, _ := ParseMarkerStyle(s)
+		change(func(style *TrackStyle) { style.Marker = m })
+	})
+	marker.SetSelected(g.dataStyles[i].Marker.String())
+
+	lineNames := make([]string, len(LineStyles))
+	for j, l := range LineStyles {
+		lineNames[j] = l.String()
+	}
+	line := widget.NewSelect(lineNames, func(s string) {
+		l, _ := ParseLineStyle(s)
+		change(func(style *TrackStyle) { style.Line = l })
+	})
+	line.SetSelected(g.dataStyles[i].Line.String())
+
+	visible := widget.NewCheck("", func(b bool) { change(func(style *TrackStyle) { style.Hidden = !b }) })
+	visible.SetChecked(!g.dataStyles[i].Hidden)
+
+	// decades on log axes, invalid values are ignored while typing
+	offset := widget.NewEntry()
+	offset.SetText(strconv.FormatFloat(g.dataStyles[i].Offset, 'g', -1, 64))
+	offset.Validator = func(s string) error {
+		_, err := strconv.ParseFloat(s, 64)
+		return err
+	}
+	offset.OnChanged = func(s string) {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			change(func(style *TrackStyle) { style.Offset = v })
+		}
+	}
+
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		g.RemoveDataTrack(dataTrack)
+		update()
+	})
+
+	return container.NewGridWithColumns(7, name, container.NewBorder(nil, nil, swatch, nil, pick), marker, line, visible, offset, remove)
+}
@@ -11,14 +11,16 @@ import (
 )
 
 func loadFileChooser() {
-	// select file
-	fileDialog := dialog.NewFileOpen(
-		fileLoader, MainWindow)
+	// select file, the configuration is loaded into the selected sample
+	s := currentSample()
+	fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		fileLoader(s, reader, err)
+	}, MainWindow)
 
 	fileDialog.Show()
 }
 
-func fileLoader(reader fyne.URIReadCloser, err error) {
+func fileLoader(s *sample, reader fyne.URIReadCloser, err error) {
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
 		return
@@ -51,14 +53,14 @@ func fileLoader(reader fyne.URIReadCloser, err error) {
 		dialog.ShowError(decodeErr, MainWindow)
 		return
 	}
-	err = LoadConfig(config, false)
+	err = s.LoadConfig(config, false)
 	if err != nil {
 		if errors.Is(err, differentParameterVersionError) || errors.Is(err, differentPlotVersionError) {
 			callBack := func(b bool) {
 				if !b {
 					return // abort after version mismatch
 				} else {
-					_ = LoadConfig(config, true) // perform force load and ignore all errors
+					_ = s.LoadConfig(config, true) // perform force load and ignore all errors
 					return
 				}
 			}
@@ -71,12 +73,15 @@ func fileLoader(reader fyne.URIReadCloser, err error) {
 }
 
 func saveFileChooser() {
-	// select file
-	fileDialog := dialog.NewFileSave(fileSaver, MainWindow)
+	// select file, the selected sample is saved
+	s := currentSample()
+	fileDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		fileSaver(s, writer, err)
+	}, MainWindow)
 	fileDialog.Show()
 }
 
-func fileSaver(writer fyne.URIWriteCloser, err error) {
+func fileSaver(s *sample, writer fyne.URIWriteCloser, err error) {
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
 		return
@@ -86,7 +91,7 @@ func fileSaver(writer fyne.URIWriteCloser, err error) {
 	}
 
 	// setup config
-	config, err := CreateConfig()
+	config, err := s.CreateConfig()
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
 		return
@@ -115,12 +120,15 @@ func fileSaver(writer fyne.URIWriteCloser, err error) {
 }
 
 func exportFileChooser() {
-	// select file
-	fileDialog := dialog.NewFileSave(fileExporter, MainWindow)
+	// select file, the graphs of the selected sample are exported
+	s := currentSample()
+	fileDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		fileExporter(s, writer, err)
+	}, MainWindow)
 	fileDialog.Show()
 }
 
-func fileExporter(writer fyne.URIWriteCloser, err error) {
+func fileExporter(s *sample, writer fyne.URIWriteCloser, err error) {
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
 	}
@@ -128,7 +136,7 @@ func fileExporter(writer fyne.URIWriteCloser, err error) {
 		return // user abort
 	}
 
-	exportInfo := CreateExport()
+	exportInfo := s.CreateExport()
 
 	var data []byte
 	var eError error
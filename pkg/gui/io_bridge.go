@@ -6,28 +6,33 @@ import (
 	"fmt"
 	"maps"
 	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
 	"physicsGUI/pkg/gui/param"
 	"physicsGUI/pkg/io"
 	"reflect"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 )
 
 var differentParameterVersionError = errors.New("different parameter version")
 var differentPlotVersionError = errors.New("different plot version")
 
+// graphs calculated from the other graphs, they have no stored data tracks and don't change the plot version
+var derivedGraphs = []string{"residuals", "patterson"}
+
 func makeVersionCheckSum(blocks []string) []byte {
 	hasher := md5.New()
 	hasher.Write([]byte(strings.Join(blocks, string(byte(0)))))
 	return hasher.Sum(nil)
 }
 
-func getProgramParameterKeys() []string {
+func (s *sample) getProgramParameterKeys() []string {
 	// get all group names
-	groupFloatKeys := param.GetFloatKeys()
-	groupIntKeys := param.GetIntKeys()
-	groupStringKeys := param.GetStringKeys()
+	groupFloatKeys := s.params.GetFloatKeys()
+	groupIntKeys := s.params.GetIntKeys()
+	groupStringKeys := s.params.GetStringKeys()
 	// Sort names to prevent change in version number caused by different adding order
 	sort.Strings(groupFloatKeys)
 	sort.Strings(groupIntKeys)
@@ -36,7 +41,7 @@ func getProgramParameterKeys() []string {
 	var keys []string = nil
 	// Add float Parameters
 	for i := range groupFloatKeys {
-		fParamKeys := param.GetFloatGroup(groupFloatKeys[i]).GetKeys()
+		fParamKeys := s.params.GetFloatGroup(groupFloatKeys[i]).GetKeys()
 		// Sort names to prevent change in version number caused by different adding order
 		sort.Strings(fParamKeys)
 		for j := range fParamKeys {
@@ -45,7 +50,7 @@ func getProgramParameterKeys() []string {
 	}
 	// Add int Parameters
 	for i := range groupIntKeys {
-		iParamKeys := param.GetIntGroup(groupIntKeys[i]).GetKeys()
+		iParamKeys := s.params.GetIntGroup(groupIntKeys[i]).GetKeys()
 		// Sort names to prevent change in version number caused by different adding order
 		sort.Strings(iParamKeys)
 		for j := range iParamKeys {
@@ -55,7 +60,7 @@ func getProgramParameterKeys() []string {
 
 	// Add string Parameters
 	for i := range groupStringKeys {
-		sParamKeys := param.GetStringGroup(groupStringKeys[i]).GetKeys()
+		sParamKeys := s.params.GetStringGroup(groupStringKeys[i]).GetKeys()
 		// Sort names to prevent change in version number caused by different adding order
 		sort.Strings(sParamKeys)
 		for j := range sParamKeys {
@@ -65,31 +70,38 @@ func getProgramParameterKeys() []string {
 	return keys
 }
 
-func getProgramPlotKeys() []string {
-	keys := slices.Collect(maps.Keys(graphMap))
+func (s *sample) getProgramPlotKeys() []string {
+	keys := slices.Collect(maps.Keys(s.graphMap))
+	keys = slices.DeleteFunc(keys, func(key string) bool { return slices.Contains(derivedGraphs, key) })
 	sort.Strings(keys)
 	return keys
 }
 
-func LoadConfig(config *io.ConfigInformation, forceLoad bool) error {
+func (s *sample) LoadConfig(config *io.ConfigInformation, forceLoad bool) error {
 	// check parameter version indicator skipped in force Load
-	if !forceLoad && !slices.Equal(makeVersionCheckSum(getProgramParameterKeys()), config.ParameterVersionIndicator) {
+	if !forceLoad && !slices.Equal(makeVersionCheckSum(s.getProgramParameterKeys()), config.ParameterVersionIndicator) {
 		return differentParameterVersionError
 	}
 
 	// load Parameter information
-	err := loadParameterInformation(config.Parameter)
+	err := s.loadParameterInformation(config.Parameter)
+	if err != nil {
+		return err
+	}
+
+	// load constraints after the parameters they reference
+	err = s.params.SetConstraints(config.Constraints)
 	if err != nil {
 		return err
 	}
 
 	// check plot version indicator skipped in force Load
-	if !forceLoad && !slices.Equal(makeVersionCheckSum(getProgramPlotKeys()), config.PlotVersionIndicator) {
+	if !forceLoad && !slices.Equal(makeVersionCheckSum(s.getProgramPlotKeys()), config.PlotVersionIndicator) {
 		return differentPlotVersionError
 	}
 
 	// load Plot information
-	err = loadPlotInformation(config.Plot)
+	err = s.loadPlotInformation(config.Plot)
 	if err != nil {
 		return err
 	}
@@ -97,11 +109,11 @@ func LoadConfig(config *io.ConfigInformation, forceLoad bool) error {
 	return nil
 }
 
-func loadParameterInformation(paramInfo []io.ParameterInformation) error {
+func (s *sample) loadParameterInformation(paramInfo []io.ParameterInformation) error {
 	// load parameters
 	for _, value := range paramInfo {
 		if strings.EqualFold(reflect.TypeOf(float64(0)).String(), value.FieldType) {
-			fpGroup := param.GetFloatGroup(value.Group)
+			fpGroup := s.params.GetFloatGroup(value.Group)
 			if fpGroup == nil {
 				fmt.Printf("Could not load %s no such parameter group (float64) in program -> Skipped", value.Group)
 				continue
@@ -120,6 +132,23 @@ func loadParameterInformation(paramInfo []io.ParameterInformation) error {
 				return err
 			}
 			fParam.SetCheck(value.UseInFit)
+			if value.FieldStep != "" {
+				stepV, err := param.StdFloatParser(value.FieldStep)
+				if err != nil {
+					return err
+				}
+				if stepP := fParam.GetRelative("step"); stepP != nil {
+					err = stepP.Set(stepV)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			if orderP := fParam.GetRelative("order"); orderP != nil {
+				if err := orderP.Set(float64(max(value.FitOrder, 0))); err != nil {
+					return err
+				}
+			}
 			if value.IsLimited {
 				minV, err := param.StdFloatParser(value.FieldMinimum)
 				if err != nil {
@@ -149,7 +178,7 @@ func loadParameterInformation(paramInfo []io.ParameterInformation) error {
 			}
 		} else if strings.EqualFold(reflect.TypeOf(int(0)).String(), value.FieldType) {
 			if val, err := param.StdIntParser(value.FieldValue); err == nil {
-				err = param.SetInt(value.Group, value.Name, val)
+				err = s.params.SetInt(value.Group, value.Name, val)
 				if err != nil {
 					if errors.Is(err, param.ErrParameterNotFound) {
 						fmt.Printf("Could not load %s no such parameter(int) in program -> Skipped", value.Group+"/"+value.Name)
@@ -163,7 +192,7 @@ func loadParameterInformation(paramInfo []io.ParameterInformation) error {
 			}
 		} else if strings.EqualFold(reflect.TypeOf(string("")).String(), value.FieldType) {
 			if val, err := param.StdStringParser(value.FieldValue); err == nil {
-				err = param.SetString(value.Group, value.Name, val)
+				err = s.params.SetString(value.Group, value.Name, val)
 				if err != nil {
 					if errors.Is(err, param.ErrParameterNotFound) {
 						fmt.Printf("Could not load %s no such parameter(string) in program -> Skipped", value.Group+"/"+value.Name)
@@ -183,9 +212,9 @@ func loadParameterInformation(paramInfo []io.ParameterInformation) error {
 	return nil
 }
 
-func loadPlotInformation(paramInfo []io.PlotInformation) error {
+func (s *sample) loadPlotInformation(paramInfo []io.PlotInformation) error {
 	for _, information := range paramInfo {
-		if _, ok := graphMap[information.Name]; !ok {
+		if _, ok := s.graphMap[information.Name]; !ok {
 			fmt.Printf("Could not load %s no such plot in program", information.Name)
 			continue
 		}
@@ -193,40 +222,116 @@ func loadPlotInformation(paramInfo []io.PlotInformation) error {
 			fcn := function.NewFunction(information.DataTracks[i].Points)
 			scopeCopy := information.DataTracks[i].Scope
 			fcn.Scope = &scopeCopy
-			graphMap[information.Name].AddDataTrack(fcn)
+			info, err := loadDatasetInformation(information.DataTracks[i].Dataset)
+			if err != nil {
+				return err
+			}
+			s.updateDataset(fcn, func(stored *datasetInfo) { *stored = info })
+			// project files without styles get the default style
+			if information.DataTracks[i].Style.Name == "" {
+				s.graphMap[information.Name].AddDataTrack(fcn)
+				continue
+			}
+			style, err := trackStyle(information.DataTracks[i].Style)
+			if err != nil {
+				return err
+			}
+			s.graphMap[information.Name].AddStyledDataTrack(fcn, style)
 		}
 	}
 	return nil
 }
 
-func CreateConfig() (*io.ConfigInformation, error) {
+// trackStyle converts the stored style of a data track
+func trackStyle(info io.TrackStyleInformation) (graph.TrackStyle, error) {
+	style := graph.TrackStyle{Name: info.Name, Hidden: info.Hidden, Offset: info.Offset}
+	var err error
+	if info.Color != "" {
+		if style.Color, err = graph.ParseColorHex(info.Color); err != nil {
+			return style, err
+		}
+	}
+	if style.Marker, err = graph.ParseMarkerStyle(info.Marker); err != nil {
+		return style, err
+	}
+	if style.Line, err = graph.ParseLineStyle(info.Line); err != nil {
+		return style, err
+	}
+	return style, nil
+}
+
+// styleInformation converts the style of a data track for storing, default values are left empty
+func styleInformation(style graph.TrackStyle) io.TrackStyleInformation {
+	info := io.TrackStyleInformation{Name: style.Name, Hidden: style.Hidden, Offset: style.Offset}
+	if style.Color != nil {
+		info.Color = graph.ColorHex(style.Color)
+	}
+	if style.Marker != graph.MarkerAuto {
+		info.Marker = style.Marker.String()
+	}
+	if style.Line != graph.LineAuto {
+		info.Line = style.Line.String()
+	}
+	return info
+}
+
+// datasetInformation converts the metadata of a data track for storing
+func datasetInformation(info datasetInfo) io.DatasetInformation {
+	stored := io.DatasetInformation{Source: info.Source, Notes: info.Notes, Excluded: info.Excluded}
+	if !info.Imported.IsZero() {
+		stored.Imported = info.Imported.Format(time.RFC3339)
+	}
+	return stored
+}
+
+// loadDatasetInformation converts the stored metadata of a data track
+func loadDatasetInformation(stored io.DatasetInformation) (datasetInfo, error) {
+	info := datasetInfo{Source: stored.Source, Notes: stored.Notes, Excluded: stored.Excluded}
+	if stored.Imported != "" {
+		imported, err := time.Parse(time.RFC3339, stored.Imported)
+		if err != nil {
+			return info, fmt.Errorf("import time of %s: %w", stored.Source, err)
+		}
+		info.Imported = imported
+	}
+	return info, nil
+}
+
+func (s *sample) CreateConfig() (*io.ConfigInformation, error) {
 
 	// create ParameterInformation
-	parameters, err := createParameterInformation()
+	parameters, err := s.createParameterInformation()
 	if err != nil {
 		return nil, err
 	}
 
 	// create PlotInformation
-	plot, err := createPlotInformation()
+	plot, err := s.createPlotInformation()
 	if err != nil {
 		return nil, err
 	}
 
+	// create constraint information
+	constraints := make([]string, 0)
+	for _, c := range s.params.GetConstraints() {
+		constraints = append(constraints, c.String())
+	}
+
 	return &io.ConfigInformation{
-		PlotVersionIndicator:      makeVersionCheckSum(getProgramPlotKeys()),
+		PlotVersionIndicator:      makeVersionCheckSum(s.getProgramPlotKeys()),
 		Plot:                      plot,
-		ParameterVersionIndicator: makeVersionCheckSum(getProgramParameterKeys()),
+		ParameterVersionIndicator: makeVersionCheckSum(s.getProgramParameterKeys()),
 		Parameter:                 parameters,
+		Constraints:               constraints,
 	}, nil
 }
 
-func createParameterInformation() ([]io.ParameterInformation, error) {
+func (s *sample) createParameterInformation() ([]io.ParameterInformation, error) {
 	parameters := make([]io.ParameterInformation, 0)
 	// all float parameters
-	for _, g := range param.GetFloatKeys() {
-		for _, n := range param.GetFloatGroup(g).GetKeys() {
-			gParam := param.GetFloatGroup(g).GetParam(n)
+	for _, g := range s.params.GetFloatKeys() {
+		for _, n := range s.params.GetFloatGroup(g).GetKeys() {
+			gParam := s.params.GetFloatGroup(g).GetParam(n)
 			value, gError := gParam.Get()
 			if gError != nil {
 				return nil, gError
@@ -248,6 +353,22 @@ func createParameterInformation() ([]io.ParameterInformation, error) {
 				minS = param.StdFloatFormater(minV)
 				maxS = param.StdFloatFormater(maxV)
 			}
+			stepS := ""
+			if stepP := gParam.GetRelative("step"); stepP != nil {
+				stepV, err := stepP.Get()
+				if err != nil {
+					return nil, err
+				}
+				stepS = param.StdFloatFormater(stepV)
+			}
+			order := 0
+			if orderP := gParam.GetRelative("order"); orderP != nil {
+				orderV, err := orderP.Get()
+				if err != nil {
+					return nil, err
+				}
+				order = int(orderV)
+			}
 
 			parameters = append(parameters, io.ParameterInformation{
 				Group:        g,
@@ -258,13 +379,15 @@ func createParameterInformation() ([]io.ParameterInformation, error) {
 				IsLimited:    limited,
 				FieldMinimum: minS,
 				FieldMaximum: maxS,
+				FieldStep:    stepS,
+				FitOrder:     order,
 			})
 		}
 	}
 
-	for _, g := range param.GetStringKeys() {
-		for _, n := range param.GetStringGroup(g).GetKeys() {
-			gParam := param.GetStringGroup(g).GetParam(n)
+	for _, g := range s.params.GetStringKeys() {
+		for _, n := range s.params.GetStringGroup(g).GetKeys() {
+			gParam := s.params.GetStringGroup(g).GetParam(n)
 			value, gError := gParam.Get()
 			if gError != nil {
 				return nil, gError
@@ -300,9 +423,9 @@ func createParameterInformation() ([]io.ParameterInformation, error) {
 		}
 	}
 
-	for _, g := range param.GetIntKeys() {
-		for _, n := range param.GetIntGroup(g).GetKeys() {
-			gParam := param.GetIntGroup(g).GetParam(n)
+	for _, g := range s.params.GetIntKeys() {
+		for _, n := range s.params.GetIntGroup(g).GetKeys() {
+			gParam := s.params.GetIntGroup(g).GetParam(n)
 			value, gError := gParam.Get()
 			if gError != nil {
 				return nil, gError
@@ -341,18 +464,24 @@ func createParameterInformation() ([]io.ParameterInformation, error) {
 	return parameters, nil
 }
 
-func createPlotInformation() ([]io.PlotInformation, error) {
-	plotInfos := make([]io.PlotInformation, 0, len(graphMap))
+func (s *sample) createPlotInformation() ([]io.PlotInformation, error) {
+	plotInfos := make([]io.PlotInformation, 0, len(s.graphMap))
 
-	for key, plot := range graphMap {
+	for key, plot := range s.graphMap {
 		dataTracks := plot.GetDataTracks()
 		funcInfos := make([]io.FunctionInformation, 0, len(dataTracks))
 		for i := 0; i < len(dataTracks); i++ {
-			scopeCopy := *dataTracks[i].Scope // this should copy the struct
+			// empty functions have no scope
+			scopeCopy := function.Scope{}
+			if dataTracks[i].Scope != nil {
+				scopeCopy = *dataTracks[i].Scope // this should copy the struct
+			}
 
 			funcInfo := io.FunctionInformation{
-				Points: dataTracks[i].GetData(),
-				Scope:  scopeCopy,
+				Points:  dataTracks[i].GetData(),
+				Scope:   scopeCopy,
+				Style:   styleInformation(plot.DataTrackStyle(i)),
+				Dataset: datasetInformation(s.dataset(dataTracks[i])),
 			}
 			funcInfos = append(funcInfos, funcInfo)
 		}
@@ -366,10 +495,10 @@ func createPlotInformation() ([]io.PlotInformation, error) {
 	return plotInfos, nil
 }
 
-func CreateExport() []io.PointsExport {
-	res := make([]io.PointsExport, 0, len(graphMap))
+func (s *sample) CreateExport() []io.PointsExport {
+	res := make([]io.PointsExport, 0, len(s.graphMap))
 
-	for key, plot := range graphMap {
+	for key, plot := range s.graphMap {
 		points := make([]function.Points, 0, len(plot.GetDataTracks())+len(plot.Config.Functions))
 		for _, fcn := range plot.Config.Functions {
 			points = append(points, fcn.GetData())
@@ -0,0 +1,67 @@
+package gui
+
+import (
+	"image/color"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackStylePersistence(t *testing.T) {
+	TestSetup(t)
+	dataTrack := function.NewFunction(function.Points{{X: 0.02, Y: 0.5, Error: 0.1}, {X: 0.05, Y: 0.01, Error: 0.002}})
+	style := graph.TrackStyle{
+		Name:   "sample.dat",
+		Color:  color.NRGBA{R: 200, G: 100, B: 0, A: 255},
+		Marker: graph.MarkerTriangle,
+		Line:   graph.LineDotted,
+		Hidden: true,
+		Offset: 2,
+	}
+	testSample.graphMap["intensity"].AddStyledDataTrack(dataTrack, style)
+	defer testSample.graphMap["intensity"].RemoveDataTrack(dataTrack)
+
+	// the residuals follow the data track without the offset
+	styles := testSample.graphMap["residuals"].Config.FunctionStyles
+	residualStyle := styles[len(styles)-1]
+	assert.Equal(t, "sample.dat", residualStyle.Name)
+	assert.True(t, residualStyle.Hidden)
+	assert.Zero(t, residualStyle.Offset)
+
+	config, err := testSample.CreateConfig()
+	assert.NoError(t, err)
+	var stored *io.FunctionInformation
+	for _, p := range config.Plot {
+		if p.Name == "intensity" {
+			stored = &p.DataTracks[len(p.DataTracks)-1]
+		}
+	}
+	assert.NotNil(t, stored)
+	assert.Equal(t, io.TrackStyleInformation{Name: "sample.dat", Color: "#c86400ff", Marker: "Triangle", Line: "Dotted", Hidden: true, Offset: 2}, stored.Style)
+
+	loaded, err := trackStyle(stored.Style)
+	assert.NoError(t, err)
+	assert.Equal(t, style, loaded)
+
+	// defaults are left empty
+	assert.Equal(t, io.TrackStyleInformation{Name: "Data 1"}, styleInformation(graph.TrackStyle{Name: "Data 1"}))
+	_, err = trackStyle(io.TrackStyleInformation{Name: "x", Marker: "star"})
+	assert.Error(t, err)
+}
+
+func TestLoadConfigPlotVersion(t *testing.T) {
+	TestSetup(t)
+	config, err := testSample.CreateConfig()
+	assert.NoError(t, err)
+
+	// project files saved before the derived graphs were added
+	config.PlotVersionIndicator = makeVersionCheckSum([]string{"eden", "intensity"})
+	config.Plot = []io.PlotInformation{{Name: "eden"}, {Name: "intensity"}}
+	assert.NoError(t, testSample.LoadConfig(config, false))
+
+	config.PlotVersionIndicator = makeVersionCheckSum([]string{"intensity"})
+	assert.ErrorIs(t, testSample.LoadConfig(config, false), differentPlotVersionError)
+}
@@ -13,9 +13,8 @@ import (
 	"physicsGUI/pkg/gui/graph"
 	"physicsGUI/pkg/gui/helper"
 	"physicsGUI/pkg/gui/param"
-	"physicsGUI/pkg/minimizer"
 	"physicsGUI/pkg/physics"
-	"physicsGUI/pkg/trigger"
+	"physicsGUI/pkg/state"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -28,9 +27,6 @@ var (
 	// App reference
 	App        fyne.App
 	MainWindow fyne.Window
-
-	functionMap = make(map[string]*function.Function)
-	graphMap    = make(map[string]*graph.GraphCanvas)
 )
 
 // adaption should not be necessary here
@@ -45,9 +41,10 @@ func Start() {
 
 // adaption should not be necessary
 // onDrop is called when a file is dropped into the window
-// imports the data if a file is dropped on a graph canvas
+// imports the data if a file is dropped on a graph canvas of the selected sample
 func onDrop(position fyne.Position, uri []fyne.URI) {
-	for mapIdentifier, u := range graphMap {
+	s := currentSample()
+	for mapIdentifier, u := range s.graphMap {
 		if u.MouseInCanvas(position) {
 			for _, v := range uri {
 				rc, err := os.OpenFile(v.Path(), os.O_RDONLY, 0666)
@@ -57,9 +54,7 @@ func onDrop(position fyne.Position, uri []fyne.URI) {
 				}
 
 				if points := addDataset(rc, v, nil); points != nil {
-					newFunction := function.NewFunction(points)
-					graphMap[mapIdentifier].AddDataTrack(newFunction)
-					physics.AlterQZAxis(graphMap[mapIdentifier].GetDataTracks(), mapIdentifier)
+					s.addDataTrack(mapIdentifier, points, v.Path())
 				}
 			}
 			return
@@ -95,6 +90,9 @@ func addDataset(reader io.ReadCloser, uri fyne.URI, err error) function.Points {
 
 	// handle import
 	points, err := data.Parse(bytes)
+	if err == nil {
+		err = data.CheckErrors(points)
+	}
 	if err != nil {
 		dialog.ShowError(err, MainWindow)
 		return nil
@@ -118,52 +116,73 @@ func createFileMenu() *fyne.Menu {
 	mnLoad := fyne.NewMenuItem("Load", loadFileChooser)
 	mnSave := fyne.NewMenuItem("Save", saveFileChooser)
 	mnExport := fyne.NewMenuItem("Export", exportFileChooser)
-	return fyne.NewMenu("File", mnLoad, mnSave, mnExport)
+	mnFigure := fyne.NewMenuItem("Export Figure", figureDialog)
+	return fyne.NewMenu("File", mnLoad, mnSave, mnExport, mnFigure)
 }
 
 // adaption should not be necessary here
 // mainWindow builds and renders the main GUI content, it will show and run the main window
+// every sample is shown in its own tab, new samples are created with the button next to the tabs
 func mainWindow() {
-	registerFunctions()
+	samples = newSampleTabs()
+	if _, err := samples.add(); err != nil {
+		log.Fatal(err)
+	}
+
+	MainWindow.SetMainMenu(fyne.NewMainMenu(
+		fyne.NewMenu("Program", fyne.NewMenuItem("API Server", apiDialog)),
+		createFileMenu(),
+		createSampleMenu(),
+		createParameterMenu(),
+		createAnalysisMenu(),
+	))
+	MainWindow.Resize(fyne.NewSize(1000, 500))
+	MainWindow.SetContent(samples.Widget())
+	MainWindow.SetOnDropped(onDrop)
 
-	content := container.NewBorder(
+	MainWindow.ShowAndRun()
+}
+
+// adaption should not be necessary here
+// layout arranges the control panel, the graphs, the parameters and the status bar of a sample inside its tab
+func (s *sample) layout(graphs, grid fyne.CanvasObject) fyne.CanvasObject {
+	return container.NewBorder(
 		container.NewVBox(
 			container.NewHBox(
-				NewMinimizerControlPanel().Widget(),
+				s.control.Widget(),
 			),
 			helper.CreateSeparator(),
 		), // top
-		nil, // bottom
-		nil, // left
-		nil, // right
+		s.status.Widget(), // bottom
+		nil,               // left
+		nil,               // right
 
 		container.NewVSplit(
-			registerGraphs(),
-			registerParams(),
+			graphs,
+			s.parameterViews(grid),
 		),
 	)
+}
 
-	// set onchange function for recalculating data
-	trigger.SetOnChange(RecalculateData)
-
-	MainWindow.SetMainMenu(fyne.NewMainMenu(
-		fyne.NewMenu("Program"),
-		createFileMenu(),
-	))
-	MainWindow.Resize(fyne.NewSize(1000, 500))
-	MainWindow.SetContent(content)
-	MainWindow.SetOnDropped(onDrop)
-
-	MainWindow.ShowAndRun()
+// adaption should not be necessary here
+// parameterViews shows the registered parameters either as input grid or as spreadsheet like table,
+// next to them the loaded data tracks and the progress of the fits of the control panel
+func (s *sample) parameterViews(grid fyne.CanvasObject) *container.AppTabs {
+	return container.NewAppTabs(
+		container.NewTabItem("Parameters", grid),
+		container.NewTabItem("Table", s.table.Widget()),
+		container.NewTabItem("Data", s.data.Widget()),
+		container.NewTabItem("Fit Progress", s.control.ProgressWidget()),
+	)
 }
 
 //!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!! adapt everything from here !!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
 
-// this is also the place where you need to pass:
-// all current parameters and all experimental data tracks
-func (controlPanel *MinimizerControlPanel) minimizerProblemSetup() error {
-	// get parameters + experimental data and put them into minimize()
-	edens := param.GetFloatGroup("eden")
+// fitParameters returns the parameters passed to the penalty function
+// !the order of the parameters needs to fit the penalty function
+func (s *sample) fitParameters() param.Parameters[float64] {
+	// get parameters and add them to the session in this order
+	edens := s.params.GetFloatGroup("eden")
 
 	e1 := edens.GetParam("Eden a")
 	e2 := edens.GetParam("Eden 1")
@@ -171,39 +190,47 @@ func (controlPanel *MinimizerControlPanel) minimizerProblemSetup() error {
 	e4 := edens.GetParam("Eden b")
 
 	// get roughness parameters
-	roughness := param.GetFloatGroup("rough")
+	roughness := s.params.GetFloatGroup("rough")
 
 	r1 := roughness.GetParam("Roughness a/1")
 	r2 := roughness.GetParam("Roughness 1/2")
 	r3 := roughness.GetParam("Roughness 2/b")
 
 	// get thickness parameters
-	thickness := param.GetFloatGroup("thick")
+	thickness := s.params.GetFloatGroup("thick")
 
 	t1 := thickness.GetParam("Thickness 1")
 	t2 := thickness.GetParam("Thickness 2")
 
 	// get general parameters
-	general := param.GetFloatGroup("general")
+	general := s.params.GetFloatGroup("general")
 
 	delta := general.GetParam("deltaq")
 	background := general.GetParam("background")
 	scaling := general.GetParam("scaling")
 
-	if err := controlPanel.minimize(e1, e2, e3, e4, t1, t2, r1, r2, r3, delta, background, scaling); err != nil {
-		fmt.Println("Error while minimizing:", err)
-		return err
+	return param.Parameters[float64]{e1, e2, e3, e4, t1, t2, r1, r2, r3, delta, background, scaling}
+}
+
+// the penalty function defines the error we minimize with minuit, it is the objective of the session
+// this is also the place where you need to pass all current parameters and all experimental data tracks
+// !the order of the parameters needs to fit fitParameters
+func modelPenalty(params []float64, dataTracks []function.Points) (float64, error) {
+	return calculatePenalty(params, dataTracks, physics.QZAxisOf(dataTracks...), physics.Sim2SigRMSOn)
+}
+
+// modelObjective is the penalty of the model with another figure of merit, f.e. for the steps of a recipe
+func modelObjective(merit physics.Merit) state.Objective {
+	return func(params []float64, dataTracks []function.Points) (float64, error) {
+		return calculatePenalty(params, dataTracks, physics.QZAxisOf(dataTracks...), merit)
 	}
-	return nil
 }
 
-// the penalty function defines the error we minimize with minuit
-// !the order of the parameters needs to fit
-func penaltyFunction(fcn *minimizer.MinuitFunction, params []float64) float64 {
+// calculates the penalty of the parameters against the data tracks, the intensity is calculated on the qz axis
+func calculatePenalty(params []float64, dataTracks []function.Points, qz []float64, merit physics.Merit) (float64, error) {
 	paramCount := 12
 	if len(params) != paramCount {
-		dialog.ShowError(fmt.Errorf("penaltyFunction has %d parameters but expects %d", len(params), paramCount), MainWindow)
-		return math.MaxFloat64
+		return math.MaxFloat64, fmt.Errorf("penalty function has %d parameters but expects %d", len(params), paramCount)
 	}
 
 	//sort the parameters
@@ -220,57 +247,56 @@ func penaltyFunction(fcn *minimizer.MinuitFunction, params []float64) float64 {
 	edenPoints, err := physics.GetEdensities(edenErr, dErr, sigmaErr)
 	if err != nil {
 		fmt.Println("Error while calculating edensities:", err)
-		return math.MaxFloat64
+		return math.MaxFloat64, nil
 	}
 
 	//intensity calculation itself
-	intensityPoints := physics.CalculateIntensityPoints(edenPoints, deltaErr, &physics.IntensityOptions{
+	intensityPoints := physics.CalculateIntensityPointsOn(qz, edenPoints, deltaErr, &physics.IntensityOptions{
 		Background: backgroundErr,
 		Scaling:    scalingErr,
 	})
 
-	experimentalData := graphMap["intensity"].GetDataTracks()
-	dataTracks := make([]function.Points, len(experimentalData))
-	for i, dataTrack := range experimentalData {
-		dataTracks[i] = dataTrack.GetData()
-	}
-
 	//penalty calculation
-	diff, err := physics.Sim2SigRMS(dataTracks, intensityPoints)
-	if err != nil {
-		dialog.ShowError(err, MainWindow)
-	}
-
-	return diff
+	return merit(dataTracks, intensityPoints)
 }
 
 // register functions which can be used for graph plotting
 // this is the place to add function plots which are shown in graphs
-func registerFunctions() {
+func (s *sample) registerFunctions() {
 	//a function needs to be added to the functionMap using a unique identifier so we can further handle it
 	//interpolation mode can be ignored
-	functionMap["intensity"] = function.NewEmptyFunction()
-	functionMap["eden"] = function.NewEmptyFunction()
+	s.functionMap["intensity"] = function.NewEmptyFunction()
+	s.functionMap["eden"] = function.NewEmptyFunction()
+	s.functionMap["patterson"] = function.NewEmptyFunction()
 }
 
 // creates the graph containers for the different graphs
 // this is the place to add graphs to the GUI
-func registerGraphs() *fyne.Container {
+func (s *sample) registerGraphs() *fyne.Container {
 
 	//a graph needs to be added to the graphMap using a unique identifier so we can further handle it
-	graphMap["intensity"] = graph.NewGraphCanvas(&graph.GraphConfig{
+	s.graphMap["intensity"] = graph.NewGraphCanvas(&graph.GraphConfig{
 
 		//title shown inside the GUI
 		Title: "Intensity Graph",
 
-		//use logarithmic scaling (both x and y axis)
-		IsLog: true,
+		//use logarithmic scaling, every axis can be changed in the menu of the graph (right click)
+		XScale: graph.ScaleLog,
+		YScale: graph.ScaleLog,
+
+		//axis titles with units
+		XLabel: "qz [Å⁻¹]",
+		YLabel: "Intensity",
 
-		//use magic scaling: p.Y = math.Pow(p.X, 4) * p.Y;  p.Error = math.Pow(p.X, 4) * p.Error
-		AdaptDraw: true,
+		//quantities which can be shown instead of the intensity, R·q⁴ by default
+		Quantities: s.intensityQuantities(),
+		Quantity:   1,
 
 		//chose the function to show inside the graph by it's identifier
-		Functions: function.Functions{functionMap["intensity"]},
+		Functions: function.Functions{s.functionMap["intensity"]},
+
+		//the model is listed in the legend with the data tracks
+		FunctionStyles: []graph.TrackStyle{{Name: "Model"}},
 
 		//optionally set an x-range to plot, points outside it are ignored
 		DisplayRange: &graph.GraphRange{
@@ -279,39 +305,85 @@ func registerGraphs() *fyne.Container {
 		},
 	})
 
-	graphMap["eden"] = graph.NewGraphCanvas(&graph.GraphConfig{
+	// the qz axis of the intensity follows the loaded data tracks
+	s.graphMap["intensity"].OnDataTracksChanged = func() {
+		s.updateQZAxis()
+		s.syncDatasets()
+		if err := s.calculate(); err != nil {
+			log.Println("Error while recalculating data:", err)
+		}
+	}
+	s.graphMap["intensity"].OnStylesChanged = func() {
+		s.syncDatasets()
+		s.updateResidualStyles()
+		s.updatePattersonStyles()
+	}
+
+	s.graphMap["eden"] = graph.NewGraphCanvas(&graph.GraphConfig{
 		Title:     "Edensity Graph",
-		IsLog:     false,
-		AdaptDraw: false,
-		Functions: function.Functions{functionMap["eden"]},
+		XLabel:    "z [Å]",
+		YLabel:    "ρ [e/Å³]",
+		Functions: function.Functions{s.functionMap["eden"]},
+	})
+
+	// normalized residuals of the data tracks of the intensity graph, one function per data track
+	s.graphMap["residuals"] = graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:           "Residuals (data - model) / σ",
+		XScale:          graph.ScaleLog,
+		XLabel:          "qz [Å⁻¹]",
+		YLabel:          "(data - model) / σ",
+		Scatter:         true,
+		DataTrackColors: true,
+		Bands:           []float64{1, 2},
+		MinSize:         fyne.NewSize(500, 150),
+		DisplayRange: &graph.GraphRange{
+			Min: 0.01,
+			Max: math.MaxFloat64,
+		},
+	})
+
+	// autocorrelation of the gradient of the profile and its estimate from the data tracks, the peaks are at layer thicknesses
+	s.graphMap["patterson"] = graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:           "Patterson Function",
+		XLabel:          "z [Å]",
+		YLabel:          "P(z) [e²/Å⁷]",
+		Functions:       function.Functions{s.functionMap["patterson"]},
+		FunctionStyles:  []graph.TrackStyle{{Name: "Model"}},
+		DataTrackColors: true,
+		ModelFunctions:  1,
+		MinSize:         fyne.NewSize(500, 150),
 	})
 
 	//chose how you like to arrange the graphs insige the GUI
-	return container.NewGridWithColumns(2, graphMap["eden"], graphMap["intensity"])
+	intensity := container.NewVSplit(s.graphMap["intensity"], s.graphMap["residuals"])
+	intensity.Offset = 0.7
+	eden := container.NewVSplit(s.graphMap["eden"], s.graphMap["patterson"])
+	eden.Offset = 0.6
+	return container.NewGridWithColumns(2, eden, intensity)
 }
 
 // creates and registers the parameter and adds them to the parameter repository
 // this is the place to alter parameters:
-func registerParams() *fyne.Container {
+func (s *sample) registerParams() *fyne.Container {
 	//created with a group name, an individual name and a default value
 	//you can get parameters as a group or individually (combining group and individual name) later on
 	//this can be helpful to easily pass similar parameters to a function and iterate over them
-	edenA, _ := param.FloatMinMax("eden", "Eden a", 0.0)
-	eden1, _ := param.FloatMinMax("eden", "Eden 1", 0.346197)
-	eden2, _ := param.FloatMinMax("eden", "Eden 2", 0.458849)
-	edenB, _ := param.FloatMinMax("eden", "Eden b", 0.334000)
+	edenA, _ := s.params.FloatMinMax("eden", "Eden a", 0.0)
+	eden1, _ := s.params.FloatMinMax("eden", "Eden 1", 0.346197)
+	eden2, _ := s.params.FloatMinMax("eden", "Eden 2", 0.458849)
+	edenB, _ := s.params.FloatMinMax("eden", "Eden b", 0.334000)
 
-	roughnessA1, _ := param.FloatMinMax("rough", "Roughness a/1", 3.39544)
-	roughness12, _ := param.FloatMinMax("rough", "Roughness 1/2", 2.15980)
-	roughness2B, _ := param.FloatMinMax("rough", "Roughness 2/b", 3.90204)
+	roughnessA1, _ := s.params.FloatMinMax("rough", "Roughness a/1", 3.39544)
+	roughness12, _ := s.params.FloatMinMax("rough", "Roughness 1/2", 2.15980)
+	roughness2B, _ := s.params.FloatMinMax("rough", "Roughness 2/b", 3.90204)
 
-	thickness1, _ := param.FloatMinMax("thick", "Thickness 1", 14.2657)
-	thickness2, _ := param.FloatMinMax("thick", "Thickness 2", 10.6906)
+	thickness1, _ := s.params.FloatMinMax("thick", "Thickness 1", 14.2657)
+	thickness2, _ := s.params.FloatMinMax("thick", "Thickness 2", 10.6906)
 
 	//parameters can be created with (above) or without (below) two additional fields for minimum and maximum values
-	deltaQ, _ := param.Float("general", "deltaq", -0.000305927)
-	background, _ := param.Float("general", "background", 1.43793e-7)
-	scaling, _ := param.Float("general", "scaling", 0.888730)
+	deltaQ, _ := s.params.Float("general", "deltaq", -0.000305927)
+	background, _ := s.params.Float("general", "background", 1.43793e-7)
+	scaling, _ := s.params.Float("general", "scaling", 0.888730)
 
 	//you can chose how to arrange the parameters inside the GUI here
 	//by now it's 4x4 partitioning where some partitions are left empty
@@ -331,40 +403,40 @@ func registerParams() *fyne.Container {
 // Insert your adapted physical calculations and parameters here!
 // RecalculateData recalculates the data for the current graphs
 // current parameter values need to be fetched, the physical calculations done and resulting points set to the functions
-func RecalculateData() {
-	// Fetch all parameters here
+// errors are returned, the recalculation trigger shows them in the status bar
+func (s *sample) RecalculateData() error {
+	// update parameters derived by constraints before fetching them
+	if err := s.session.ApplyConstraints(); err != nil {
+		return fmt.Errorf("applying constraints: %w", err)
+	}
+
+	// Fetch all parameters of the session here
 	// Get current parameters by group identifier
-	eden, err := param.GetFloats("eden")
+	eden, err := s.session.Floats("eden")
 	if err != nil {
-		log.Println("Error while getting eden parameters:", err)
-		return
+		return fmt.Errorf("getting eden parameters: %w", err)
 	}
-	d, err := param.GetFloats("thick")
+	d, err := s.session.Floats("thick")
 	if err != nil {
-		log.Println("Error while getting thickness parameters:", err)
-		return
+		return fmt.Errorf("getting thickness parameters: %w", err)
 	}
-	sigma, err := param.GetFloats("rough")
+	sigma, err := s.session.Floats("rough")
 	if err != nil {
-		log.Println("Error while getting roughness parameters:", err)
-		return
+		return fmt.Errorf("getting roughness parameters: %w", err)
 	}
 
 	// get general parameters individually
-	delta, err := param.GetFloat("general", "deltaq")
+	delta, err := s.session.Float("general", "deltaq")
 	if err != nil {
-		log.Println("Error while getting deltaq parameter:", err)
-		return
+		return fmt.Errorf("getting deltaq parameter: %w", err)
 	}
-	background, err := param.GetFloat("general", "background")
+	background, err := s.session.Float("general", "background")
 	if err != nil {
-		log.Println("Error while getting background parameter:", err)
-		return
+		return fmt.Errorf("getting background parameter: %w", err)
 	}
-	scaling, err := param.GetFloat("general", "scaling")
+	scaling, err := s.session.Float("general", "scaling")
 	if err != nil {
-		log.Println("Error while getting scaling parameter:", err)
-		return
+		return fmt.Errorf("getting scaling parameter: %w", err)
 	}
 
 	// calculate all functions which need to be updated here
@@ -373,18 +445,21 @@ func RecalculateData() {
 	edenPoints, err := physics.GetEdensities(eden, d, sigma)
 	//only potential error handling
 	if err != nil {
-		log.Println("Error while calculating edensities:", err)
-		return
+		return fmt.Errorf("calculating edensities: %w", err)
 	} else {
 		//set points to function which is automatically shown inside the graph
-		functionMap["eden"].SetData(edenPoints)
+		s.functionMap["eden"].SetData(edenPoints)
 	}
 
 	// calculate intensities
-	intensityPoints := physics.CalculateIntensityPoints(edenPoints, delta, &physics.IntensityOptions{
+	intensityPoints := physics.CalculateIntensityPointsOn(s.qzAxis(), edenPoints, delta, &physics.IntensityOptions{
 		Background: background,
 		Scaling:    scaling,
 	})
 	//set points to function which is automatically shown inside the graph
-	functionMap["intensity"].SetData(intensityPoints)
+	s.functionMap["intensity"].SetData(intensityPoints)
+
+	s.updateResiduals(intensityPoints)
+	s.updatePatterson(edenPoints, delta)
+	return nil
 }
@@ -0,0 +1,266 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/minimizer"
+	"strconv"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// maximum number of points per walker shown in the chain graphs
+	mcmcChainPoints = 200
+	// maximum number of samples shown in a scatter plot of the corner plot
+	mcmcScatterPoints = 2000
+	mcmcHistogramBins = 30
+	// steps sampled between progress updates
+	mcmcProgressSteps = 10
+)
+
+// mcmcDialog asks for the sampler settings and samples the posterior of the checked parameters of the selected sample
+func mcmcDialog() {
+	s := currentSample()
+	walkers := widget.NewEntry()
+	walkers.SetText("0")
+	steps := widget.NewEntry()
+	steps.SetText("2000")
+	burnIn := widget.NewEntry()
+	burnIn.SetText("500")
+	scale := widget.NewEntry()
+	scale.SetText("0.5")
+	seed := widget.NewEntry()
+	seed.SetPlaceHolder("random")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Walkers", walkers),
+		widget.NewFormItem("Steps", steps),
+		widget.NewFormItem("Burn-in", burnIn),
+		widget.NewFormItem("Likelihood scale", scale),
+		widget.NewFormItem("Seed", seed),
+	}
+	items[0].HintText = "0 uses 4 walkers per free parameter"
+	items[2].HintText = "steps dropped from the results"
+	items[3].HintText = "log-likelihood = -scale * penalty, 0.5 for a χ² merit"
+
+	dialog.ShowForm("MCMC Uncertainties", "Sample", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		config := minimizer.MCMCConfig{Seed: rand.Uint64()}
+		var err error
+		var nSteps, nBurnIn int
+		if config.Walkers, err = strconv.Atoi(walkers.Text); err != nil {
+			dialog.ShowError(fmt.Errorf("walkers: %w", err), MainWindow)
+			return
+		}
+		if nSteps, err = strconv.Atoi(steps.Text); err != nil || nSteps <= 0 {
+			dialog.ShowError(errors.New("steps must be a positive number"), MainWindow)
+			return
+		}
+		if nBurnIn, err = strconv.Atoi(burnIn.Text); err != nil || nBurnIn < 0 || nBurnIn >= nSteps {
+			dialog.ShowError(errors.New("burn-in must be a positive number smaller than the steps"), MainWindow)
+			return
+		}
+		if config.LikelihoodScale, err = param.StdFloatParser(scale.Text); err != nil || config.LikelihoodScale <= 0 {
+			dialog.ShowError(errors.New("likelihood scale must be a positive number"), MainWindow)
+			return
+		}
+		if seed.Text != "" {
+			if config.Seed, err = strconv.ParseUint(seed.Text, 10, 64); err != nil {
+				dialog.ShowError(fmt.Errorf("seed: %w", err), MainWindow)
+				return
+			}
+		}
+
+		runMCMC(s, config, nSteps, nBurnIn)
+	}, MainWindow)
+}
+
+// runMCMC samples the checked parameters in the background and shows the results afterwards
+func runMCMC(s *sample, config minimizer.MCMCConfig, steps, burnIn int) {
+	problem, err := s.session.FitProblem()
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+	sampler, err := minimizer.NewSampler(problem, config)
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+
+	names := s.session.Names()
+
+	var cancelled atomic.Bool
+	progress := widget.NewProgressBar()
+	btnCancel := widget.NewButton("Cancel", func() {
+		cancelled.Store(true)
+	})
+	d := dialog.NewCustomWithoutButtons("Sampling", container.NewVBox(progress, btnCancel), MainWindow)
+	d.Resize(fyne.NewSize(400, 100))
+	d.Show()
+
+	go func() {
+		for sampler.Steps() < steps && !cancelled.Load() {
+			sampler.Run(min(mcmcProgressSteps, steps-sampler.Steps()))
+			progress.SetValue(float64(sampler.Steps()) / float64(steps))
+		}
+		d.Hide()
+
+		if sampler.Steps() <= burnIn {
+			dialog.ShowError(errors.New("sampling cancelled before the burn-in was completed"), MainWindow)
+			return
+		}
+		showMCMCResults(sampler, names, burnIn)
+	}()
+}
+
+// showMCMCResults opens a window with the credible intervals, chains, marginal histograms and the corner plot
+func showMCMCResults(sampler *minimizer.Sampler, names []string, burnIn int) {
+	w := fyne.CurrentApp().NewWindow("MCMC Results")
+
+	btnExport := widget.NewButton("Export Chains (CSV)", func() {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if writer == nil {
+				return // user abort
+			}
+			defer writer.Close()
+			if err := sampler.WriteCSV(writer, names); err != nil {
+				dialog.ShowError(err, w)
+			}
+		}, w)
+	})
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Summary", mcmcSummary(sampler, names, burnIn)),
+		container.NewTabItem("Chains", container.NewVScroll(mcmcChains(sampler, names))),
+		container.NewTabItem("Histograms", container.NewVScroll(mcmcHistograms(sampler, names, burnIn))),
+		container.NewTabItem("Corner Plot", container.NewScroll(mcmcCorner(sampler, names, burnIn))),
+	)
+
+	w.SetContent(container.NewBorder(nil, container.NewHBox(btnExport), nil, nil, tabs))
+	w.Resize(fyne.NewSize(1000, 700))
+	w.Show()
+}
+
+// table of the credible intervals of every sampled parameter
+func mcmcSummary(sampler *minimizer.Sampler, names []string, burnIn int) fyne.CanvasObject {
+	header := []string{"Parameter", "Median", "68.3% Interval", "95.4% Interval", "Mean", "Std. Dev."}
+	cells := make([]fyne.CanvasObject, 0)
+	for _, h := range header {
+		cells = append(cells, widget.NewLabelWithStyle(h, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
+	for i, id := range sampler.Free() {
+		m := sampler.Marginal(i, burnIn)
+		cells = append(cells,
+			widget.NewLabel(names[id]),
+			widget.NewLabel(fmt.Sprintf("%g", m.Median)),
+			widget.NewLabel(fmt.Sprintf("[%g, %g]", m.Sigma1.Lower, m.Sigma1.Upper)),
+			widget.NewLabel(fmt.Sprintf("[%g, %g]", m.Sigma2.Lower, m.Sigma2.Upper)),
+			widget.NewLabel(fmt.Sprintf("%g", m.Mean)),
+			widget.NewLabel(fmt.Sprintf("%g", m.StdDev)),
+		)
+	}
+
+	info := widget.NewLabel(fmt.Sprintf("%d walkers, %d steps (%d burn-in), acceptance fraction %.3f",
+		sampler.Walkers(), sampler.Steps(), burnIn, sampler.AcceptanceFraction()))
+
+	return container.NewVScroll(container.NewVBox(info, container.NewGridWithColumns(len(header), cells...)))
+}
+
+// one graph per parameter with the value of every walker over the steps
+func mcmcChains(sampler *minimizer.Sampler, names []string) fyne.CanvasObject {
+	graphs := make([]fyne.CanvasObject, 0)
+	for i, id := range sampler.Free() {
+		chains := sampler.Chain(i)
+		functions := make(function.Functions, len(chains))
+		for w, chain := range chains {
+			stride := max(len(chain)/mcmcChainPoints, 1)
+			points := make(function.Points, 0, mcmcChainPoints+1)
+			for step := 0; step < len(chain); step += stride {
+				// steps start at 1, the graph drops points at x = 0
+				points = append(points, &function.Point{X: float64(step + 1), Y: chain[step]})
+			}
+			functions[w] = function.NewFunction(points)
+		}
+		graphs = append(graphs, graph.NewGraphCanvas(&graph.GraphConfig{
+			Title:     names[id],
+			Functions: functions,
+			MinSize:   fyne.NewSize(450, 200),
+		}))
+	}
+	return container.NewGridWithColumns(2, graphs...)
+}
+
+func mcmcHistogram(sampler *minimizer.Sampler, param, burnIn int, title string, size fyne.Size) *graph.GraphCanvas {
+	centers, density := sampler.Histogram(param, burnIn, mcmcHistogramBins)
+	points := make(function.Points, len(centers))
+	for i := range centers {
+		points[i] = &function.Point{X: centers[i], Y: density[i]}
+	}
+	return graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:     title,
+		Functions: function.Functions{function.NewFunction(points)},
+		MinSize:   size,
+	})
+}
+
+// one marginal histogram per parameter
+func mcmcHistograms(sampler *minimizer.Sampler, names []string, burnIn int) fyne.CanvasObject {
+	graphs := make([]fyne.CanvasObject, 0)
+	for i, id := range sampler.Free() {
+		graphs = append(graphs, mcmcHistogram(sampler, i, burnIn, names[id], fyne.NewSize(450, 200)))
+	}
+	return container.NewGridWithColumns(2, graphs...)
+}
+
+// corner plot: marginal histograms on the diagonal and the pairwise samples below
+func mcmcCorner(sampler *minimizer.Sampler, names []string, burnIn int) fyne.CanvasObject {
+	free := sampler.Free()
+	size := fyne.NewSize(200, 200)
+	samples := make([][]float64, len(free))
+	for i := range free {
+		samples[i] = sampler.Samples(i, burnIn)
+	}
+
+	cells := make([]fyne.CanvasObject, 0, len(free)*len(free))
+	for row := range free {
+		for col := range free {
+			switch {
+			case row == col:
+				cells = append(cells, mcmcHistogram(sampler, row, burnIn, names[free[row]], size))
+			case row > col:
+				stride := max(len(samples[col])/mcmcScatterPoints, 1)
+				points := make(function.Points, 0, mcmcScatterPoints+1)
+				for k := 0; k < len(samples[col]); k += stride {
+					points = append(points, &function.Point{X: samples[col][k], Y: samples[row][k]})
+				}
+				cells = append(cells, graph.NewGraphCanvas(&graph.GraphConfig{
+					Title:     fmt.Sprintf("%s / %s", names[free[row]], names[free[col]]),
+					Functions: function.Functions{function.NewFunction(points)},
+					Scatter:   true,
+					MinSize:   size,
+				}))
+			default:
+				cells = append(cells, layout.NewSpacer())
+			}
+		}
+	}
+	return container.NewGridWithColumns(len(free), cells...)
+}
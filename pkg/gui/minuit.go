@@ -0,0 +1,302 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// confidence levels of a contour by the rise of the function at their border
+var contourLevels = []struct {
+	name string
+	up   float64
+}{
+	{"1σ of each parameter (+1)", 1},
+	{"68.3% of both (+2.3)", minimizer.ContourUp68},
+	{"95.4% of both (+6.18)", minimizer.ContourUp95},
+}
+
+// returns the problem of the session with the names of all parameters and the indices of the checked ones
+func minuitProblem(s *state.Session, needed int) (*minimizer.FitProblem, []string, []int, error) {
+	if s.Fit().Status.Active() {
+		return nil, nil, nil, state.ErrFitRunning
+	}
+	problem, err := s.FitProblem()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	free := problem.Free()
+	if len(free) < needed {
+		return nil, nil, nil, fmt.Errorf("check at least %d parameter(s) to analyse", needed)
+	}
+	return problem, s.Names(), free, nil
+}
+
+// names of the parameters with the indices
+func parameterNames(names []string, indices []int) []string {
+	selected := make([]string, len(indices))
+	for i, id := range indices {
+		selected[i] = names[id]
+	}
+	return selected
+}
+
+// runs the analysis in the background with a dialog to cancel it, errors are shown unless it was cancelled
+func runMinuit(title string, analysis func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := widget.NewProgressBarInfinite()
+	btnCancel := widget.NewButton("Cancel", cancel)
+	d := dialog.NewCustomWithoutButtons(title, container.NewVBox(progress, btnCancel), MainWindow)
+	d.Resize(fyne.NewSize(400, 100))
+	d.Show()
+
+	go func() {
+		defer cancel()
+		err := analysis(ctx)
+		progress.Stop()
+		d.Hide()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			dialog.ShowError(err, MainWindow)
+		}
+	}()
+}
+
+// scanDialog asks for a checked parameter and the range to scan the penalty of the session along
+func scanDialog(s *state.Session, config minimizer.MinuitConfig) {
+	problem, names, free, err := minuitProblem(s, 1)
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+	selParam := widget.NewSelect(parameterNames(names, free), nil)
+	selParam.SetSelectedIndex(0)
+	points := widget.NewEntry()
+	points.SetText("21")
+	low := widget.NewEntry()
+	low.SetPlaceHolder("value - 2 steps")
+	high := widget.NewEntry()
+	high.SetPlaceHolder("value + 2 steps")
+	chkProfile := widget.NewCheck("Minimize the other parameters", nil)
+	chkProfile.SetChecked(true)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Parameter", selParam),
+		widget.NewFormItem("Points", points),
+		widget.NewFormItem("From", low),
+		widget.NewFormItem("To", high),
+		widget.NewFormItem("Profile", chkProfile),
+	}
+	items[1].HintText = fmt.Sprintf("at most %d without profile", minimizer.MaxScanPoints)
+	items[4].HintText = "otherwise the other parameters keep their values"
+
+	dialog.ShowForm("Scan Parameter", "Scan", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		par := free[selParam.SelectedIndex()]
+		n, err := strconv.Atoi(points.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("points: %w", err), MainWindow)
+			return
+		}
+		var from, to float64
+		if low.Text != "" || high.Text != "" {
+			if from, err = param.StdFloatParser(low.Text); err != nil {
+				dialog.ShowError(fmt.Errorf("from: %w", err), MainWindow)
+				return
+			}
+			if to, err = param.StdFloatParser(high.Text); err != nil {
+				dialog.ShowError(fmt.Errorf("to: %w", err), MainWindow)
+				return
+			}
+		}
+
+		profile := chkProfile.Checked
+		runMinuit("Scanning "+names[par], func(ctx context.Context) error {
+			var scanned []minimizer.ScanPoint
+			var err error
+			if profile {
+				scanned, err = minimizer.Profile(ctx, problem, par, n, from, to, config)
+			} else {
+				scanned, err = minimizer.Scan(problem, par, n, from, to)
+			}
+			if err != nil {
+				return err
+			}
+			showScanResults(names[par], profile, scanned)
+			return nil
+		})
+	}, MainWindow)
+}
+
+// showScanResults opens a window with the penalty over the values of the parameter
+func showScanResults(name string, profile bool, scanned []minimizer.ScanPoint) {
+	title := "Scan of " + name
+	if profile {
+		title = "Profile of " + name
+	}
+	points := make(function.Points, len(scanned))
+	for i, p := range scanned {
+		points[i] = &function.Point{X: p.Value, Y: p.FVal}
+	}
+
+	w := fyne.CurrentApp().NewWindow(title)
+	w.SetContent(graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:     title,
+		XLabel:    name,
+		YLabel:    "penalty",
+		Functions: function.Functions{function.NewFunction(points)},
+		FunctionStyles: []graph.TrackStyle{
+			{Marker: graph.MarkerDot},
+		},
+	}))
+	w.Resize(fyne.NewSize(700, 450))
+	w.Show()
+}
+
+// minosDialog calculates the Minos errors of the checked parameters of the session
+func minosDialog(s *state.Session, config minimizer.MinuitConfig) {
+	problem, names, _, err := minuitProblem(s, 1)
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+	runMinuit("Minos Errors", func(ctx context.Context) error {
+		errs, res, err := minimizer.Minos(ctx, problem, nil, config)
+		if err != nil {
+			return err
+		}
+		showMinosResults(names, errs, res)
+		return nil
+	})
+}
+
+// text of a Minos error, crossings beyond a limit are marked
+func minosText(offset float64, atLimit, valid bool) string {
+	switch {
+	case !valid && offset == 0:
+		return "-"
+	case atLimit:
+		return fmt.Sprintf("%+g (limit)", offset)
+	}
+	return fmt.Sprintf("%+g", offset)
+}
+
+// showMinosResults opens a window with the asymmetric errors next to the parabolic ones
+func showMinosResults(names []string, errs []minimizer.MinosError, res *minimizer.FitResult) {
+	header := []string{"Parameter", "Value", "Parabolic Error", "Lower", "Upper"}
+	cells := make([]fyne.CanvasObject, 0)
+	for _, h := range header {
+		cells = append(cells, widget.NewLabelWithStyle(h, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
+	for _, e := range errs {
+		cells = append(cells,
+			widget.NewLabel(names[e.Parameter]),
+			widget.NewLabel(fmt.Sprintf("%g", e.Value)),
+			widget.NewLabel(fmt.Sprintf("±%g", e.Error)),
+			widget.NewLabel(minosText(e.Lower, e.AtLowerLimit, e.Valid)),
+			widget.NewLabel(minosText(e.Upper, e.AtUpperLimit, e.Valid)),
+		)
+	}
+	info := widget.NewLabel(fmt.Sprintf("minimum %g after %d calls, the parameters of the session are unchanged", res.FVal, res.NFcn))
+
+	w := fyne.CurrentApp().NewWindow("Minos Errors")
+	w.SetContent(container.NewVScroll(container.NewVBox(info, container.NewGridWithColumns(len(header), cells...))))
+	w.Resize(fyne.NewSize(700, 300))
+	w.Show()
+}
+
+// contourDialog asks for two checked parameters and the confidence level of their contour
+func contourDialog(s *state.Session, config minimizer.MinuitConfig) {
+	problem, names, free, err := minuitProblem(s, 2)
+	if err != nil {
+		dialog.ShowError(err, MainWindow)
+		return
+	}
+	options := parameterNames(names, free)
+	selX := widget.NewSelect(options, nil)
+	selX.SetSelectedIndex(0)
+	selY := widget.NewSelect(options, nil)
+	selY.SetSelectedIndex(1)
+	points := widget.NewEntry()
+	points.SetText("20")
+	levels := make([]string, len(contourLevels))
+	for i, l := range contourLevels {
+		levels[i] = l.name
+	}
+	selLevel := widget.NewSelect(levels, nil)
+	selLevel.SetSelectedIndex(0)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("X", selX),
+		widget.NewFormItem("Y", selY),
+		widget.NewFormItem("Points", points),
+		widget.NewFormItem("Confidence", selLevel),
+	}
+	items[3].HintText = "rise of the penalty above its minimum"
+
+	dialog.ShowForm("Contour", "Draw", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		px, py := free[selX.SelectedIndex()], free[selY.SelectedIndex()]
+		n, err := strconv.Atoi(points.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("points: %w", err), MainWindow)
+			return
+		}
+		level := contourLevels[selLevel.SelectedIndex()]
+		runMinuit(fmt.Sprintf("Contour of %s and %s", names[px], names[py]), func(ctx context.Context) error {
+			contour, res, err := minimizer.Contour(ctx, problem, px, py, n, level.up, config)
+			if err != nil {
+				return err
+			}
+			showContourResults(names[px], names[py], level.name, contour, res.Parameters[px], res.Parameters[py])
+			return nil
+		})
+	}, MainWindow)
+}
+
+// points of the contour, closed by its first point
+func contourPoints(contour []minimizer.ContourPoint) function.Points {
+	points := make(function.Points, 0, len(contour)+1)
+	for _, p := range contour {
+		points = append(points, &function.Point{X: p.X, Y: p.Y})
+	}
+	if len(contour) > 0 {
+		points = append(points, &function.Point{X: contour[0].X, Y: contour[0].Y})
+	}
+	return points
+}
+
+// showContourResults opens a window with the contour around the minimum
+func showContourResults(nameX, nameY, level string, contour []minimizer.ContourPoint, x, y float64) {
+	title := fmt.Sprintf("%s / %s", nameY, nameX)
+	w := fyne.CurrentApp().NewWindow("Contour " + title)
+	w.SetContent(graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:  fmt.Sprintf("%s, %s", title, level),
+		XLabel: nameX,
+		YLabel: nameY,
+		Functions: function.Functions{
+			function.NewFunction(contourPoints(contour)),
+			function.NewFunction(function.Points{{X: x, Y: y}}),
+		},
+		FunctionStyles: []graph.TrackStyle{
+			{Name: "contour", Marker: graph.MarkerDot},
+			{Name: "minimum", Marker: graph.MarkerCross, Line: graph.LineNone},
+		},
+	}))
+	w.Resize(fyne.NewSize(600, 500))
+	w.Show()
+}
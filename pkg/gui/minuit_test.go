@@ -0,0 +1,41 @@
+package gui
+
+import (
+	"context"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContourPoints(t *testing.T) {
+	points := contourPoints([]minimizer.ContourPoint{{X: 1, Y: 2}, {X: 3, Y: 4}, {X: 5, Y: 2}})
+	assert.Len(t, points, 4)
+	assert.Equal(t, &function.Point{X: 1, Y: 2}, points[3])
+	assert.Empty(t, contourPoints(nil))
+}
+
+func TestMinosText(t *testing.T) {
+	assert.Equal(t, "-0.5", minosText(-0.5, false, true))
+	assert.Equal(t, "+2 (limit)", minosText(2, true, true))
+	assert.Equal(t, "-", minosText(0, false, false))
+}
+
+func TestMinuitProblem(t *testing.T) {
+	_, s := newTestPanel(t)
+	problem, names, free, err := minuitProblem(s, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, names)
+	assert.Equal(t, []int{0}, free)
+	assert.Len(t, problem.Values, 1)
+
+	// a contour needs two checked parameters
+	_, _, _, err = minuitProblem(s, 2)
+	assert.Error(t, err)
+
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	_, _, _, err = minuitProblem(s, 1)
+	assert.ErrorIs(t, err, state.ErrFitRunning)
+}
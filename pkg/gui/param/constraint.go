@@ -0,0 +1,323 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/expression"
+	"slices"
+	"strings"
+)
+
+// Constraint ties a float parameter to an expression of other float parameters
+// f.e. "Thickness 2 = 2*Thickness 1" or "Eden b = 0.334"
+type Constraint struct {
+	Group      string
+	Label      string
+	Expression *expression.Expression
+
+	target *Parameter[float64]
+	// parameters of the variables of the expression, resolved once when the constraint is parsed
+	sources map[string]*Parameter[float64]
+}
+
+// ConstraintPlan evaluates the constraints on plain values ordered like the parameters it was compiled for
+// it reads no widgets or bindings, so the fits can use it on their own goroutines
+type ConstraintPlan struct {
+	steps   []constraintStep
+	derived []bool
+}
+
+// a constraint with the indices of its target and variables
+type constraintStep struct {
+	constraint *Constraint
+	target     int
+	sources    map[string]int
+}
+
+var (
+	// ErrConstraintCycle is returned when constraints depend on each other
+	ErrConstraintCycle = errors.New("constraints depend on each other")
+)
+
+// String returns the constraint in the form "Label = Expression"
+func (c *Constraint) String() string {
+	return c.Label + " = " + c.Expression.String()
+}
+
+// returns all labels of the float parameters which can be used inside an expression
+func (r *Registry) floatLabels() []string {
+	labels := make([]string, 0)
+	for _, group := range r.fParams {
+		labels = append(labels, group.GetKeys()...)
+	}
+	slices.Sort(labels)
+	return labels
+}
+
+// finds a float parameter by its label without knowing the group
+func (r *Registry) findFloat(label string) (string, *Parameter[float64], error) {
+	var foundGroup string
+	var found *Parameter[float64]
+	for group, elements := range r.fParams {
+		if p := elements.GetParam(label); p != nil {
+			if found != nil {
+				return "", nil, fmt.Errorf("parameter '%s' exists in groups '%s' and '%s'", label, foundGroup, group)
+			}
+			foundGroup = group
+			found = p
+		}
+	}
+	if found == nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrParameterNotFound, label)
+	}
+	return foundGroup, found, nil
+}
+
+// ParseConstraint parses a constraint in the form "Label = Expression"
+func (r *Registry) ParseConstraint(line string) (*Constraint, error) {
+	target, source, ok := strings.Cut(line, "=")
+	if !ok {
+		return nil, fmt.Errorf("constraint '%s' needs the form 'Parameter = Expression'", line)
+	}
+	target = strings.TrimSpace(target)
+
+	group, p, err := r.findFloat(target)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := expression.Parse(strings.TrimSpace(source), r.floatLabels())
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]*Parameter[float64], len(expr.Variables()))
+	for _, v := range expr.Variables() {
+		if v == target {
+			return nil, fmt.Errorf("%w: '%s' references itself", ErrConstraintCycle, target)
+		}
+		_, source, err := r.findFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		sources[v] = source
+	}
+
+	return &Constraint{
+		Group:      group,
+		Label:      target,
+		Expression: expr,
+		target:     p,
+		sources:    sources,
+	}, nil
+}
+
+// SetConstraints replaces all constraints with the given ones
+// each line has the form "Label = Expression", empty lines are ignored
+func (r *Registry) SetConstraints(lines []string) error {
+	parsed := make([]*Constraint, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		c, err := r.ParseConstraint(line)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(parsed, func(o *Constraint) bool { return o.target == c.target }) {
+			return fmt.Errorf("parameter '%s' is constrained more than once", c.Label)
+		}
+		parsed = append(parsed, c)
+	}
+
+	var plan *ConstraintPlan
+	if r.constraintParams != nil {
+		var err error
+		if plan, err = compileConstraints(parsed, r.constraintParams); err != nil {
+			return err
+		}
+	} else if _, err := orderConstraints(parsed); err != nil {
+		return err
+	}
+
+	// release old derived parameters
+	for _, c := range r.constraints {
+		c.target.setDerived(false)
+	}
+
+	r.constraints = parsed
+	for _, c := range r.constraints {
+		c.target.setDerived(true)
+	}
+	if plan != nil {
+		// the owner of the bound values applies the plan, the widgets follow it
+		return r.onConstraintsChanged(plan)
+	}
+
+	return r.ApplyConstraints()
+}
+
+// BindConstraints compiles the constraints for values ordered like the parameters, onChanged gets the plan now and
+// whenever the constraints are replaced and applies it, its error is returned by SetConstraints
+// constraints may only reference the parameters
+func (r *Registry) BindConstraints(params Parameters[float64], onChanged func(plan *ConstraintPlan) error) error {
+	plan, err := compileConstraints(r.constraints, params)
+	if err != nil {
+		return err
+	}
+	r.constraintParams = params
+	r.onConstraintsChanged = onChanged
+	return onChanged(plan)
+}
+
+// resolves the targets and variables of the constraints to indices of the parameters
+func compileConstraints(list []*Constraint, params Parameters[float64]) (*ConstraintPlan, error) {
+	ordered, err := orderConstraints(list)
+	if err != nil {
+		return nil, err
+	}
+	index := func(p *Parameter[float64], label string) (int, error) {
+		if i := slices.Index(params, p); i != -1 {
+			return i, nil
+		}
+		return 0, fmt.Errorf("parameter '%s' is not fitted and can't be used in constraints", label)
+	}
+
+	plan := &ConstraintPlan{derived: make([]bool, len(params))}
+	for _, c := range ordered {
+		step := constraintStep{constraint: c, sources: make(map[string]int, len(c.sources))}
+		if step.target, err = index(c.target, c.Label); err != nil {
+			return nil, err
+		}
+		for name, source := range c.sources {
+			if step.sources[name], err = index(source, name); err != nil {
+				return nil, err
+			}
+		}
+		plan.steps = append(plan.steps, step)
+		plan.derived[step.target] = true
+	}
+	return plan, nil
+}
+
+// Apply overwrites the derived values, the constraints are evaluated after the ones they depend on
+func (p *ConstraintPlan) Apply(values []float64) error {
+	if len(values) != len(p.derived) {
+		return errors.New("values and parameters have different length")
+	}
+	for _, step := range p.steps {
+		value, err := step.constraint.Expression.Eval(func(name string) (float64, error) {
+			return values[step.sources[name]], nil
+		})
+		if err != nil {
+			return fmt.Errorf("constraint '%s': %w", step.constraint, err)
+		}
+		values[step.target] = value
+	}
+	return nil
+}
+
+// Derived reports whether the parameter at the index is the target of a constraint
+func (p *ConstraintPlan) Derived(i int) bool {
+	return p.derived[i]
+}
+
+// GetConstraints returns all current constraints
+func (r *Registry) GetConstraints() []*Constraint {
+	return slices.Clone(r.constraints)
+}
+
+// IsConstrained checks if the parameter is derived from a constraint
+func (r *Registry) IsConstrained(p *Parameter[float64]) bool {
+	return slices.ContainsFunc(r.constraints, func(c *Constraint) bool { return c.target == p })
+}
+
+// sorts the constraints so every constraint is evaluated after the constraints it depends on
+func orderConstraints(list []*Constraint) ([]*Constraint, error) {
+	ordered := make([]*Constraint, 0, len(list))
+	done := make(map[*Constraint]bool)
+	visiting := make(map[*Constraint]bool)
+
+	byLabel := make(map[string]*Constraint)
+	for _, c := range list {
+		byLabel[c.Label] = c
+	}
+
+	var visit func(c *Constraint) error
+	visit = func(c *Constraint) error {
+		if done[c] {
+			return nil
+		}
+		if visiting[c] {
+			return fmt.Errorf("%w: '%s'", ErrConstraintCycle, c.Label)
+		}
+		visiting[c] = true
+		for _, v := range c.Expression.Variables() {
+			if dependency, ok := byLabel[v]; ok {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[c] = false
+		done[c] = true
+		ordered = append(ordered, c)
+		return nil
+	}
+
+	for _, c := range list {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// evaluates all constraints, values of parameters are resolved by the given lookup
+// returns the derived values by target parameter
+func (r *Registry) evaluateConstraints(lookup func(p *Parameter[float64]) (float64, error)) (map[*Parameter[float64]]float64, error) {
+	ordered, err := orderConstraints(r.constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	derived := make(map[*Parameter[float64]]float64, len(ordered))
+	for _, c := range ordered {
+		value, err := c.Expression.Eval(func(name string) (float64, error) {
+			p := c.sources[name]
+			if v, ok := derived[p]; ok {
+				return v, nil
+			}
+			return lookup(p)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("constraint '%s': %w", c, err)
+		}
+		derived[c.target] = value
+	}
+
+	return derived, nil
+}
+
+// ApplyConstraints evaluates all constraints with the current parameter values and updates the derived parameters
+func (r *Registry) ApplyConstraints() error {
+	derived, err := r.evaluateConstraints(func(p *Parameter[float64]) (float64, error) {
+		return p.Get()
+	})
+	if err != nil {
+		return err
+	}
+
+	for p, value := range derived {
+		// only update changed values, every update triggers a recalculation
+		if current, err := p.Get(); err == nil && current == value {
+			continue
+		}
+		if err := p.Set(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
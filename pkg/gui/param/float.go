@@ -4,10 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
 
-	"fyne.io/fyne/v2/widget"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
@@ -39,22 +38,94 @@ func FloatParameter(defaultValue float64) *Parameter[float64] {
 	})
 }
 
+// returns an initial minimizer step size scaled to the magnitude of the value
+func DefaultStep(value float64) float64 {
+	if value == 0 {
+		return 0.1
+	}
+
+	return math.Abs(value) * 0.1
+}
+
+// create a new step size parameter, a step size has to be positive
+func StepParameter(defaultValue float64) *Parameter[float64] {
+	return New(&Config[float64]{
+		InitialValue: defaultValue,
+		Validator: func(s string) error {
+			value, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return errors.New("keine gültige Zahl")
+			}
+
+			if value <= 0 {
+				return fmt.Errorf("step (%s) has to be positive", s)
+			}
+
+			return nil
+		},
+		Format: StdFloatFormater,
+		Parser: StdFloatParser,
+	})
+}
+
+// create a new fit order parameter, the order is a whole number from 0
+func OrderParameter() *Parameter[float64] {
+	return New(&Config[float64]{
+		InitialValue: 0,
+		Validator: func(s string) error {
+			value, err := strconv.Atoi(s)
+			if err != nil || value < 0 {
+				return fmt.Errorf("order (%s) has to be a whole number from 0", s)
+			}
+
+			return nil
+		},
+		Format: StdFloatFormater,
+		Parser: StdFloatParser,
+	})
+}
+
+// create a new fit error parameter, the error is unknown ("-") until a fit has been completed
+func ErrorParameter() *Parameter[float64] {
+	return New(&Config[float64]{
+		InitialValue: 0,
+		Validator: func(s string) error {
+			return nil
+		},
+		Format: func(f float64) string {
+			if f == 0 {
+				return "-"
+			}
+			return StdFloatFormater(f)
+		},
+		Parser: func(s string) (float64, error) {
+			if s == "-" {
+				return 0, nil
+			}
+			return StdFloatParser(s)
+		},
+	})
+}
+
 // create a new float input canvas object with a label
 // returns the canvas object and the parameter
-func Float(group, label string, defaultValue float64) (fyne.CanvasObject, *Parameter[float64]) {
-	if fParams[group] == nil {
-		fParams[group] = NewGroupElements[float64]()
+func (r *Registry) Float(group, label string, defaultValue float64) (fyne.CanvasObject, *Parameter[float64]) {
+	if r.fParams[group] == nil {
+		r.fParams[group] = NewGroupElements[float64]()
 	}
 
-	if fParams[group].Check(label) {
+	if r.fParams[group].Check(label) {
 		log.Fatal(errors.New("parameter key '" + label + "' already exists in group '" + group + "'"))
 	}
 
 	floatParameter := FloatParameter(defaultValue)
-	floatParameter.checkbox = widget.NewCheck("", func(b bool) {})
+	floatParameter.createCheckbox()
+	floatParameter.SetRelative("step", StepParameter(DefaultStep(defaultValue)))
+	floatParameter.SetRelative("error", ErrorParameter())
+	floatParameter.SetRelative("order", OrderParameter())
 
 	// add parameter to group
-	fParams[group].Add(label, floatParameter)
+	r.fParams[group].Add(label, floatParameter)
 
 	lbl := &canvas.Text{Text: label, Color: labelColor, TextSize: 14}
 
@@ -66,12 +137,12 @@ func Float(group, label string, defaultValue float64) (fyne.CanvasObject, *Param
 
 // create a new float input canvas object with a label and two min max input fields
 // returns the canvas object and the parameter
-func FloatMinMax(group, label string, defaultValue float64) (fyne.CanvasObject, *Parameter[float64]) {
-	if fParams[group] == nil {
-		fParams[group] = NewGroupElements[float64]()
+func (r *Registry) FloatMinMax(group, label string, defaultValue float64) (fyne.CanvasObject, *Parameter[float64]) {
+	if r.fParams[group] == nil {
+		r.fParams[group] = NewGroupElements[float64]()
 	}
 
-	if fParams[group].Check(label) {
+	if r.fParams[group].Check(label) {
 		log.Fatal(errors.New("parameter key '" + label + "' already exists in group '" + group + "'"))
 	}
 
@@ -130,11 +201,14 @@ func FloatMinMax(group, label string, defaultValue float64) (fyne.CanvasObject,
 	})
 	param.SetRelative("min", min)
 	param.SetRelative("max", max)
+	param.SetRelative("step", StepParameter(DefaultStep(defaultValue)))
+	param.SetRelative("error", ErrorParameter())
+	param.SetRelative("order", OrderParameter())
 
-	param.checkbox = widget.NewCheck("", nil)
+	param.createCheckbox()
 
 	// add parameter to group
-	fParams[group].Add(label, param)
+	r.fParams[group].Add(label, param)
 
 	lbl := &canvas.Text{Text: label, Color: labelColor, TextSize: 14}
 	minL := &canvas.Text{Text: "Minimum", Color: minMaxColor, TextSize: 11}
@@ -1,12 +1,14 @@
 package param
 
+import "slices"
+
 // Get single string value based on group and label
-func GetString(group, label string) (string, error) {
-	if sParams[group] == nil {
+func (r *Registry) GetString(group, label string) (string, error) {
+	if r.sParams[group] == nil {
 		return "", ErrParameterNotFound
 	}
 
-	if p := sParams[group].GetParam(label); p != nil {
+	if p := r.sParams[group].GetParam(label); p != nil {
 		return p.Get()
 	}
 
@@ -14,21 +16,21 @@ func GetString(group, label string) (string, error) {
 }
 
 // Get all string values based on group
-func GetStrings(group string) ([]string, error) {
-	if sParams[group] == nil {
+func (r *Registry) GetStrings(group string) ([]string, error) {
+	if r.sParams[group] == nil {
 		return nil, ErrParameterNotFound
 	}
 
-	return sParams[group].GetValues()
+	return r.sParams[group].GetValues()
 }
 
 // Get single float value based on group and label
-func GetFloat(group, label string) (float64, error) {
-	if fParams[group] == nil {
+func (r *Registry) GetFloat(group, label string) (float64, error) {
+	if r.fParams[group] == nil {
 		return 0, ErrParameterNotFound
 	}
 
-	if p := fParams[group].GetParam(label); p != nil {
+	if p := r.fParams[group].GetParam(label); p != nil {
 		return p.Get()
 	}
 
@@ -36,21 +38,21 @@ func GetFloat(group, label string) (float64, error) {
 }
 
 // Get all float values based on group
-func GetFloats(group string) ([]float64, error) {
-	if fParams[group] == nil {
+func (r *Registry) GetFloats(group string) ([]float64, error) {
+	if r.fParams[group] == nil {
 		return nil, ErrParameterNotFound
 	}
 
-	return fParams[group].GetValues()
+	return r.fParams[group].GetValues()
 }
 
 // Get single int value based on group and label
-func GetInt(group, label string) (int, error) {
-	if iParams[group] == nil {
+func (r *Registry) GetInt(group, label string) (int, error) {
+	if r.iParams[group] == nil {
 		return 0, ErrParameterNotFound
 	}
 
-	if p := iParams[group].GetParam(label); p != nil {
+	if p := r.iParams[group].GetParam(label); p != nil {
 		return p.Get()
 	}
 
@@ -58,10 +60,32 @@ func GetInt(group, label string) (int, error) {
 }
 
 // Get all int values based on group
-func GetInts(group string) ([]int, error) {
-	if iParams[group] == nil {
+func (r *Registry) GetInts(group string) ([]int, error) {
+	if r.iParams[group] == nil {
 		return nil, ErrParameterNotFound
 	}
 
-	return iParams[group].GetValues()
+	return r.iParams[group].GetValues()
+}
+
+// GetFloatLabel returns the label of a float parameter, empty if the parameter isn't registered
+func (r *Registry) GetFloatLabel(p *Parameter[float64]) string {
+	for _, group := range r.fParams {
+		for _, label := range group.GetLabels() {
+			if group.GetParam(label) == p {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
+// GetFloatGroupName returns the group of a float parameter, empty if the parameter isn't registered
+func (r *Registry) GetFloatGroupName(p *Parameter[float64]) string {
+	for name, group := range r.fParams {
+		if slices.Contains(group.params, p) {
+			return name
+		}
+	}
+	return ""
 }
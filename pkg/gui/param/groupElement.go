@@ -23,6 +23,15 @@ func (g *GroupElements[T]) GetKeys() []string {
 	return slices.Collect(maps.Keys(g.ref))
 }
 
+// returns the labels of all parameters in the order they were added
+func (g *GroupElements[T]) GetLabels() []string {
+	labels := make([]string, len(g.params))
+	for label, i := range g.ref {
+		labels[i-1] = label
+	}
+	return labels
+}
+
 // checks if parameter is in the group
 func (g *GroupElements[T]) Check(label string) bool {
 	return g.ref[label] != 0
@@ -6,8 +6,6 @@ import (
 	"log"
 	"strconv"
 
-	"fyne.io/fyne/v2/widget"
-
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
@@ -37,20 +35,20 @@ func IntParameter(defaultValue int) *Parameter[int] {
 
 // create a new int input canvas object with a label
 // returns the canvas object and the parameter
-func Int(group, label string, defaultValue int) (fyne.CanvasObject, *Parameter[int]) {
-	if iParams[group] == nil {
-		iParams[group] = NewGroupElements[int]()
+func (r *Registry) Int(group, label string, defaultValue int) (fyne.CanvasObject, *Parameter[int]) {
+	if r.iParams[group] == nil {
+		r.iParams[group] = NewGroupElements[int]()
 	}
 
-	if !iParams[group].Check(label) {
+	if !r.iParams[group].Check(label) {
 		log.Fatal(errors.New("parameter key '" + label + "' already exists in group '" + group + "'"))
 	}
 
 	intParameter := IntParameter(defaultValue)
-	intParameter.checkbox = widget.NewCheck("", func(b bool) {})
+	intParameter.createCheckbox()
 
 	// add parameter to group
-	iParams[group].Add(label, intParameter)
+	r.iParams[group].Add(label, intParameter)
 
 	lbl := &canvas.Text{Text: label, Color: labelColor, TextSize: 14}
 
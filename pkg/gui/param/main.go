@@ -9,22 +9,42 @@ import (
 
 type ParameterGroup[T any] map[string]*GroupElements[T]
 
-var (
+// Registry holds the parameters and the constraints of one sample
+// every sample has its own registry, so the same group and label can exist in several samples
+type Registry struct {
 	// sParams is a map of string parameter groups
 	// each group contains a map of parameter labels and their values
 	// each group can be used for iterating through parameters of the same type
-	sParams = make(ParameterGroup[string])
+	sParams ParameterGroup[string]
 
 	// fParams is a map of float parameter groups
 	// each group contains a map of parameter labels and their values
 	// each group can be used for iterating through parameters of the same type (edesntiy, roughness, thickness)
-	fParams = make(ParameterGroup[float64])
+	fParams ParameterGroup[float64]
 
 	// iParams is a map of int parameter groups
 	// each group contains a map of parameter labels and their values
 	// each group can be used for iterating through parameters of the same type (limits, number of slabs)
-	iParams = make(ParameterGroup[int])
+	iParams ParameterGroup[int]
+
+	// constraints ordered by creation, evaluation order is resolved on apply
+	constraints []*Constraint
+	// parameters the constraints are compiled for and the receiver of the compiled constraints
+	constraintParams     Parameters[float64]
+	onConstraintsChanged func(plan *ConstraintPlan) error
+}
 
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		sParams:     make(ParameterGroup[string]),
+		fParams:     make(ParameterGroup[float64]),
+		iParams:     make(ParameterGroup[int]),
+		constraints: make([]*Constraint, 0),
+	}
+}
+
+var (
 	// label configs
 	labelColor  = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
 	minMaxColor = color.NRGBA{R: 120, G: 120, B: 120, A: 255}
@@ -33,23 +53,23 @@ var (
 	ErrParameterNotFound = errors.New("parameter not found")
 )
 
-func GetStringGroup(group string) *GroupElements[string] {
-	return sParams[group]
+func (r *Registry) GetStringGroup(group string) *GroupElements[string] {
+	return r.sParams[group]
 }
 
-func GetFloatGroup(group string) *GroupElements[float64] {
-	return fParams[group]
+func (r *Registry) GetFloatGroup(group string) *GroupElements[float64] {
+	return r.fParams[group]
 }
 
-func GetIntGroup(group string) *GroupElements[int] {
-	return iParams[group]
+func (r *Registry) GetIntGroup(group string) *GroupElements[int] {
+	return r.iParams[group]
 }
-func GetStringKeys() []string {
-	return slices.Collect(maps.Keys(sParams))
+func (r *Registry) GetStringKeys() []string {
+	return slices.Collect(maps.Keys(r.sParams))
 }
-func GetFloatKeys() []string {
-	return slices.Collect(maps.Keys(fParams))
+func (r *Registry) GetFloatKeys() []string {
+	return slices.Collect(maps.Keys(r.fParams))
 }
-func GetIntKeys() []string {
-	return slices.Collect(maps.Keys(iParams))
+func (r *Registry) GetIntKeys() []string {
+	return slices.Collect(maps.Keys(r.iParams))
 }
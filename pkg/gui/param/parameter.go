@@ -1,8 +1,6 @@
 package param
 
 import (
-	"physicsGUI/pkg/trigger"
-
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/widget"
 )
@@ -17,6 +15,7 @@ type Parameter[T any] struct {
 
 	// use for fit checkbox
 	checkbox *widget.Check
+	checked  binding.Bool
 }
 
 type Parameters[T any] []*Parameter[T]
@@ -40,12 +39,9 @@ func New[T any](config *Config[T]) *Parameter[T] {
 		relatives: make(map[string]*Parameter[T]),
 	}
 
-	// creates the widget with the binding
+	// creates the widget with the binding, changes reach the observers of the binding
 	f.widget = widget.NewEntryWithData(f.binding)
 	f.widget.Validator = config.Validator
-	f.widget.OnChanged = func(s string) {
-		trigger.Recalc()
-	}
 
 	f.Set(config.InitialValue)
 
@@ -89,6 +85,24 @@ func (f *Parameter[T]) Widget() *widget.Entry {
 	return f.widget
 }
 
+// Binding returns the data binding of the parameter, it can be used to create additional widgets for the same value
+func (f *Parameter[T]) Binding() binding.String {
+	return f.binding
+}
+
+// creates the use for fit checkbox of the parameter
+func (f *Parameter[T]) createCheckbox() *widget.Check {
+	f.checked = binding.NewBool()
+	f.checkbox = widget.NewCheckWithData("", f.checked)
+	return f.checkbox
+}
+
+// CheckBinding returns the data binding of the use for fit checkbox
+// returns nil if the parameter has no checkbox
+func (f *Parameter[T]) CheckBinding() binding.Bool {
+	return f.checked
+}
+
 // SetCheckbox sets the checkbox of the parameter
 // returns true if checkbox isn't set
 func (f *Parameter[T]) IsChecked() bool {
@@ -96,11 +110,36 @@ func (f *Parameter[T]) IsChecked() bool {
 		return true
 	}
 
+	if f.checked != nil {
+		checked, err := f.checked.Get()
+		return err == nil && checked
+	}
+
 	return f.checkbox.Checked
 }
 
 func (f *Parameter[T]) SetCheck(checked bool) {
 	if f.checkbox == nil {
 	}
+	if f.checked != nil {
+		_ = f.checked.Set(checked)
+		return
+	}
 	f.checkbox.SetChecked(checked)
 }
+
+// marks the parameter as derived (read-only), derived parameters are calculated by constraints and never fitted
+func (f *Parameter[T]) setDerived(derived bool) {
+	if derived {
+		f.widget.Disable()
+		if f.checkbox != nil {
+			f.SetCheck(false)
+			f.checkbox.Disable()
+		}
+	} else {
+		f.widget.Enable()
+		if f.checkbox != nil {
+			f.checkbox.Enable()
+		}
+	}
+}
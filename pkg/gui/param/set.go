@@ -1,55 +1,55 @@
 package param
 
 // sets a string value for a specific group and label
-func SetString(group, label, value string) error {
-	if sParams[group] == nil || !sParams[group].Check(label) {
+func (r *Registry) SetString(group, label, value string) error {
+	if r.sParams[group] == nil || !r.sParams[group].Check(label) {
 		return ErrParameterNotFound
 	}
 
-	return sParams[group].Set(label, value)
+	return r.sParams[group].Set(label, value)
 }
 
 // sets string values for a specific group
-func SetStrings(group string, values []string) error {
-	if sParams[group] == nil {
+func (r *Registry) SetStrings(group string, values []string) error {
+	if r.sParams[group] == nil {
 		return ErrParameterNotFound
 	}
 
-	return sParams[group].SetAll(values)
+	return r.sParams[group].SetAll(values)
 }
 
 // sets a float value for a specific group and label
-func SetFloat(group, label string, value float64) error {
-	if fParams[group] == nil || !fParams[group].Check(label) {
+func (r *Registry) SetFloat(group, label string, value float64) error {
+	if r.fParams[group] == nil || !r.fParams[group].Check(label) {
 		return ErrParameterNotFound
 	}
 
-	return fParams[group].Set(label, value)
+	return r.fParams[group].Set(label, value)
 }
 
 // sets float values for a specific group
-func SetFloats(group string, values []float64) error {
-	if fParams[group] == nil {
+func (r *Registry) SetFloats(group string, values []float64) error {
+	if r.fParams[group] == nil {
 		return ErrParameterNotFound
 	}
 
-	return fParams[group].SetAll(values)
+	return r.fParams[group].SetAll(values)
 }
 
 // sets an int value for a specific group and label
-func SetInt(group, label string, value int) error {
-	if iParams[group] == nil || !iParams[group].Check(label) {
+func (r *Registry) SetInt(group, label string, value int) error {
+	if r.iParams[group] == nil || !r.iParams[group].Check(label) {
 		return ErrParameterNotFound
 	}
 
-	return iParams[group].Set(label, value)
+	return r.iParams[group].Set(label, value)
 }
 
 // sets int values for a specific group
-func SetInts(group string, values []int) error {
-	if iParams[group] == nil {
+func (r *Registry) SetInts(group string, values []int) error {
+	if r.iParams[group] == nil {
 		return ErrParameterNotFound
 	}
 
-	return iParams[group].SetAll(values)
+	return r.iParams[group].SetAll(values)
 }
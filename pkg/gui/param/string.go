@@ -32,19 +32,19 @@ func StringParameter(defaultValue string) *Parameter[string] {
 }
 
 // created a new string input field with a label
-func String(group, label, defaultValue string) (fyne.CanvasObject, *Parameter[string]) {
-	if sParams[group] == nil {
-		sParams[group] = NewGroupElements[string]()
+func (r *Registry) String(group, label, defaultValue string) (fyne.CanvasObject, *Parameter[string]) {
+	if r.sParams[group] == nil {
+		r.sParams[group] = NewGroupElements[string]()
 	}
 
-	if sParams[group].Check(label) {
+	if r.sParams[group].Check(label) {
 		log.Fatal(errors.New("parameter key '" + label + "' already exists in group '" + group + "'"))
 	}
 
 	stringParameter := StringParameter(defaultValue)
 
 	// add parameter to group
-	sParams[group].Add(label, stringParameter)
+	r.sParams[group].Add(label, stringParameter)
 
 	lbl := &canvas.Text{Text: label, Color: labelColor, TextSize: 14}
 
@@ -0,0 +1,429 @@
+package param
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// columns of the parameter table
+const (
+	columnGroup = iota
+	columnName
+	columnValue
+	columnMin
+	columnMax
+	columnFit
+	columnError
+	columnStep
+	columnOrder
+	columnCount
+)
+
+var (
+	tableColumns = []string{"Group", "Name", "Value", "Min", "Max", "Fit", "Error", "Step", "Order"}
+	tableWidths  = []float32{90, 130, 120, 100, 100, 50, 100, 100, 60}
+
+	// relative of the parameter shown in a column
+	tableRelatives = map[int]string{
+		columnMin:   "min",
+		columnMax:   "max",
+		columnError: "error",
+		columnStep:  "step",
+		columnOrder: "order",
+	}
+)
+
+type tableRow struct {
+	group string
+	label string
+	param *Parameter[float64]
+}
+
+// Table is a spreadsheet like view of all float parameters with one row per parameter
+// columns can be sorted, edited in place and copied to/pasted from a spreadsheet
+type Table struct {
+	table    *widget.Table
+	window   fyne.Window
+	registry *Registry
+	rows     []*tableRow
+
+	sortColumn    int
+	sortAscending bool
+}
+
+// NewTable creates a table of all float parameters registered so far in the registry
+func NewTable(window fyne.Window, registry *Registry) *Table {
+	t := &Table{
+		window:        window,
+		registry:      registry,
+		rows:          make([]*tableRow, 0),
+		sortColumn:    -1,
+		sortAscending: true,
+	}
+
+	groups := registry.GetFloatKeys()
+	slices.Sort(groups)
+	for _, group := range groups {
+		for _, label := range registry.fParams[group].GetLabels() {
+			t.rows = append(t.rows, &tableRow{
+				group: group,
+				label: label,
+				param: registry.fParams[group].GetParam(label),
+			})
+		}
+	}
+
+	t.table = widget.NewTable(t.length, t.createCell, t.updateCell)
+	t.table.ShowHeaderRow = true
+	t.table.CreateHeader = t.createHeader
+	t.table.UpdateHeader = t.updateHeader
+	t.table.StickyColumnCount = 2
+	for i, w := range tableWidths {
+		t.table.SetColumnWidth(i, w)
+	}
+
+	return t
+}
+
+// Widget returns the drawable table
+func (t *Table) Widget() fyne.CanvasObject {
+	return t.table
+}
+
+// Refresh redraws the table, f.e. after constraints changed which parameters are derived
+func (t *Table) Refresh() {
+	t.table.Refresh()
+}
+
+func (t *Table) length() (int, int) {
+	return len(t.rows), columnCount
+}
+
+// returns the parameter shown in a cell, nil if the parameter has no such relative
+func (t *Table) cellParameter(id widget.TableCellID) *Parameter[float64] {
+	p := t.rows[id.Row].param
+	if relative, ok := tableRelatives[id.Col]; ok {
+		return p.GetRelative(relative)
+	}
+	return p
+}
+
+// every cell can show a label, an entry or a checkbox, the matching one is shown on update
+func (t *Table) createCell() fyne.CanvasObject {
+	entry := widget.NewEntry()
+	check := widget.NewCheck("", nil)
+	return container.NewStack(widget.NewLabel(""), entry, check)
+}
+
+func (t *Table) updateCell(id widget.TableCellID, o fyne.CanvasObject) {
+	cell := o.(*fyne.Container)
+	label := cell.Objects[0].(*widget.Label)
+	entry := cell.Objects[1].(*widget.Entry)
+	check := cell.Objects[2].(*widget.Check)
+
+	label.Unbind()
+	entry.Unbind()
+	check.Unbind()
+	label.Hide()
+	entry.Hide()
+	check.Hide()
+
+	row := t.rows[id.Row]
+	derived := t.registry.IsConstrained(row.param)
+
+	switch id.Col {
+	case columnGroup:
+		label.SetText(row.group)
+		label.Show()
+	case columnName:
+		label.SetText(row.label)
+		label.Show()
+	case columnFit:
+		if row.param.CheckBinding() == nil {
+			label.SetText("-")
+			label.Show()
+			return
+		}
+		check.Bind(row.param.CheckBinding())
+		if derived {
+			check.Disable()
+		} else {
+			check.Enable()
+		}
+		check.Show()
+	case columnError:
+		p := t.cellParameter(id)
+		if p == nil {
+			label.SetText("-")
+		} else {
+			label.Bind(p.Binding())
+		}
+		label.Show()
+	default:
+		p := t.cellParameter(id)
+		if p == nil {
+			label.SetText("-")
+			label.Show()
+			return
+		}
+		// the table shares the binding with the parameter widget
+		entry.Bind(p.Binding())
+		entry.Validator = p.config.Validator
+		if derived && id.Col == columnValue {
+			entry.Disable()
+		} else {
+			entry.Enable()
+		}
+		entry.Show()
+	}
+}
+
+// the header shows the column name (click to sort) and buttons to copy/paste the column
+func (t *Table) createHeader() fyne.CanvasObject {
+	sort := widget.NewButton("", nil)
+	sort.Importance = widget.LowImportance
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), nil)
+	copyBtn.Importance = widget.LowImportance
+	pasteBtn := widget.NewButtonWithIcon("", theme.ContentPasteIcon(), nil)
+	pasteBtn.Importance = widget.LowImportance
+	return container.NewBorder(nil, nil, nil, container.NewHBox(copyBtn, pasteBtn), sort)
+}
+
+func (t *Table) updateHeader(id widget.TableCellID, o fyne.CanvasObject) {
+	header := o.(*fyne.Container)
+	sort := header.Objects[0].(*widget.Button)
+	buttons := header.Objects[1].(*fyne.Container)
+	copyBtn := buttons.Objects[0].(*widget.Button)
+	pasteBtn := buttons.Objects[1].(*widget.Button)
+
+	if id.Col < 0 {
+		sort.SetText("")
+		buttons.Hide()
+		return
+	}
+	buttons.Show()
+
+	text := tableColumns[id.Col]
+	if id.Col == t.sortColumn {
+		if t.sortAscending {
+			text += " ▲"
+		} else {
+			text += " ▼"
+		}
+	}
+	sort.SetText(text)
+
+	col := id.Col
+	sort.OnTapped = func() {
+		t.Sort(col)
+	}
+	copyBtn.OnTapped = func() {
+		t.window.Clipboard().SetContent(t.CopyColumn(col))
+	}
+	pasteBtn.OnTapped = func() {
+		if err := t.PasteColumn(col, t.window.Clipboard().Content()); err != nil {
+			dialog.ShowError(err, t.window)
+		}
+	}
+	if col == columnGroup || col == columnName || col == columnError {
+		pasteBtn.Disable()
+	} else {
+		pasteBtn.Enable()
+	}
+}
+
+// returns the text of a cell as it would be copied
+func (t *Table) cellText(row *tableRow, col int) string {
+	switch col {
+	case columnGroup:
+		return row.group
+	case columnName:
+		return row.label
+	case columnFit:
+		return strconv.FormatBool(row.param.IsChecked())
+	}
+
+	p := row.param
+	if relative, ok := tableRelatives[col]; ok {
+		p = p.GetRelative(relative)
+	}
+	if p == nil {
+		return ""
+	}
+	s, err := p.binding.Get()
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// Sort sorts the rows by the given column, sorting the same column again reverses the order
+func (t *Table) Sort(col int) {
+	if t.sortColumn == col {
+		t.sortAscending = !t.sortAscending
+	} else {
+		t.sortColumn = col
+		t.sortAscending = true
+	}
+
+	slices.SortStableFunc(t.rows, func(a, b *tableRow) int {
+		var c int
+		av, aErr := strconv.ParseFloat(t.cellText(a, col), 64)
+		bv, bErr := strconv.ParseFloat(t.cellText(b, col), 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(av, bv)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(t.cellText(a, col), t.cellText(b, col))
+		}
+		if !t.sortAscending {
+			return -c
+		}
+		return c
+	})
+
+	t.table.Refresh()
+}
+
+// CopyColumn returns the values of a column in the current row order, one value per line
+func (t *Table) CopyColumn(col int) string {
+	lines := make([]string, len(t.rows))
+	for i, row := range t.rows {
+		lines[i] = t.cellText(row, col)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parses a spreadsheet cell, decimal commas are accepted
+func parseCell(s string) (float64, error) {
+	return StdFloatParser(strings.ReplaceAll(strings.TrimSpace(s), ",", "."))
+}
+
+// parses a spreadsheet boolean cell
+func parseBoolCell(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "true", "yes", "x", "wahr":
+		return true, nil
+	case "0", "false", "no", "", "falsch":
+		return false, nil
+	}
+	return false, fmt.Errorf("'%s' is not a boolean", s)
+}
+
+// checks a pasted value of the cell p of the parameter with its validator and against the limits of the parameter
+func checkPaste(param, p *Parameter[float64], col int, value float64) error {
+	if err := p.config.Validator(p.config.Format(value)); err != nil {
+		return err
+	}
+	bound := func(relative string) (float64, bool) {
+		if r := param.GetRelative(relative); r != nil {
+			v, err := r.Get()
+			return v, err == nil
+		}
+		return 0, false
+	}
+	current, err := param.Get()
+	if err != nil {
+		return err
+	}
+	switch col {
+	case columnMin:
+		if maximum, ok := bound("max"); ok && value > maximum {
+			return fmt.Errorf("minimum %g is greater than the maximum %g", value, maximum)
+		}
+		if value > current {
+			return fmt.Errorf("minimum %g is greater than the value %g", value, current)
+		}
+	case columnMax:
+		if minimum, ok := bound("min"); ok && value < minimum {
+			return fmt.Errorf("maximum %g is smaller than the minimum %g", value, minimum)
+		}
+		if value < current {
+			return fmt.Errorf("maximum %g is smaller than the value %g", value, current)
+		}
+	}
+	return nil
+}
+
+// PasteColumn sets the values of a column in the current row order from text copied from a spreadsheet
+// every line holds the value of one row, additional tab separated cells are ignored
+// nothing is set if any value is invalid for its cell
+func (t *Table) PasteColumn(col int, content string) error {
+	if col == columnGroup || col == columnName || col == columnError {
+		return fmt.Errorf("column %s can not be pasted", tableColumns[col])
+	}
+
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	if len(lines) != len(t.rows) {
+		return fmt.Errorf("clipboard contains %d values but the table has %d rows", len(lines), len(t.rows))
+	}
+
+	// parse everything first so a bad value doesn't leave the column half pasted
+	values := make([]float64, len(lines))
+	checks := make([]bool, len(lines))
+	for i, line := range lines {
+		cell, _, _ := strings.Cut(line, "\t")
+		var err error
+		if col == columnFit {
+			checks[i], err = parseBoolCell(cell)
+		} else {
+			values[i], err = parseCell(cell)
+		}
+		if err != nil {
+			return fmt.Errorf("row %d (%s): %w", i+1, t.rows[i].label, err)
+		}
+	}
+
+	// validate everything before setting anything, f.e. steps <= 0 or a minimum above the maximum
+	pasted := make([]*Parameter[float64], len(t.rows))
+	for i, row := range t.rows {
+		if col == columnFit || (t.registry.IsConstrained(row.param) && col == columnValue) {
+			continue
+		}
+		p := row.param
+		if relative, ok := tableRelatives[col]; ok {
+			p = p.GetRelative(relative)
+		}
+		if p == nil {
+			continue
+		}
+		if err := checkPaste(row.param, p, col, values[i]); err != nil {
+			return fmt.Errorf("row %d (%s): %w", i+1, row.label, err)
+		}
+		pasted[i] = p
+	}
+
+	errs := make([]error, 0)
+	for i, row := range t.rows {
+		if col == columnFit {
+			if !t.registry.IsConstrained(row.param) {
+				row.param.SetCheck(checks[i])
+			}
+			continue
+		}
+		p := pasted[i]
+		if p == nil {
+			continue
+		}
+		if err := p.Set(values[i]); err != nil {
+			errs = append(errs, fmt.Errorf("row %d (%s): %w", i+1, row.label, err))
+		}
+	}
+
+	t.table.Refresh()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,36 @@
+package param
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasteColumn(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	r := NewRegistry()
+	_, a := r.FloatMinMax("thick", "a", 5)
+	_, b := r.FloatMinMax("thick", "b", 50)
+	table := NewTable(test.NewWindow(nil), r)
+
+	assert.NoError(t, table.PasteColumn(columnStep, "0.5\n2"))
+	step, _ := b.GetRelative("step").Get()
+	assert.Equal(t, 2.0, step)
+
+	// a single invalid value rejects the whole paste
+	for col, content := range map[int]string{
+		columnStep:  "1\n0",
+		columnMin:   "1\n200",
+		columnMax:   "1\n90",
+		columnValue: "6\n-1",
+		columnOrder: "1\n0.5",
+	} {
+		before := table.CopyColumn(col)
+		assert.Error(t, table.PasteColumn(col, content), tableColumns[col])
+		assert.Equal(t, before, table.CopyColumn(col), tableColumns[col])
+	}
+	value, _ := a.Get()
+	assert.Equal(t, 5.0, value)
+}
@@ -0,0 +1,44 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/physics"
+)
+
+// updatePatterson shows the Patterson function of the electron density profile and of every data track of the
+// intensity graph, the data is transformed on the z axis of the profile
+func (s *sample) updatePatterson(edenPoints function.Points, deltaq float64) {
+	pattersonGraph, ok := s.graphMap["patterson"]
+	if !ok {
+		return
+	}
+
+	// the function leaves out z = 0 like all points at x = 0
+	s.functionMap["patterson"].SetData(physics.Patterson(edenPoints))
+	model := s.functionMap["patterson"].GetData()
+
+	zaxis := make([]float64, len(model))
+	for i, p := range model {
+		zaxis[i] = p.X
+	}
+	dataTracks := s.graphMap["intensity"].GetDataTracks()
+	functions := function.Functions{s.functionMap["patterson"]}
+	for _, dataTrack := range dataTracks {
+		functions = append(functions, function.NewFunction(physics.PattersonOfData(dataTrack.GetData(), deltaq, zaxis)))
+	}
+
+	s.updatePattersonStyles()
+	pattersonGraph.SetFunctions(functions)
+}
+
+// updatePattersonStyles names, colors and hides the Patterson functions of the data like their data tracks
+func (s *sample) updatePattersonStyles() {
+	pattersonGraph, ok := s.graphMap["patterson"]
+	if !ok {
+		return
+	}
+
+	pattersonGraph.Config.FunctionStyles = append([]graph.TrackStyle{{Name: "Model"}}, s.dataTrackStyles()...)
+	pattersonGraph.Refresh()
+}
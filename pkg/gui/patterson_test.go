@@ -0,0 +1,32 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattersonGraph(t *testing.T) {
+	TestSetup(t)
+	dataTrack := function.NewFunction(function.Points{
+		{X: 0.02, Y: 0.5, Error: 0.1},
+		{X: 0.05, Y: 0.01, Error: 0.002},
+	})
+	testSample.graphMap["intensity"].AddDataTrack(dataTrack)
+	defer testSample.graphMap["intensity"].RemoveDataTrack(dataTrack)
+
+	// the model and one function per data track on the z axis of the model
+	functions := testSample.graphMap["patterson"].Config.Functions
+	assert.Equal(t, testSample.functionMap["patterson"], functions[0])
+	assert.Len(t, functions, 1+len(testSample.graphMap["intensity"].GetDataTracks()))
+	model := functions[0].GetData()
+	assert.NotEmpty(t, model)
+	data := functions[len(functions)-1].GetData()
+	assert.Len(t, data, len(model))
+	assert.Equal(t, model[len(model)-1].X, data[len(data)-1].X)
+
+	styles := testSample.graphMap["patterson"].Config.FunctionStyles
+	assert.Equal(t, "Model", styles[0].Name)
+	assert.Equal(t, testSample.graphMap["intensity"].DataTrackStyle(len(functions)-2).Name, styles[len(styles)-1].Name)
+}
@@ -0,0 +1,131 @@
+package gui
+
+import (
+	"fmt"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/minimizer"
+	"slices"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// fitProgress shows the penalty and the trace of a parameter over the function calls of the last fit
+// it is updated after every iteration and keeps the history until the next fit is started
+type fitProgress struct {
+	history *minimizer.FitHistory
+
+	lock sync.RWMutex
+	// names of the free parameters and their indices in the fit problem
+	names []string
+	free  []int
+
+	convergence      *function.Function
+	trace            *function.Function
+	convergenceGraph *graph.GraphCanvas
+	traceGraph       *graph.GraphCanvas
+	selParam         *widget.Select
+}
+
+func newFitProgress() *fitProgress {
+	p := &fitProgress{
+		history:     minimizer.NewFitHistory(),
+		convergence: function.NewEmptyFunction(),
+		trace:       function.NewEmptyFunction(),
+	}
+	p.convergenceGraph = graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:     "Convergence (FVal vs Calls)",
+		XScale:    graph.ScaleLog,
+		YScale:    graph.ScaleLog,
+		XLabel:    "Calls",
+		YLabel:    "FVal",
+		Functions: function.Functions{p.convergence},
+		MinSize:   fyne.NewSize(300, 200),
+	})
+	p.traceGraph = graph.NewGraphCanvas(&graph.GraphConfig{
+		Title:     "Parameter Trace",
+		XLabel:    "Calls",
+		YLabel:    "Value",
+		Functions: function.Functions{p.trace},
+		MinSize:   fyne.NewSize(300, 200),
+	})
+	p.selParam = widget.NewSelect(nil, func(string) {
+		p.updateTrace()
+	})
+	p.selParam.PlaceHolder = "no fit started"
+	return p
+}
+
+func (p *fitProgress) Widget() fyne.CanvasObject {
+	return container.NewGridWithColumns(2,
+		p.convergenceGraph,
+		container.NewBorder(container.NewHBox(widget.NewLabel("Parameter"), p.selParam), nil, nil, nil, p.traceGraph),
+	)
+}
+
+// reset clears the history for a new fit of the free parameters, names are indexed like the fit problem
+func (p *fitProgress) reset(names []string, free []int) {
+	p.history.Reset()
+
+	p.lock.Lock()
+	p.names = make([]string, len(free))
+	for i, id := range free {
+		p.names[i] = names[id]
+	}
+	p.free = slices.Clone(free)
+	options := slices.Clone(p.names)
+	p.lock.Unlock()
+
+	selected := p.selParam.Selected
+	p.selParam.SetOptions(options)
+	switch {
+	case slices.Contains(options, selected):
+		p.selParam.SetSelected(selected)
+	case len(options) > 0:
+		p.selParam.SetSelected(options[0])
+	}
+	p.update()
+}
+
+// record adds the result of an iteration and redraws the graphs
+func (p *fitProgress) record(res *minimizer.FitResult) {
+	p.history.Record(res)
+	p.update()
+}
+
+func (p *fitProgress) update() {
+	p.convergence.SetData(historyPoints(p.history.Calls(), p.history.FVals()))
+	p.convergenceGraph.Refresh()
+	p.updateTrace()
+}
+
+// draws the trace of the selected parameter
+func (p *fitProgress) updateTrace() {
+	p.lock.RLock()
+	i := slices.Index(p.names, p.selParam.Selected)
+	id := -1
+	if i >= 0 {
+		id = p.free[i]
+	}
+	p.lock.RUnlock()
+
+	points := make(function.Points, 0)
+	if id >= 0 {
+		points = historyPoints(p.history.Calls(), p.history.Trace(id))
+		p.traceGraph.Config.Title = fmt.Sprintf("Trace of %s", p.selParam.Selected)
+	}
+	p.trace.SetData(points)
+	p.traceGraph.Refresh()
+}
+
+// points of values over the function calls, a result recorded in between the two reads is skipped
+func historyPoints(calls []int, values []float64) function.Points {
+	points := make(function.Points, min(len(calls), len(values)))
+	for i := range points {
+		points[i] = &function.Point{X: float64(calls[i]), Y: values[i]}
+	}
+	return points
+}
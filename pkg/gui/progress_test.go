@@ -0,0 +1,35 @@
+package gui
+
+import (
+	"physicsGUI/pkg/minimizer"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFitProgress(t *testing.T) {
+	TestSetup(t)
+	progress := newFitProgress()
+	progress.reset([]string{"a", "b", "c"}, []int{0, 2})
+	assert.Equal(t, []string{"a", "c"}, progress.selParam.Options)
+	assert.Equal(t, "a", progress.selParam.Selected)
+
+	progress.record(&minimizer.FitResult{Parameters: []float64{1, 2, 3}, FVal: 100, NFcn: 50})
+	progress.record(&minimizer.FitResult{Parameters: []float64{1.5, 2, 2}, FVal: 10, NFcn: 100})
+
+	convergence := progress.convergence.GetData()
+	assert.Len(t, convergence, 2)
+	assert.Equal(t, 100.0, convergence[1].X)
+	assert.Equal(t, 10.0, convergence[1].Y)
+
+	progress.selParam.SetSelected("c")
+	trace := progress.trace.GetData()
+	assert.Len(t, trace, 2)
+	assert.Equal(t, 3.0, trace[0].Y)
+	assert.Equal(t, 2.0, trace[1].Y)
+
+	// the selection is kept for the next fit of the same parameters
+	progress.reset([]string{"a", "b", "c"}, []int{0, 2})
+	assert.Equal(t, "c", progress.selParam.Selected)
+	assert.Empty(t, progress.convergence.GetData())
+}
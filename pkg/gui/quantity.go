@@ -0,0 +1,41 @@
+package gui
+
+import (
+	"log"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/physics"
+)
+
+// quantities the intensity graph can show
+func (s *sample) intensityQuantities() []graph.Quantity {
+	return []graph.Quantity{
+		{Name: "R", Label: "R"},
+		{Name: "R·q⁴", Label: "R·q⁴ [Å⁻⁴]", Transform: func(points function.Points) { points.Magie() }},
+		{Name: "R/R_F", Label: "R/R_F", Transform: s.divideByFresnel},
+	}
+}
+
+// divides the points by the Fresnel reflectivity of the substrate of the parameters of the sample
+func (s *sample) divideByFresnel(points function.Points) {
+	eden, err := s.session.Floats("eden")
+	if err != nil || len(eden) < 2 {
+		log.Println("Error while getting eden parameters for the Fresnel reflectivity:", err)
+		return
+	}
+	delta, err := s.session.Float("general", "deltaq")
+	if err != nil {
+		log.Println("Error while getting deltaq parameter:", err)
+		return
+	}
+
+	qz := make([]float64, len(points))
+	for i, p := range points {
+		qz[i] = p.X + delta
+	}
+	fresnel := physics.FresnelReflectivity(qz, eden[0]*physics.ELECTRON_RADIUS, eden[len(eden)-1]*physics.ELECTRON_RADIUS)
+	for i, p := range points {
+		p.Y /= fresnel[i]
+		p.Error /= fresnel[i]
+	}
+}
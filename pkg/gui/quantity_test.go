@@ -0,0 +1,23 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/physics"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDivideByFresnel(t *testing.T) {
+	TestSetup(t)
+
+	// total reflection below the critical edge of the substrate
+	fresnel := physics.FresnelReflectivity([]float64{0.001, 0.5}, 0, 0.334*physics.ELECTRON_RADIUS)
+	assert.InDelta(t, 1, fresnel[0], 1e-9)
+	assert.Less(t, fresnel[1], 1e-6)
+
+	points := function.Points{{X: 0.3, Y: 1e-7, Error: 1e-8}}
+	testSample.divideByFresnel(points)
+	assert.Greater(t, points[0].Y, 1e-7)
+	assert.InDelta(t, 0.1, points[0].Error/points[0].Y, 1e-12)
+}
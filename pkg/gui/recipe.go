@@ -0,0 +1,173 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"physicsGUI/pkg/physics"
+	"physicsGUI/pkg/recipe"
+	"physicsGUI/pkg/state"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// recipeMerits returns the penalty of the model with a figure of merit of a recipe step
+func recipeMerits(name string) (state.Objective, error) {
+	if name == "" {
+		name = physics.DefaultMerit
+	}
+	merit, ok := physics.Merits[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown figure of merit %q, use one of %s", name, strings.Join(physics.MeritNames(), ", "))
+	}
+	return modelObjective(merit), nil
+}
+
+// recipeDialog asks for a recipe file and runs its steps with the selected sample
+func recipeDialog() {
+	s := currentSample()
+	if s.session.Fit().Status.Active() {
+		dialog.ShowError(state.ErrFitRunning, MainWindow)
+		return
+	}
+	d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		if reader == nil {
+			return // user abort
+		}
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		r, err := recipe.Parse(content)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", reader.URI().Name(), err), MainWindow)
+			return
+		}
+		if r.Name == "" {
+			r.Name = strings.TrimSuffix(reader.URI().Name(), filepath.Ext(reader.URI().Name()))
+		}
+		runRecipe(s, r)
+	}, MainWindow)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml", ".json"}))
+	d.Show()
+}
+
+// runs the steps of the recipe in the background, finished steps are listed until the results are shown
+func runRecipe(s *sample, r *recipe.Recipe) {
+	ctx, cancel := context.WithCancel(context.Background())
+	status := widget.NewLabel(fmt.Sprintf("0 / %d steps", len(r.Steps)))
+	finished := container.NewVBox()
+	btnCancel := widget.NewButton("Cancel", cancel)
+	d := dialog.NewCustomWithoutButtons("Fit Recipe: "+r.Name, container.NewVBox(status, finished, btnCancel), MainWindow)
+	d.Resize(fyne.NewSize(500, 150))
+	d.Show()
+
+	// the steps are reported in the dialog and the results, not by the control panel
+	s.control.SetQuiet(true)
+	go func() {
+		defer cancel()
+		done := 0
+		stages, err := recipe.Run(ctx, s.session, r, recipeMerits, func(stage recipe.Stage) {
+			done++
+			status.SetText(fmt.Sprintf("%d / %d steps", done, len(r.Steps)))
+			finished.Add(widget.NewLabel(fmt.Sprintf("%s: %s, FVal %g after %d calls", stage.Step, stage.Status, stage.FVal, stage.Calls)))
+		})
+		s.control.SetQuiet(false)
+		d.Hide()
+
+		if err != nil && !errors.Is(err, context.Canceled) {
+			dialog.ShowError(err, MainWindow)
+		}
+		if len(stages) > 0 {
+			showRecipeResults(r.Name, s.session.Names(), stages)
+		}
+	}()
+}
+
+// showRecipeResults opens a window with a row per step and the values of the parameters after it
+func showRecipeResults(name string, names []string, stages []recipe.Stage) {
+	w := fyne.CurrentApp().NewWindow("Fit Recipe Results: " + name)
+
+	header := append([]string{"Step", "Engine", "Merit", "Status", "FVal", "Calls", "Seconds"}, names...)
+	table := widget.NewTable(
+		func() (int, int) { return len(stages), len(header) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			stage := stages[id.Row]
+			label := o.(*widget.Label)
+			switch id.Col {
+			case 0:
+				label.SetText(stage.Step)
+			case 1:
+				label.SetText(stage.Engine)
+			case 2:
+				label.SetText(stage.Merit)
+			case 3:
+				label.SetText(stage.Status)
+			case 4:
+				label.SetText(fmt.Sprintf("%g", stage.FVal))
+			case 5:
+				label.SetText(strconv.Itoa(stage.Calls))
+			case 6:
+				label.SetText(fmt.Sprintf("%.1f", stage.Seconds))
+			default:
+				param := names[id.Col-7]
+				label.SetText(fmt.Sprintf("%g ± %g", stage.Values[param], stage.Errors[param]))
+			}
+		})
+	table.ShowHeaderRow = true
+	table.CreateHeader = func() fyne.CanvasObject { return widget.NewLabel("") }
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		if id.Col >= 0 {
+			o.(*widget.Label).SetText(header[id.Col])
+		}
+	}
+	table.StickyColumnCount = 1
+	for i := range header {
+		table.SetColumnWidth(i, 130)
+	}
+
+	// errors of the failed steps
+	errorsText := make([]string, 0)
+	for _, stage := range stages {
+		if stage.Error != "" {
+			errorsText = append(errorsText, fmt.Sprintf("%s: %s", stage.Step, stage.Error))
+		}
+	}
+	lblErrors := widget.NewLabel(strings.Join(errorsText, "\n"))
+	lblErrors.Wrapping = fyne.TextWrapWord
+
+	btnJSON := widget.NewButton("Export JSON", func() {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if writer == nil {
+				return // user abort
+			}
+			defer writer.Close()
+			if err := recipe.WriteJSON(writer, stages); err != nil {
+				dialog.ShowError(err, w)
+			}
+		}, w)
+	})
+
+	w.SetContent(container.NewBorder(nil, container.NewVBox(lblErrors, container.NewHBox(btnJSON)), nil, nil, table))
+	w.Resize(fyne.NewSize(1000, 400))
+	w.Show()
+}
@@ -0,0 +1,35 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecipeMerits(t *testing.T) {
+	TestSetup(t)
+	values := testSample.session.Values()
+	datasets := []function.Points{syntheticDataset(t, values)}
+
+	// the default figure of merit is the penalty of the session
+	objective, err := recipeMerits("")
+	assert.NoError(t, err)
+	expected, err := modelPenalty(values, datasets)
+	assert.NoError(t, err)
+	penalty, err := objective(values, datasets)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, penalty)
+
+	// every figure of merit vanishes at the values the data was created with
+	for _, name := range []string{"chi2", "log"} {
+		objective, err := recipeMerits(name)
+		assert.NoError(t, err)
+		penalty, err := objective(values, datasets)
+		assert.NoError(t, err)
+		assert.InDelta(t, 0, penalty, 1e-12, name)
+	}
+
+	_, err = recipeMerits("unknown")
+	assert.Error(t, err)
+}
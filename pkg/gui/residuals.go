@@ -0,0 +1,52 @@
+package gui
+
+import (
+	"log"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/physics"
+)
+
+// updateResiduals shows the normalized residuals of every data track of the intensity graph against the intensity
+func (s *sample) updateResiduals(intensity function.Points) {
+	residualGraph, ok := s.graphMap["residuals"]
+	if !ok {
+		return
+	}
+
+	dataTracks := s.graphMap["intensity"].GetDataTracks()
+	functions := make(function.Functions, len(dataTracks))
+	for i, dataTrack := range dataTracks {
+		residuals, err := physics.Residuals(dataTrack.GetData(), intensity)
+		if err != nil {
+			log.Println("Error while calculating residuals:", err)
+			residuals = function.Points{}
+		}
+		functions[i] = function.NewFunction(residuals)
+	}
+
+	s.updateResidualStyles()
+	residualGraph.SetFunctions(functions)
+}
+
+// updateResidualStyles names, colors and hides the residuals like their data tracks
+func (s *sample) updateResidualStyles() {
+	residualGraph, ok := s.graphMap["residuals"]
+	if !ok {
+		return
+	}
+
+	residualGraph.Config.FunctionStyles = s.dataTrackStyles()
+	residualGraph.Refresh()
+}
+
+// styles of the data tracks of the intensity graph without the offsets, for graphs derived from the data tracks
+func (s *sample) dataTrackStyles() []graph.TrackStyle {
+	dataTracks := s.graphMap["intensity"].GetDataTracks()
+	styles := make([]graph.TrackStyle, len(dataTracks))
+	for i := range dataTracks {
+		styles[i] = s.graphMap["intensity"].DataTrackStyle(i)
+		styles[i].Offset = 0
+	}
+	return styles
+}
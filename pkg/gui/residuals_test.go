@@ -0,0 +1,37 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResiduals(t *testing.T) {
+	TestSetup(t)
+	dataTrack := function.NewFunction(function.Points{
+		{X: 0.02, Y: 0.5, Error: 0.1},
+		{X: 0.05, Y: 0.01, Error: 0.002},
+		{X: 0.1, Y: 1e-4, Error: 0},
+	})
+
+	// adding the track changes the qz axis and recalculates the intensity and the residuals
+	testSample.graphMap["intensity"].AddDataTrack(dataTrack)
+	defer testSample.graphMap["intensity"].RemoveDataTrack(dataTrack)
+	assert.Equal(t, []float64{0.02, 0.05, 0.1}, testSample.qzAxis())
+
+	functions := testSample.graphMap["residuals"].Config.Functions
+	assert.Len(t, functions, len(testSample.graphMap["intensity"].GetDataTracks()))
+	residuals := functions[len(functions)-1].GetData()
+
+	// the point without error is skipped
+	assert.Len(t, residuals, 2)
+	model, err := function.GetY(testSample.functionMap["intensity"].GetData(), 0.05)
+	assert.NoError(t, err)
+	assert.InDelta(t, (0.01-model)/0.002, residuals[1].Y, 1e-12)
+
+	// removing the last track resets the qz axis
+	testSample.graphMap["intensity"].RemoveDataTrack(dataTrack)
+	assert.Len(t, testSample.graphMap["residuals"].Config.Functions, len(testSample.graphMap["intensity"].GetDataTracks()))
+	assert.Len(t, testSample.qzAxis(), 500)
+}
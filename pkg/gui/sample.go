@@ -0,0 +1,369 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/graph"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/physics"
+	"physicsGUI/pkg/state"
+	"physicsGUI/pkg/trigger"
+	"physicsGUI/pkg/watch"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// sample is a tab of the main window, every sample has its own parameters, data sets, graphs, fit and recalculation
+// the functions marked for adaption in main.go are called for every sample
+type sample struct {
+	name string
+
+	params  *param.Registry
+	session *state.Session
+
+	functionMap map[string]*function.Function
+	graphMap    map[string]*graph.GraphCanvas
+	// metadata of the data tracks of the graphs and the watcher of their files, nil if files aren't watched
+	datasetsLock sync.Mutex
+	datasets     map[*function.Function]*datasetInfo
+	watcher      *watch.Watcher
+	// changed files, they are reloaded by the recalculation once no fit is running
+	reloads map[string]bool
+	// fit the data tracks after their files were reloaded
+	refit atomic.Bool
+
+	// spreadsheet view of the parameters
+	table   *param.Table
+	control *MinimizerControlPanel
+	status  *statusBar
+	data    *datasetPanel
+	recalc  *trigger.Scheduler
+	// serializes the calculations of the trigger, the UI and the API, they share the functions
+	calcLock sync.Mutex
+	content  fyne.CanvasObject
+
+	// qz axis of the intensity, the combined axis of the data tracks
+	qzLock sync.RWMutex
+	qz     []float64
+}
+
+// newSample creates the parameters, the graphs and the session of a sample, the recalculation isn't started yet
+func newSample(name string) (*sample, error) {
+	s := &sample{
+		name:        name,
+		params:      param.NewRegistry(),
+		session:     state.NewSession(modelPenalty),
+		functionMap: make(map[string]*function.Function),
+		graphMap:    make(map[string]*graph.GraphCanvas),
+		datasets:    make(map[*function.Function]*datasetInfo),
+		qz:          physics.QZAxisOrDefault(),
+	}
+
+	s.registerFunctions()
+	graphs := s.registerGraphs()
+	grid := s.registerParams()
+	if err := bindParameters(s.session, s.params, s.fitParameters()); err != nil {
+		return nil, err
+	}
+
+	s.table = param.NewTable(MainWindow, s.params)
+	s.control = NewMinimizerControlPanel(s.session)
+	s.status = newStatusBar()
+	s.data = newDatasetPanel(s)
+
+	// every change of a parameter recalculates the graphs in the background
+	s.recalc = trigger.NewScheduler(trigger.DefaultDelay, s.recalculate)
+	s.recalc.SetOnResult(s.status.show)
+	// reloads postponed by a fit follow at its end
+	s.control.OnIdle = func() {
+		if s.reloadsPending() {
+			s.recalc.Request()
+		}
+	}
+	s.session.Subscribe(func(e state.Event) {
+		if e.Kind == state.ParameterChanged {
+			s.recalc.Request()
+		}
+	})
+
+	s.content = s.layout(graphs, grid)
+	return s, nil
+}
+
+// recalculate is the calculation of the recalculation trigger, changed data files are reloaded first
+func (s *sample) recalculate() error {
+	s.applyReloads()
+	return s.calculate()
+}
+
+// calculate runs RecalculateData, one calculation at a time
+func (s *sample) calculate() error {
+	s.calcLock.Lock()
+	defer s.calcLock.Unlock()
+	return s.RecalculateData()
+}
+
+// close stops the fit, the recalculation and the file watcher of the sample
+func (s *sample) close() {
+	s.session.StopFit()
+	s.control.Close()
+	s.recalc.Stop()
+	if err := s.watchFiles(false); err != nil {
+		log.Println("Error while closing the file watcher:", err)
+	}
+}
+
+// syncDatasets mirrors the data tracks of the intensity graph and their metadata into the session
+// the metadata of removed data tracks is dropped
+func (s *sample) syncDatasets() {
+	loaded := make(map[*function.Function]bool)
+	for _, g := range s.graphMap {
+		for _, track := range g.GetDataTracks() {
+			loaded[track] = true
+		}
+	}
+	s.datasetsLock.Lock()
+	for track := range s.datasets {
+		if !loaded[track] {
+			delete(s.datasets, track)
+		}
+	}
+	if err := s.watchSources(); err != nil {
+		log.Println("Error while watching data files:", err)
+	}
+	s.datasetsLock.Unlock()
+
+	g := s.graphMap["intensity"]
+	tracks := g.GetDataTracks()
+	datasets := make([]state.Dataset, len(tracks))
+	for i, track := range tracks {
+		info := s.dataset(track)
+		datasets[i] = state.Dataset{
+			Name:     g.DataTrackStyle(i).Name,
+			Points:   track.GetData(),
+			Source:   info.Source,
+			Imported: info.Imported,
+			Excluded: info.Excluded,
+		}
+	}
+	s.session.SetDatasets(datasets)
+}
+
+// updateQZAxis uses the combined axis of the data tracks of the intensity graph as qz axis
+func (s *sample) updateQZAxis() {
+	tracks := s.graphMap["intensity"].GetDataTracks()
+	points := make([]function.Points, len(tracks))
+	for i, track := range tracks {
+		points[i] = track.GetData()
+	}
+	qz := physics.QZAxisOrDefault(points...)
+
+	s.qzLock.Lock()
+	defer s.qzLock.Unlock()
+	s.qz = qz
+}
+
+// qzAxis returns the qz axis of the intensity
+func (s *sample) qzAxis() []float64 {
+	s.qzLock.RLock()
+	defer s.qzLock.RUnlock()
+	return s.qz
+}
+
+// copyParameters sets the parameter values of a sample to the ones of another sample
+// with settings also the limits, the steps, the fit selection and the constraints are copied
+func copyParameters(from, to *sample, settings bool) error {
+	if from == to {
+		return errors.New("select two different samples")
+	}
+	if _, err := to.session.CopyParameters(from.session, settings); err != nil {
+		return err
+	}
+	if settings {
+		lines := make([]string, 0)
+		for _, c := range from.params.GetConstraints() {
+			lines = append(lines, c.String())
+		}
+		if err := to.params.SetConstraints(lines); err != nil {
+			return err
+		}
+		to.table.Refresh()
+	}
+	to.recalc.Request()
+	return nil
+}
+
+// sampleTabs shows the samples as tabs of the main window, the menus act on the selected sample
+type sampleTabs struct {
+	tabs    *container.DocTabs
+	samples map[*container.TabItem]*sample
+	created int
+}
+
+// samples of the main window
+var samples *sampleTabs
+
+func newSampleTabs() *sampleTabs {
+	t := &sampleTabs{samples: make(map[*container.TabItem]*sample)}
+	t.tabs = container.NewDocTabs()
+	t.tabs.CreateTab = func() *container.TabItem {
+		item, err := t.newTab()
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return nil
+		}
+		return item
+	}
+	// the last sample is never closed
+	t.tabs.CloseIntercept = func(item *container.TabItem) {
+		if s := t.samples[item]; s != nil && len(t.samples) > 1 {
+			t.remove(s)
+		}
+	}
+	return t
+}
+
+func (t *sampleTabs) Widget() fyne.CanvasObject {
+	return t.tabs
+}
+
+// creates a sample with the next free name and its tab item, the recalculation of the sample is started
+func (t *sampleTabs) newTab() (*container.TabItem, error) {
+	t.created++
+	s, err := newSample(fmt.Sprintf("Sample %d", t.created))
+	if err != nil {
+		return nil, err
+	}
+	item := container.NewTabItem(s.name, s.content)
+	t.samples[item] = s
+	s.recalc.Start()
+	s.recalc.Request()
+	return item, nil
+}
+
+// add creates a sample and selects its tab
+func (t *sampleTabs) add() (*sample, error) {
+	item, err := t.newTab()
+	if err != nil {
+		return nil, err
+	}
+	t.tabs.Append(item)
+	t.tabs.Select(item)
+	return t.samples[item], nil
+}
+
+// returns the tab item of a sample, nil if the sample isn't shown
+func (t *sampleTabs) item(s *sample) *container.TabItem {
+	for item, other := range t.samples {
+		if other == s {
+			return item
+		}
+	}
+	return nil
+}
+
+// remove closes a sample and its tab
+func (t *sampleTabs) remove(s *sample) {
+	item := t.item(s)
+	if item == nil {
+		return
+	}
+	delete(t.samples, item)
+	t.tabs.Remove(item)
+	s.close()
+}
+
+// rename changes the name of a sample and its tab
+func (t *sampleTabs) rename(s *sample, name string) {
+	s.name = name
+	if item := t.item(s); item != nil {
+		item.Text = name
+		t.tabs.Refresh()
+	}
+}
+
+// current returns the sample of the selected tab
+func (t *sampleTabs) current() *sample {
+	return t.samples[t.tabs.Selected()]
+}
+
+// list returns the samples in the order of their tabs
+func (t *sampleTabs) list() []*sample {
+	list := make([]*sample, 0, len(t.samples))
+	for _, item := range t.tabs.Items {
+		if s := t.samples[item]; s != nil {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// currentSample returns the sample the menus act on
+func currentSample() *sample {
+	return samples.current()
+}
+
+func createSampleMenu() *fyne.Menu {
+	mnNew := fyne.NewMenuItem("New Sample", func() {
+		if _, err := samples.add(); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	})
+	mnRename := fyne.NewMenuItem("Rename Sample", renameDialog)
+	mnCopy := fyne.NewMenuItem("Copy Parameters", copyParametersDialog)
+	return fyne.NewMenu("Samples", mnNew, mnRename, mnCopy)
+}
+
+// renameDialog asks for a new name of the selected sample
+func renameDialog() {
+	s := currentSample()
+	name := widget.NewEntry()
+	name.SetText(s.name)
+	dialog.ShowForm("Rename Sample", "Rename", "Cancel", []*widget.FormItem{widget.NewFormItem("Name", name)}, func(ok bool) {
+		if ok && name.Text != "" {
+			samples.rename(s, name.Text)
+		}
+	}, MainWindow)
+}
+
+// copyParametersDialog asks for the samples to copy the parameters from and to
+func copyParametersDialog() {
+	list := samples.list()
+	if len(list) < 2 {
+		dialog.ShowInformation("Copy Parameters", "Create a second sample to copy parameters between samples.", MainWindow)
+		return
+	}
+	// the names of the samples need not be unique
+	names := make([]string, len(list))
+	for i, s := range list {
+		names[i] = fmt.Sprintf("%d: %s", i+1, s.name)
+	}
+
+	from := widget.NewSelect(names, nil)
+	from.SetSelectedIndex(slices.Index(list, currentSample()))
+	to := widget.NewSelect(names, nil)
+	to.SetSelectedIndex((from.SelectedIndex() + 1) % len(list))
+	settings := widget.NewCheck("Limits, steps, fit selection and constraints", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("From", from),
+		widget.NewFormItem("To", to),
+		widget.NewFormItem("", settings),
+	}
+	dialog.ShowForm("Copy Parameters", "Copy", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := copyParameters(list[from.SelectedIndex()], list[to.SelectedIndex()], settings.Checked); err != nil {
+			dialog.ShowError(err, MainWindow)
+		}
+	}, MainWindow)
+}
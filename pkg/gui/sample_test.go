@@ -0,0 +1,62 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleTabs(t *testing.T) {
+	TestSetup(t)
+	tabs := newSampleTabs()
+	reference, err := tabs.add()
+	assert.NoError(t, err)
+	modified, err := tabs.add()
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		for _, s := range tabs.list() {
+			tabs.remove(s)
+		}
+	})
+	assert.Equal(t, []*sample{reference, modified}, tabs.list())
+	assert.Equal(t, modified, tabs.current())
+	assert.Equal(t, "Sample 2", modified.name)
+
+	// the recalculation of every sample runs in the background
+	assert.Eventually(t, func() bool {
+		return len(reference.functionMap["intensity"].GetData()) > 0 && len(modified.functionMap["intensity"].GetData()) > 0
+	}, time.Second, time.Millisecond)
+
+	// parameters, constraints and data of the samples are independent
+	assert.NoError(t, reference.session.SetFloat("thick", "Thickness 1", 20))
+	value, _ := reference.params.GetFloat("thick", "Thickness 1")
+	assert.Equal(t, 20.0, value)
+	value, _ = modified.params.GetFloat("thick", "Thickness 1")
+	assert.Equal(t, 14.2657, value)
+	assert.NoError(t, reference.params.SetConstraints([]string{"Thickness 2 = 2*Thickness 1"}))
+	assert.Empty(t, modified.params.GetConstraints())
+	reference.graphMap["intensity"].AddDataTrack(function.NewFunction(function.Points{{X: 0.02, Y: 0.5, Error: 0.1}}))
+	assert.Equal(t, []float64{0.02}, reference.qzAxis())
+	assert.Len(t, modified.qzAxis(), 500)
+	assert.Empty(t, modified.session.Datasets())
+
+	// only the values are copied without the settings
+	assert.Error(t, copyParameters(reference, reference, false))
+	assert.NoError(t, copyParameters(reference, modified, false))
+	value, _ = modified.session.Float("thick", "Thickness 1")
+	assert.Equal(t, 20.0, value)
+	assert.Empty(t, modified.params.GetConstraints())
+	assert.NoError(t, copyParameters(reference, modified, true))
+	assert.Len(t, modified.params.GetConstraints(), 1)
+
+	tabs.rename(reference, "Reference")
+	assert.Equal(t, "Reference", tabs.item(reference).Text)
+
+	// the last sample is never closed
+	tabs.tabs.CloseIntercept(tabs.item(reference))
+	assert.Equal(t, []*sample{modified}, tabs.list())
+	tabs.tabs.CloseIntercept(tabs.item(modified))
+	assert.Equal(t, []*sample{modified}, tabs.list())
+}
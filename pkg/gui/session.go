@@ -0,0 +1,124 @@
+package gui
+
+import (
+	"fmt"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/state"
+	"sync"
+
+	"fyne.io/fyne/v2/data/binding"
+)
+
+// bindParameters adds the parameters of the registry to the session in their order and binds their widgets to it
+// the widgets only show the session: edits are written into the session and changes of the session are shown by the widgets
+func bindParameters(s *state.Session, registry *param.Registry, parameters param.Parameters[float64]) error {
+	for i, p := range parameters {
+		if p == nil {
+			return fmt.Errorf("parameter %d is nil", i)
+		}
+		if err := bindParameter(s, registry, p); err != nil {
+			return err
+		}
+	}
+	// the constraints are evaluated on the values of the session, the widgets of derived parameters follow it
+	return registry.BindConstraints(parameters, func(plan *param.ConstraintPlan) error {
+		s.SetConstraints(plan)
+		return s.ApplyConstraints()
+	})
+}
+
+// binds the value, the limits, the step, the fit error and the use for fit checkbox of a parameter to the session
+func bindParameter(s *state.Session, registry *param.Registry, p *param.Parameter[float64]) error {
+	group, name := registry.GetFloatGroupName(p), registry.GetFloatLabel(p)
+	relative := func(key string) *param.Parameter[float64] {
+		if key == "" {
+			return p
+		}
+		return p.GetRelative(key)
+	}
+	value := func(key string) (float64, bool) {
+		r := relative(key)
+		if r == nil {
+			return 0, false
+		}
+		v, err := r.Get()
+		return v, err == nil
+	}
+
+	initial := state.Parameter{Group: group, Name: name, Fit: p.IsChecked()}
+	initial.Value, _ = value("")
+	initial.Step, _ = value("step")
+	order, _ := value("order")
+	initial.Order = int(order)
+	initial.Error, _ = value("error")
+	minimum, hasMin := value("min")
+	maximum, hasMax := value("max")
+	initial.Min, initial.Max, initial.Limited = minimum, maximum, hasMin && hasMax
+	if err := s.AddParameter(initial); err != nil {
+		return err
+	}
+
+	// widgets -> session, invalid input stays in the entry until it is corrected
+	fields := map[string]func(sp *state.Parameter, v float64){
+		"":      func(sp *state.Parameter, v float64) { sp.Value = v },
+		"min":   func(sp *state.Parameter, v float64) { sp.Min = v },
+		"max":   func(sp *state.Parameter, v float64) { sp.Max = v },
+		"step":  func(sp *state.Parameter, v float64) { sp.Step = v },
+		"order": func(sp *state.Parameter, v float64) { sp.Order = int(v) },
+	}
+	for key, set := range fields {
+		r := relative(key)
+		if r == nil {
+			continue
+		}
+		r.Binding().AddListener(binding.NewDataListener(func() {
+			if v, ok := value(key); ok {
+				_ = s.UpdateParameter(group, name, func(sp *state.Parameter) { set(sp, v) })
+			}
+		}))
+	}
+	if checked := p.CheckBinding(); checked != nil {
+		checked.AddListener(binding.NewDataListener(func() {
+			fit := p.IsChecked()
+			_ = s.UpdateParameter(group, name, func(sp *state.Parameter) { sp.Fit = fit })
+		}))
+	}
+
+	// session -> widgets, only fields changed in the session are set, so pending edits of other fields are kept
+	// and the listeners above don't write back
+	var lock sync.Mutex
+	shown := initial
+	show := func(key string, v, last float64) {
+		r := relative(key)
+		if r == nil || v == last {
+			return
+		}
+		if current, ok := value(key); ok && current == v {
+			return
+		}
+		_ = r.Set(v)
+	}
+	s.Subscribe(func(e state.Event) {
+		if e.Kind != state.ParameterChanged || e.Group != group || e.Name != name {
+			return
+		}
+		sp, err := s.Parameter(group, name)
+		if err != nil {
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		last := shown
+		shown = sp
+		show("", sp.Value, last.Value)
+		show("min", sp.Min, last.Min)
+		show("max", sp.Max, last.Max)
+		show("step", sp.Step, last.Step)
+		show("error", sp.Error, last.Error)
+		show("order", float64(sp.Order), float64(last.Order))
+		if p.CheckBinding() != nil && sp.Fit != last.Fit && p.IsChecked() != sp.Fit {
+			p.SetCheck(sp.Fit)
+		}
+	})
+	return nil
+}
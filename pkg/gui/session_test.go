@@ -0,0 +1,128 @@
+package gui
+
+import (
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/gui/param"
+	"physicsGUI/pkg/state"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindParameters(t *testing.T) {
+	TestSetup(t)
+	assert.Equal(t, []string{"Eden a", "Eden 1", "Eden 2", "Eden b", "Thickness 1", "Thickness 2", "Roughness a/1", "Roughness 1/2", "Roughness 2/b", "deltaq", "background", "scaling"}, testSample.session.Names())
+
+	p := testSample.params.GetFloatGroup("thick").GetParam("Thickness 1")
+	initial, err := testSample.session.Parameter("thick", "Thickness 1")
+	assert.NoError(t, err)
+	assert.Equal(t, 14.2657, initial.Value)
+	assert.True(t, initial.Limited)
+	assert.False(t, initial.Fit)
+	defer func() {
+		assert.NoError(t, testSample.session.UpdateParameter("thick", "Thickness 1", func(sp *state.Parameter) { *sp = initial }))
+	}()
+
+	// changes of the session are shown by the widgets
+	assert.NoError(t, testSample.session.UpdateParameter("thick", "Thickness 1", func(sp *state.Parameter) {
+		sp.Value, sp.Max, sp.Fit, sp.Error = 20, 50, true, 0.5
+	}))
+	value, _ := p.Get()
+	maximum, _ := p.GetRelative("max").Get()
+	fitError, _ := p.GetRelative("error").Get()
+	assert.Equal(t, 20.0, value)
+	assert.Equal(t, 50.0, maximum)
+	assert.Equal(t, 0.5, fitError)
+	assert.True(t, p.IsChecked())
+
+	// edits of the widgets are written into the session
+	assert.NoError(t, p.Set(21))
+	assert.NoError(t, p.GetRelative("min").Set(1))
+	p.SetCheck(false)
+	assert.Eventually(t, func() bool {
+		sp, _ := testSample.session.Parameter("thick", "Thickness 1")
+		return sp.Value == 21 && sp.Min == 1 && !sp.Fit
+	}, time.Second, time.Millisecond)
+
+	// invalid input is not written
+	assert.NoError(t, p.Binding().Set("2x"))
+	time.Sleep(10 * time.Millisecond)
+	v, _ := testSample.session.Float("thick", "Thickness 1")
+	assert.Equal(t, 21.0, v)
+}
+
+func TestSessionConstraints(t *testing.T) {
+	TestSetup(t)
+	before := testSample.session.Values()
+	assert.NoError(t, testSample.params.SetConstraints([]string{"Thickness 2 = 2*Thickness 1"}))
+	defer func() {
+		assert.NoError(t, testSample.params.SetConstraints(nil))
+		assert.NoError(t, testSample.session.SetValues(before))
+	}()
+	// the derived value is set in the session and shown by the widget
+	v, _ := testSample.session.Float("thick", "Thickness 2")
+	assert.Equal(t, 2*before[4], v)
+	shown, _ := testSample.params.GetFloat("thick", "Thickness 2")
+	assert.Equal(t, 2*before[4], shown)
+
+	// the recalculation uses the derived value of the current session values
+	assert.NoError(t, testSample.session.SetFloat("thick", "Thickness 1", 12))
+	assert.NoError(t, testSample.RecalculateData())
+	v, _ = testSample.session.Float("thick", "Thickness 2")
+	assert.Equal(t, 24.0, v)
+	assert.NoError(t, testSample.session.UpdateParameter("thick", "Thickness 2", func(sp *state.Parameter) { sp.Fit = true }))
+	defer func() {
+		assert.NoError(t, testSample.session.UpdateParameter("thick", "Thickness 2", func(sp *state.Parameter) { sp.Fit = false }))
+	}()
+
+	// derived parameters are never fitted and follow the constraint in every penalty call
+	problem, err := testSample.session.FitProblem()
+	assert.NoError(t, err)
+	assert.True(t, problem.Fixed[5])
+	values := testSample.session.Values()
+	changed := append([]float64{}, values...)
+	changed[5] = 100
+	assert.Equal(t, problem.Function(values), problem.Function(changed))
+}
+
+func TestConstraintPlan(t *testing.T) {
+	TestSetup(t)
+	s, err := newSample("Constraints")
+	assert.NoError(t, err)
+	var plan *param.ConstraintPlan
+	params := s.fitParameters()
+	assert.NoError(t, s.params.BindConstraints(params, func(p *param.ConstraintPlan) error {
+		plan = p
+		return p.Apply(s.session.Values())
+	}))
+
+	// the plan follows the constraints and evaluates them on plain values
+	assert.NoError(t, s.params.SetConstraints([]string{"Thickness 2 = 2*Thickness 1"}))
+	values := s.session.Values()
+	values[4] = 3
+	assert.NoError(t, plan.Apply(values))
+	assert.Equal(t, 6.0, values[5])
+	assert.True(t, plan.Derived(5))
+	assert.False(t, plan.Derived(4))
+
+	// results which are not a number are errors
+	assert.Error(t, s.params.SetConstraints([]string{"Thickness 2 = sqrt(Thickness 1 - 1000)"}))
+	assert.Error(t, plan.Apply(values))
+
+	// constraints may only reference the bound parameters
+	assert.Error(t, s.params.BindConstraints(params[5:], func(*param.ConstraintPlan) error { return nil }))
+}
+
+func TestSyncDatasets(t *testing.T) {
+	TestSetup(t)
+	dataTrack := function.NewFunction(function.Points{{X: 0.02, Y: 0.5, Error: 0.1}})
+	testSample.graphMap["intensity"].AddDataTrack(dataTrack)
+	defer testSample.graphMap["intensity"].RemoveDataTrack(dataTrack)
+
+	datasets := testSample.session.Datasets()
+	assert.Len(t, datasets, len(testSample.graphMap["intensity"].GetDataTracks()))
+	last := datasets[len(datasets)-1]
+	assert.Equal(t, dataTrack.GetData(), last.Points)
+	assert.Equal(t, testSample.graphMap["intensity"].DataTrackStyle(len(datasets)-1).Name, last.Name)
+}
@@ -0,0 +1,47 @@
+package gui
+
+import (
+	"fmt"
+	"physicsGUI/pkg/trigger"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// statusBar shows the error or the computation time of the last recalculation at the bottom of the main window
+type statusBar struct {
+	icon  *widget.Icon
+	label *widget.Label
+}
+
+func newStatusBar() *statusBar {
+	s := &statusBar{
+		icon:  widget.NewIcon(nil),
+		label: widget.NewLabel("Ready"),
+	}
+	s.icon.Hide()
+	return s
+}
+
+func (s *statusBar) Widget() fyne.CanvasObject {
+	return container.NewHBox(s.icon, s.label)
+}
+
+// show the result of a recalculation, it is called by the trigger outside the UI thread
+func (s *statusBar) show(r trigger.Result) {
+	if r.Err != nil {
+		s.icon.SetResource(theme.ErrorIcon())
+		s.icon.Show()
+		s.label.SetText(fmt.Sprintf("Recalculation failed: %v", r.Err))
+		return
+	}
+	s.icon.Hide()
+	text := fmt.Sprintf("Recalculated in %v", r.Duration.Round(10*time.Microsecond))
+	if r.Requests > 1 {
+		text += fmt.Sprintf(" (%d changes)", r.Requests)
+	}
+	s.label.SetText(text)
+}
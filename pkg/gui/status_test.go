@@ -0,0 +1,23 @@
+package gui
+
+import (
+	"errors"
+	"physicsGUI/pkg/trigger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusBar(t *testing.T) {
+	status := newStatusBar()
+	assert.Equal(t, "Ready", status.label.Text)
+
+	status.show(trigger.Result{Err: errors.New("no data"), Duration: time.Millisecond, Requests: 1})
+	assert.Equal(t, "Recalculation failed: no data", status.label.Text)
+	assert.True(t, status.icon.Visible())
+
+	status.show(trigger.Result{Duration: 1234567 * time.Nanosecond, Requests: 3})
+	assert.Equal(t, "Recalculated in 1.23ms (3 changes)", status.label.Text)
+	assert.False(t, status.icon.Visible())
+}
@@ -13,12 +13,35 @@ type ConfigInformation struct {
 	Plot                      []PlotInformation      `json:"plot" xml:"plot"`
 	ParameterVersionIndicator []byte                 `json:"parameter_version" xml:"parameter_version"`
 	Parameter                 []ParameterInformation `json:"parameter" xml:"parameter"`
+	Constraints               []string               `json:"constraints" xml:"constraints"`
 }
 
 type FunctionInformation struct {
-	Points function.Points `json:"points" xml:"points"`
-	Scope  function.Scope  `json:"scope" xml:"scope"`
+	Points  function.Points       `json:"points" xml:"points"`
+	Scope   function.Scope        `json:"scope" xml:"scope"`
+	Style   TrackStyleInformation `json:"style" xml:"style"`
+	Dataset DatasetInformation    `json:"dataset" xml:"dataset"`
 }
+
+// TrackStyleInformation is the name and the look of a data track, empty fields use the defaults
+type TrackStyleInformation struct {
+	Name   string  `json:"name" xml:"name"`
+	Color  string  `json:"color,omitempty" xml:"color,omitempty"`
+	Marker string  `json:"marker,omitempty" xml:"marker,omitempty"`
+	Line   string  `json:"line,omitempty" xml:"line,omitempty"`
+	Hidden bool    `json:"hidden,omitempty" xml:"hidden,omitempty"`
+	Offset float64 `json:"offset,omitempty" xml:"offset,omitempty"`
+}
+
+// DatasetInformation is the origin of a data track and its use in fits, empty fields are unknown
+type DatasetInformation struct {
+	Source string `json:"source,omitempty" xml:"source,omitempty"`
+	// time of the import in RFC 3339 format
+	Imported string `json:"imported,omitempty" xml:"imported,omitempty"`
+	Notes    string `json:"notes,omitempty" xml:"notes,omitempty"`
+	Excluded bool   `json:"excluded,omitempty" xml:"excluded,omitempty"`
+}
+
 type PlotInformation struct {
 	Name       string                `json:"name" xml:"name"`
 	DataTracks []FunctionInformation `json:"data_tracks" xml:"data_tracks"`
@@ -33,6 +56,8 @@ type ParameterInformation struct {
 	IsLimited    bool   `json:"limited" xml:"limited"`
 	FieldMinimum string `json:"minimum" xml:"minimum"`
 	FieldMaximum string `json:"maximum" xml:"maximum"`
+	FieldStep    string `json:"step,omitempty" xml:"step,omitempty"`
+	FitOrder     int    `json:"order,omitempty" xml:"order,omitempty"`
 }
 
 func DecodeJSONFromBytes(data []byte) (*ConfigInformation, error) {
@@ -0,0 +1,41 @@
+package io
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadProject reads a project file saved by the GUI, decoded by its extension like File > Load
+func ReadProject(path string) (*ConfigInformation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return DecodeXMLFromBytes(data)
+	case ".json":
+		return DecodeJSONFromBytes(data)
+	default:
+		return DecodeGOBFromBytes(data)
+	}
+}
+
+// WriteProject writes a project file, encoded by its extension like File > Save
+func WriteProject(path string, config *ConfigInformation) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		data, err = EncodeXMLToBytes(config)
+	case ".json":
+		data, err = EncodeJSONToBytes(config)
+	default:
+		data, err = EncodeGOBToBytes(config)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,109 @@
+package minimizer
+
+import (
+	"math"
+	"slices"
+	"sync/atomic"
+)
+
+// asyncEngine runs one of the minimizers working on an AsyncMinimiserProblem
+// the free parameters are scaled by their step size, so the fixed deltas of the minimizers fit every parameter
+type asyncEngine struct {
+	minimizer Minimizer[float64]
+	problem   *FitProblem
+	free      []int
+	async     *AsyncMinimiserProblem[float64]
+	calls     atomic.Int64
+	fval      float64
+}
+
+// AsyncEngineFactory creates a factory of fit engines using one of the asynchronous minimizers,
+// f.e. FloatMinimizerHC or FloatMinimizerPLLS
+func AsyncEngineFactory(minimizer Minimizer[float64]) EngineFactory {
+	return func(problem *FitProblem) (FitEngine, error) {
+		return newAsyncEngine(minimizer, problem), nil
+	}
+}
+
+// StatefulEngineFactory creates a factory of fit engines using asynchronous minimizers which keep a state,
+// f.e. a population, so every engine needs its own minimizer
+func StatefulEngineFactory(create func() Minimizer[float64]) EngineFactory {
+	return func(problem *FitProblem) (FitEngine, error) {
+		return newAsyncEngine(create(), problem), nil
+	}
+}
+
+func newAsyncEngine(minimizer Minimizer[float64], problem *FitProblem) *asyncEngine {
+	e := &asyncEngine{
+		minimizer: minimizer,
+		problem:   problem,
+		free:      problem.Free(),
+	}
+
+	x0 := make([]float64, len(e.free))
+	minima := make([]float64, len(e.free))
+	maxima := make([]float64, len(e.free))
+	for i, id := range e.free {
+		step := problem.Steps[id]
+		minima[i] = (problem.Minima[id] - problem.Values[id]) / step
+		maxima[i] = (problem.Maxima[id] - problem.Values[id]) / step
+	}
+
+	e.async = NewProblem(x0, minima, maxima, e.evaluate, &MinimiserConfig{
+		LoopCount:     0,
+		ParallelReads: true,
+	})
+	e.fval = e.evaluate(x0)
+
+	return e
+}
+
+// maps the scaled free parameters to all parameters
+func (e *asyncEngine) parameters(scaled []float64) []float64 {
+	par := slices.Clone(e.problem.Values)
+	for i, id := range e.free {
+		par[id] = e.problem.Values[id] + scaled[i]*e.problem.Steps[id]
+	}
+	return par
+}
+
+// the minimizers call this concurrently
+func (e *asyncEngine) evaluate(scaled []float64) float64 {
+	e.calls.Add(1)
+	return e.problem.Function(e.parameters(scaled))
+}
+
+func (e *asyncEngine) Iterate(maxCalls int) (*FitResult, error) {
+	// every loop of the local minimizers evaluates both neighbours of each free parameter
+	callsPerLoop := 2 * len(e.free)
+	if r, ok := e.minimizer.(loopCostReporter); ok {
+		callsPerLoop = r.callsPerLoop(len(e.free))
+	}
+	e.async.lock.Lock()
+	e.async.config.LoopCount = max(1, maxCalls/callsPerLoop)
+	e.async.lock.Unlock()
+
+	e.minimizer.Minimize(e.async)
+
+	e.async.lock.RLock()
+	scaled := slices.Clone(e.async.parameter)
+	e.async.lock.RUnlock()
+
+	last := e.fval
+	e.fval = e.evaluate(scaled)
+
+	// global minimizers may keep searching without improving the best value
+	converged := e.fval >= last
+	if r, ok := e.minimizer.(convergenceReporter); ok {
+		converged = r.converged()
+	}
+
+	return &FitResult{
+		Parameters: e.parameters(scaled),
+		Errors:     make([]float64, len(e.problem.Values)),
+		FVal:       e.fval,
+		EDM:        math.NaN(),
+		NFcn:       int(e.calls.Load()),
+		Converged:  converged,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package minimizer
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// DEConfig configures the differential evolution
+type DEConfig struct {
+	// number of members of the population, 0 uses 10 members per parameter
+	PopulationSize int
+	// differential weight F in [0, 2], 0 uses 0.7
+	Weight float64
+	// crossover probability CR in [0, 1], 0 uses 0.9
+	Crossover float64
+	// the population converged if the spread of its errors is below the tolerance, 0 uses 1e-8
+	Tolerance float64
+	// seed of the random number generator, the same seed gives the same result
+	Seed uint64
+}
+
+type differentialEvolution struct {
+	config DEConfig
+	rng    *rand.Rand
+
+	population [][]float64
+	errors     []float64
+	lower      []float64
+	upper      []float64
+	done       bool
+}
+
+// NewDifferentialEvolution creates a global minimizer evolving a population of parameter sets (DE/rand/1/bin)
+// every loop is one generation, the population is evaluated in parallel
+// the minimizer keeps its population, so create one per problem
+func NewDifferentialEvolution(config DEConfig) Minimizer[float64] {
+	if config.Weight == 0 {
+		config.Weight = 0.7
+	}
+	if config.Crossover == 0 {
+		config.Crossover = 0.9
+	}
+	if config.Tolerance == 0 {
+		config.Tolerance = 1e-8
+	}
+	return &differentialEvolution{
+		config: config,
+		rng:    rand.New(rand.NewPCG(config.Seed, config.Seed^0x9e3779b97f4a7c15)),
+	}
+}
+
+func (d *differentialEvolution) populationSize(parameterCount int) int {
+	if d.config.PopulationSize > 0 {
+		return max(d.config.PopulationSize, 4)
+	}
+	return max(10*parameterCount, 4)
+}
+
+func (d *differentialEvolution) callsPerLoop(parameterCount int) int {
+	return d.populationSize(parameterCount)
+}
+
+func (d *differentialEvolution) converged() bool {
+	return d.done
+}
+
+// creates the initial population, the start value is always a member
+func (d *differentialEvolution) initialize(problem *AsyncMinimiserProblem[float64]) {
+	problem.lock.RLock()
+	x0 := slices.Clone(problem.parameter)
+	d.lower, d.upper = searchBounds(x0, problem.minima, problem.maxima)
+	problem.lock.RUnlock()
+
+	d.population = make([][]float64, d.populationSize(len(x0)))
+	d.population[0] = x0
+	for i := 1; i < len(d.population); i++ {
+		member := make([]float64, len(x0))
+		for j := range member {
+			member[j] = d.lower[j] + d.rng.Float64()*(d.upper[j]-d.lower[j])
+		}
+		d.population[i] = member
+	}
+	d.errors = evaluateParallel(problem, d.population)
+}
+
+func (d *differentialEvolution) best() int {
+	best := 0
+	for i, e := range d.errors {
+		if e < d.errors[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func (d *differentialEvolution) Minimize(problem *AsyncMinimiserProblem[float64]) {
+	if d.population == nil {
+		d.initialize(problem)
+	}
+
+	size := len(d.population)
+	for shouldContinue(problem) && !d.done {
+		// create the trial members, all random numbers are drawn here so the result only depends on the seed
+		trials := make([][]float64, size)
+		for i, member := range d.population {
+			a, b, c := d.pick(i, size)
+			forced := d.rng.IntN(len(member))
+			trial := slices.Clone(member)
+			for j := range trial {
+				if j == forced || d.rng.Float64() < d.config.Crossover {
+					v := d.population[a][j] + d.config.Weight*(d.population[b][j]-d.population[c][j])
+					trial[j] = reflectInto(v, d.lower[j], d.upper[j])
+				}
+			}
+			trials[i] = trial
+		}
+
+		// keep the better of member and trial
+		trialErrors := evaluateParallel(problem, trials)
+		for i := range trials {
+			if trialErrors[i] <= d.errors[i] {
+				d.population[i] = trials[i]
+				d.errors[i] = trialErrors[i]
+			}
+		}
+
+		best := d.best()
+		spread := slices.Max(d.errors) - d.errors[best]
+		d.done = spread <= d.config.Tolerance*(math.Abs(d.errors[best])+d.config.Tolerance)
+		publishBest(problem, slices.Clone(d.population[best]), d.done)
+	}
+}
+
+// picks three different members which are not the member i
+func (d *differentialEvolution) pick(i, size int) (int, int, int) {
+	a := d.rng.IntN(size)
+	for a == i {
+		a = d.rng.IntN(size)
+	}
+	b := d.rng.IntN(size)
+	for b == i || b == a {
+		b = d.rng.IntN(size)
+	}
+	c := d.rng.IntN(size)
+	for c == i || c == a || c == b {
+		c = d.rng.IntN(size)
+	}
+	return a, b, c
+}
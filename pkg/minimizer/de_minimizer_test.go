@@ -0,0 +1,93 @@
+package minimizer
+
+import (
+	"math"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// many local minima, the global minimum is 0 at the origin
+func rastrigin(in []float64) float64 {
+	sum := 10 * float64(len(in))
+	for _, x := range in {
+		sum += x*x - 10*math.Cos(2*math.Pi*x)
+	}
+	return sum
+}
+
+func TestDifferentialEvolutionGlobalMinimum(t *testing.T) {
+	problem := NewProblem([]float64{3.2, -2.9}, []float64{-5.12, -5.12}, []float64{5.12, 5.12}, rastrigin, &MinimiserConfig{
+		LoopCount:     1000,
+		ParallelReads: true,
+	})
+
+	NewDifferentialEvolution(DEConfig{Seed: 1}).Minimize(problem)
+
+	res, err := problem.GetCurrentParameters()
+	if err != nil {
+		t.Fatalf("Failed to get parameters from problem after minimizer should have finished: %s", err.Error())
+	}
+	if math.Abs(res[0]) > 1e-3 || math.Abs(res[1]) > 1e-3 {
+		t.Errorf("Minimizer failed to find the global minimum expected {0,0} but got {%f,%f}", res[0], res[1])
+	}
+}
+
+func TestDifferentialEvolutionSeed(t *testing.T) {
+	run := func(seed uint64) []float64 {
+		problem := NewProblem([]float64{3.2, -2.9}, []float64{-5.12, -5.12}, []float64{5.12, 5.12}, rastrigin, &MinimiserConfig{
+			LoopCount:     20,
+			ParallelReads: true,
+		})
+		NewDifferentialEvolution(DEConfig{Seed: seed}).Minimize(problem)
+		res, _ := problem.GetCurrentParameters()
+		return res
+	}
+
+	if a, b := run(7), run(7); !slices.Equal(a, b) {
+		t.Errorf("expected the same result for the same seed but got %v and %v", a, b)
+	}
+}
+
+func TestDifferentialEvolutionPause(t *testing.T) {
+	var calls atomic.Int64
+	slow := func(in []float64) float64 {
+		calls.Add(1)
+		time.Sleep(100 * time.Microsecond)
+		return rastrigin(in)
+	}
+	problem := NewProblem([]float64{3.2, -2.9}, []float64{-5.12, -5.12}, []float64{5.12, 5.12}, slow, &MinimiserConfig{
+		LoopCount:     1e6,
+		ParallelReads: true,
+	})
+
+	done := make(chan bool)
+	go func() {
+		NewDifferentialEvolution(DEConfig{Seed: 1, Tolerance: 1e-300}).Minimize(problem)
+		done <- true
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if err := problem.Pause(); err != nil {
+		t.Fatalf("Failed to pause: %s", err)
+	}
+	// a running generation may still finish its evaluations
+	time.Sleep(20 * time.Millisecond)
+	paused := calls.Load()
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != paused {
+		t.Errorf("minimizer kept evaluating while paused")
+	}
+
+	// stop the minimizer after resuming
+	problem.config.LoopCount = 1
+	if err := problem.Resume(); err != nil {
+		t.Fatalf("Failed to resume: %s", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("minimizer did not finish after resume")
+	}
+}
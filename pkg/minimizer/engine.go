@@ -0,0 +1,278 @@
+package minimizer
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// names of the built-in fit engines
+const (
+	EngineMigrad         = "Minuit Migrad"
+	EngineHC             = "Hill Climbing"
+	EngineStagedHC       = "Staged Hill Climbing"
+	EnginePLLS           = "Parallel Linear Local Search"
+	EngineStagedHCMigrad = "Staged Hill Climbing + Migrad"
+	EngineDE             = "Differential Evolution"
+	EngineSA             = "Simulated Annealing"
+	EngineDEMigrad       = "Differential Evolution + Migrad"
+	EngineSAMigrad       = "Simulated Annealing + Migrad"
+	DefaultEngine        = EngineMigrad
+	defaultParameterStep = 0.1
+)
+
+var (
+	ErrUnknownEngine = errors.New("unknown fit engine")
+	ErrNoFreeParam   = errors.New("no parameter(s) selected to be minimized")
+)
+
+// FitProblem describes a fit independently of the engine used to solve it
+// every slice holds one entry per parameter, the function is always called with all parameters
+type FitProblem struct {
+	Function func(parameter []float64) float64
+	Values   []float64
+	// initial step size, roughly the expected uncertainty of a parameter
+	Steps []float64
+	// limits of the parameters, use -Inf/+Inf for unlimited parameters
+	Minima []float64
+	Maxima []float64
+	// fixed parameters are never changed by the engine
+	Fixed []bool
+	// settings of the engines from the FitConfig of a job
+	strategy     Strategy
+	seed         uint64
+	edmTolerance float64
+	maxCalls     int
+}
+
+// NewFitProblem creates a problem with all parameters free, unlimited and the default step size
+func NewFitProblem(function func(parameter []float64) float64, values []float64) *FitProblem {
+	p := &FitProblem{
+		Function: function,
+		Values:   slices.Clone(values),
+		Steps:    make([]float64, len(values)),
+		Minima:   make([]float64, len(values)),
+		Maxima:   make([]float64, len(values)),
+		Fixed:    make([]bool, len(values)),
+	}
+	for i := range values {
+		p.Steps[i] = defaultParameterStep
+		p.Minima[i] = math.Inf(-1)
+		p.Maxima[i] = math.Inf(1)
+	}
+	return p
+}
+
+// Free returns the indices of the parameters the engine may change
+func (p *FitProblem) Free() []int {
+	free := make([]int, 0, len(p.Values))
+	for i, fixed := range p.Fixed {
+		if !fixed {
+			free = append(free, i)
+		}
+	}
+	return free
+}
+
+// WithValues returns a copy of the problem starting at other values
+func (p *FitProblem) WithValues(values []float64) *FitProblem {
+	c := *p
+	c.Values = slices.Clone(values)
+	return &c
+}
+
+// returns a copy of the problem with the strategy, the seed, the EDM tolerance and the call limit of the config
+func (p *FitProblem) withConfig(config FitConfig) *FitProblem {
+	c := *p
+	c.strategy, c.seed, c.edmTolerance, c.maxCalls = config.Strategy, config.Seed, config.EDMTolerance, config.MaxCalls
+	return &c
+}
+
+func (p *FitProblem) validate() error {
+	if p.Function == nil {
+		return errors.New("fit problem has no function")
+	}
+	n := len(p.Values)
+	if len(p.Steps) != n || len(p.Minima) != n || len(p.Maxima) != n || len(p.Fixed) != n {
+		return errors.New("fit problem has slices of different length")
+	}
+	for i := range p.Values {
+		if p.Minima[i] > p.Maxima[i] {
+			return fmt.Errorf("parameter %d: minimum %g is greater than maximum %g", i, p.Minima[i], p.Maxima[i])
+		}
+		if !p.Fixed[i] && p.Steps[i] <= 0 {
+			return fmt.Errorf("parameter %d: step size must be positive", i)
+		}
+	}
+	if len(p.Free()) == 0 {
+		return ErrNoFreeParam
+	}
+	return nil
+}
+
+// FitResult is the state of a fit engine after an iteration
+type FitResult struct {
+	// values of all parameters, fixed ones included
+	Parameters []float64
+	// errors of the parameters, zero if the engine does not estimate errors
+	Errors []float64
+	FVal   float64
+	// estimated distance to the minimum, NaN if the engine does not estimate it
+	EDM float64
+	// the engine reports a valid minimum, the EDM of an invalid minimum is not a criterion for convergence
+	Valid bool
+	// total number of function calls of the engine
+	NFcn int
+	// set once the engine finds no further improvement
+	Converged bool
+}
+
+// FitEngine is an algorithm minimizing a FitProblem
+// the fit is advanced in small iterations, so it can be paused or stopped between them
+type FitEngine interface {
+	// Iterate continues the fit with roughly maxCalls function calls
+	Iterate(maxCalls int) (*FitResult, error)
+}
+
+// EngineFactory creates a fit engine for a problem
+type EngineFactory func(problem *FitProblem) (FitEngine, error)
+
+// EngineSetting is a setting of FitConfig which only some engines use
+type EngineSetting int
+
+const (
+	// the engine draws random numbers starting at FitConfig.Seed
+	SettingSeed EngineSetting = iota + 1
+	// the engine runs Migrad with FitConfig.Strategy
+	SettingStrategy
+)
+
+var (
+	engineNames     = make([]string, 0)
+	engineFactories = make(map[string]EngineFactory)
+	engineSettings  = make(map[string][]EngineSetting)
+)
+
+func init() {
+	RegisterEngine(EngineMigrad, NewMigradEngine, SettingStrategy)
+	RegisterEngine(EngineHC, AsyncEngineFactory(FloatMinimizerHC))
+	RegisterEngine(EngineStagedHC, AsyncEngineFactory(FloatMinimizerStagedHC))
+	RegisterEngine(EnginePLLS, AsyncEngineFactory(FloatMinimizerPLLS))
+	RegisterEngine(EngineStagedHCMigrad, ChainEngines(AsyncEngineFactory(FloatMinimizerStagedHC), NewMigradEngine), SettingStrategy)
+	RegisterEngine(EngineDE, NewDifferentialEvolutionEngine, SettingSeed)
+	RegisterEngine(EngineSA, NewSimulatedAnnealingEngine, SettingSeed)
+	RegisterEngine(EngineDEMigrad, ChainEngines(NewDifferentialEvolutionEngine, NewMigradEngine), SettingSeed, SettingStrategy)
+	RegisterEngine(EngineSAMigrad, ChainEngines(NewSimulatedAnnealingEngine, NewMigradEngine), SettingSeed, SettingStrategy)
+}
+
+// NewDifferentialEvolutionEngine creates a differential evolution engine with the seed of the job, a random one
+// without job, the limits of the parameters are the bounds of the population
+func NewDifferentialEvolutionEngine(problem *FitProblem) (FitEngine, error) {
+	return StatefulEngineFactory(func() Minimizer[float64] {
+		return NewDifferentialEvolution(DEConfig{Seed: cmp.Or(problem.seed, rand.Uint64())})
+	})(problem)
+}
+
+// NewSimulatedAnnealingEngine creates a simulated annealing engine with the seed of the job, a random one
+// without job, the limits of the parameters are the bounds of the walkers
+func NewSimulatedAnnealingEngine(problem *FitProblem) (FitEngine, error) {
+	return StatefulEngineFactory(func() Minimizer[float64] {
+		return NewSimulatedAnnealing(SAConfig{Seed: cmp.Or(problem.seed, rand.Uint64())})
+	})(problem)
+}
+
+// RegisterEngine makes a fit engine selectable by its name, registering a name again replaces the engine
+// settings are the settings of FitConfig which only some engines use and this one depends on
+func RegisterEngine(name string, factory EngineFactory, settings ...EngineSetting) {
+	if _, ok := engineFactories[name]; !ok {
+		engineNames = append(engineNames, name)
+	}
+	engineFactories[name] = factory
+	engineSettings[name] = settings
+}
+
+// EngineUses reports whether the registered engine depends on the setting
+func EngineUses(name string, setting EngineSetting) bool {
+	return slices.Contains(engineSettings[name], setting)
+}
+
+// EngineNames returns the names of all registered engines in registration order
+func EngineNames() []string {
+	return slices.Clone(engineNames)
+}
+
+// NewEngine creates the registered engine with the given name for a problem
+func NewEngine(name string, problem *FitProblem) (FitEngine, error) {
+	factory, ok := engineFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEngine, name)
+	}
+	if err := problem.validate(); err != nil {
+		return nil, err
+	}
+	return factory(problem)
+}
+
+// chainEngine runs several engines one after another, every engine starts at the result of the previous one
+// the remaining calls of the job are shared equally by the remaining stages, a stage ends once it converges
+// or has used its share, so the last stage always runs
+type chainEngine struct {
+	problem   *FitProblem
+	factories []EngineFactory
+	stage     int
+	current   FitEngine
+	// function calls of the completed stages
+	calls int
+	// function calls of the current stage
+	stageCalls int
+}
+
+// ChainEngines creates a factory running the engines one after another,
+// f.e. a robust global search followed by Migrad for the precise minimum and errors
+func ChainEngines(factories ...EngineFactory) EngineFactory {
+	return func(problem *FitProblem) (FitEngine, error) {
+		if len(factories) == 0 {
+			return nil, errors.New("no engines to chain")
+		}
+		return &chainEngine{problem: problem, factories: factories}, nil
+	}
+}
+
+func (c *chainEngine) Iterate(maxCalls int) (*FitResult, error) {
+	if c.current == nil {
+		engine, err := c.factories[c.stage](c.problem)
+		if err != nil {
+			return nil, err
+		}
+		c.current = engine
+	}
+
+	last := c.stage == len(c.factories)-1
+	share := (cmp.Or(c.problem.maxCalls, DefaultMaxCalls) - c.calls) / (len(c.factories) - c.stage)
+	if !last {
+		maxCalls = max(min(maxCalls, share-c.stageCalls), 1)
+	}
+
+	res, err := c.current.Iterate(maxCalls)
+	if err != nil {
+		return nil, err
+	}
+	c.stageCalls = res.NFcn
+	res.NFcn += c.calls
+
+	// continue with the next engine at the current result
+	if !last && (res.Converged || c.stageCalls >= share) {
+		c.stage++
+		c.current = nil
+		c.calls = res.NFcn
+		c.stageCalls = 0
+		c.problem = c.problem.WithValues(res.Parameters)
+		res.Converged = false
+		res.EDM = math.NaN()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,171 @@
+package minimizer
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// minimum at (1, -2), the third parameter is fixed and must not change
+func quadratic(par []float64) float64 {
+	return (par[0]-1)*(par[0]-1) + 10*(par[1]+2)*(par[1]+2) + par[2]*par[2]
+}
+
+func quadraticProblem() *FitProblem {
+	problem := NewFitProblem(quadratic, []float64{0, 0, 3})
+	problem.Fixed[2] = true
+	for i := range problem.Steps {
+		problem.Steps[i] = 1
+	}
+	return problem
+}
+
+func runEngine(t *testing.T, name string, problem *FitProblem) *FitResult {
+	engine, err := NewEngine(name, problem)
+	if err != nil {
+		t.Fatalf("%s: could not create engine: %s", name, err)
+	}
+
+	var res *FitResult
+	for i := 0; i < 500; i++ {
+		res, err = engine.Iterate(50)
+		if err != nil {
+			t.Fatalf("%s: iteration failed: %s", name, err)
+		}
+		if res.Converged {
+			return res
+		}
+	}
+	t.Fatalf("%s: did not converge", name)
+	return nil
+}
+
+func TestEngines(t *testing.T) {
+	tolerance := map[string]float64{
+		EngineMigrad:         1e-3,
+		EngineHC:             2e-2,
+		EngineStagedHC:       0.15,
+		EnginePLLS:           2e-2,
+		EngineStagedHCMigrad: 1e-3,
+		EngineDE:             1e-2,
+		EngineSA:             5e-2,
+		EngineDEMigrad:       1e-3,
+		EngineSAMigrad:       1e-3,
+	}
+
+	for _, name := range EngineNames() {
+		t.Run(name, func(t *testing.T) {
+			res := runEngine(t, name, quadraticProblem())
+
+			if math.Abs(res.Parameters[0]-1) > tolerance[name] || math.Abs(res.Parameters[1]+2) > tolerance[name] {
+				t.Errorf("expected minimum at (1, -2) but got (%g, %g)", res.Parameters[0], res.Parameters[1])
+			}
+			if res.Parameters[2] != 3 {
+				t.Errorf("fixed parameter changed to %g", res.Parameters[2])
+			}
+			if len(res.Errors) != 3 {
+				t.Errorf("expected 3 errors but got %d", len(res.Errors))
+			}
+			if res.NFcn <= 0 {
+				t.Errorf("expected function calls to be counted")
+			}
+		})
+	}
+}
+
+func TestEngineLimits(t *testing.T) {
+	for _, name := range []string{EngineHC, EnginePLLS} {
+		t.Run(name, func(t *testing.T) {
+			problem := quadraticProblem()
+			problem.Minima[1] = -1.5
+			problem.Maxima[1] = 5
+
+			res := runEngine(t, name, problem)
+			if res.Parameters[1] < -1.5-1e-6 || math.Abs(res.Parameters[1]+1.5) > 2e-2 {
+				t.Errorf("expected parameter at the lower limit -1.5 but got %g", res.Parameters[1])
+			}
+		})
+	}
+}
+
+func TestNewEngineErrors(t *testing.T) {
+	if _, err := NewEngine("does not exist", quadraticProblem()); !errors.Is(err, ErrUnknownEngine) {
+		t.Errorf("expected unknown engine error but got %v", err)
+	}
+
+	problem := quadraticProblem()
+	problem.Fixed[0] = true
+	problem.Fixed[1] = true
+	if _, err := NewEngine(EngineMigrad, problem); !errors.Is(err, ErrNoFreeParam) {
+		t.Errorf("expected no free parameter error but got %v", err)
+	}
+
+	problem = quadraticProblem()
+	problem.Steps[0] = 0
+	if _, err := NewEngine(EngineHC, problem); err == nil {
+		t.Errorf("expected error for zero step size")
+	}
+}
+
+func TestRegisterEngine(t *testing.T) {
+	count := len(EngineNames())
+	RegisterEngine(EngineMigrad, NewMigradEngine)
+	if len(EngineNames()) != count {
+		t.Errorf("registering an engine again must replace it")
+	}
+	if EngineNames()[0] != DefaultEngine {
+		t.Errorf("expected %s to be the first engine", DefaultEngine)
+	}
+	if !EngineUses(EngineDEMigrad, SettingSeed) || EngineUses(EngineMigrad, SettingSeed) {
+		t.Errorf("expected only the engines with random numbers to use the seed")
+	}
+	if !EngineUses(EngineDEMigrad, SettingStrategy) || EngineUses(EngineDE, SettingStrategy) {
+		t.Errorf("expected only the engines running Migrad to use the strategy")
+	}
+}
+
+// never converges and stays at the start values
+type stuckEngine struct {
+	problem *FitProblem
+	calls   int
+}
+
+func (e *stuckEngine) Iterate(maxCalls int) (*FitResult, error) {
+	e.calls += maxCalls
+	return &FitResult{
+		Parameters: e.problem.Values,
+		FVal:       e.problem.Function(e.problem.Values),
+		EDM:        math.NaN(),
+		NFcn:       e.calls,
+	}, nil
+}
+
+func TestChainEngineBudget(t *testing.T) {
+	stuck := func(problem *FitProblem) (FitEngine, error) {
+		return &stuckEngine{problem: problem}, nil
+	}
+	problem := quadraticProblem().withConfig(FitConfig{MaxCalls: 1000})
+	engine, err := ChainEngines(stuck, NewMigradEngine)(problem)
+	if err != nil {
+		t.Fatalf("could not create engine: %s", err)
+	}
+
+	var res *FitResult
+	for res == nil || !res.Converged {
+		if res, err = engine.Iterate(50); err != nil {
+			t.Fatalf("iteration failed: %s", err)
+		}
+		if res.NFcn > 1000 {
+			t.Fatalf("the global stage used the budget of Migrad")
+		}
+	}
+	if math.Abs(res.Parameters[0]-1) > 1e-3 || math.Abs(res.Parameters[1]+2) > 1e-3 {
+		t.Errorf("expected minimum at (1, -2) but got (%g, %g)", res.Parameters[0], res.Parameters[1])
+	}
+	if !res.Valid || res.Errors[0] <= 0 {
+		t.Errorf("expected a valid minimum with errors from Migrad")
+	}
+	if res.NFcn < 500 {
+		t.Errorf("expected the global stage to use its share of 500 calls but the chain used %d", res.NFcn)
+	}
+}
@@ -0,0 +1,90 @@
+package minimizer
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// unlimited parameters are searched within this distance around the start value,
+// used with a FitEngine the distance is measured in step sizes
+const unboundedSearchRange = 10.0
+
+// minimizers with their own stopping criterion report if they converged
+type convergenceReporter interface {
+	converged() bool
+}
+
+// minimizers reporting how many function calls one loop takes
+type loopCostReporter interface {
+	callsPerLoop(parameterCount int) int
+}
+
+// returns the finite search range of every parameter of the problem
+func searchBounds(x0, minima, maxima []float64) ([]float64, []float64) {
+	lower := make([]float64, len(x0))
+	upper := make([]float64, len(x0))
+	for i := range x0 {
+		lower[i] = minima[i]
+		upper[i] = maxima[i]
+		if math.IsInf(lower[i], 0) {
+			lower[i] = x0[i] - unboundedSearchRange
+		}
+		if math.IsInf(upper[i], 0) {
+			upper[i] = x0[i] + unboundedSearchRange
+		}
+	}
+	return lower, upper
+}
+
+// reflects a value leaving the bounds back into them
+func reflectInto(x, lower, upper float64) float64 {
+	if x < lower {
+		x = lower + (lower - x)
+	}
+	if x > upper {
+		x = upper - (x - upper)
+	}
+	return min(max(x, lower), upper)
+}
+
+// evaluates the error function of all nodes concurrently on one worker per processor
+func evaluateParallel(problem *AsyncMinimiserProblem[float64], nodes [][]float64) []float64 {
+	errs := make([]float64, len(nodes))
+	indices := make(chan int)
+	workers := min(runtime.GOMAXPROCS(0), len(nodes))
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = problem.errorFunction(nodes[i])
+			}
+		}()
+	}
+	for i := range nodes {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return errs
+}
+
+// checks if the problem should run another loop, blocks while the problem is paused
+func shouldContinue[T Number](problem *AsyncMinimiserProblem[T]) bool {
+	problem.lock.RLock()
+	defer problem.lock.RUnlock()
+	return problem.config.LoopCount > 0
+}
+
+// publishes the best node and counts down the loops
+func publishBest(problem *AsyncMinimiserProblem[float64], best []float64, done bool) {
+	problem.lock.Lock()
+	defer problem.lock.Unlock()
+	problem.parameter = best
+	problem.config.LoopCount--
+	if done {
+		problem.config.LoopCount = 0
+	}
+}
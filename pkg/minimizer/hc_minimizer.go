@@ -18,7 +18,7 @@ func (s *stagedHillClimbingMinimizer[T]) Minimize(problem *AsyncMinimiserProblem
 
 	for i := 0; i < s.stageCount; i++ {
 		problem.lock.Lock()
-		problem.config.LoopCount = totalLoopPool / s.stageCount
+		problem.config.LoopCount = max(1, totalLoopPool/s.stageCount)
 		problem.lock.Unlock()
 
 		currentMinimizer := hillClimbingMinimizer[T]{
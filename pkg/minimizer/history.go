@@ -0,0 +1,73 @@
+package minimizer
+
+import (
+	"slices"
+	"sync"
+)
+
+// FitHistory records the results of a fit after every iteration, it is safe for concurrent use
+type FitHistory struct {
+	lock   sync.RWMutex
+	calls  []int
+	fvals  []float64
+	values [][]float64
+}
+
+// NewFitHistory creates an empty history
+func NewFitHistory() *FitHistory {
+	return &FitHistory{}
+}
+
+// Record appends a result, results without new function calls are ignored
+func (h *FitHistory) Record(res *FitResult) {
+	if res == nil {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if n := len(h.calls); n > 0 && h.calls[n-1] >= res.NFcn {
+		return
+	}
+	h.calls = append(h.calls, res.NFcn)
+	h.fvals = append(h.fvals, res.FVal)
+	h.values = append(h.values, slices.Clone(res.Parameters))
+}
+
+// Reset removes all recorded results
+func (h *FitHistory) Reset() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.calls, h.fvals, h.values = nil, nil, nil
+}
+
+// Len returns the number of recorded results
+func (h *FitHistory) Len() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return len(h.calls)
+}
+
+// Calls returns the total number of function calls of every recorded result
+func (h *FitHistory) Calls() []int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return slices.Clone(h.calls)
+}
+
+// FVals returns the penalty of every recorded result
+func (h *FitHistory) FVals() []float64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return slices.Clone(h.fvals)
+}
+
+// Trace returns the value of a parameter (indexed like the problem values) of every recorded result
+func (h *FitHistory) Trace(param int) []float64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	trace := make([]float64, len(h.values))
+	for i, values := range h.values {
+		trace[i] = values[param]
+	}
+	return trace
+}
@@ -0,0 +1,57 @@
+package minimizer
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFitHistory(t *testing.T) {
+	history := NewFitHistory()
+	history.Record(&FitResult{Parameters: []float64{1, 2}, FVal: 10, NFcn: 50})
+	history.Record(&FitResult{Parameters: []float64{3, 4}, FVal: 5, NFcn: 100})
+	// no new calls, f.e. the final event repeating the last progress
+	history.Record(&FitResult{Parameters: []float64{3, 4}, FVal: 5, NFcn: 100})
+	history.Record(nil)
+
+	if !slices.Equal(history.Calls(), []int{50, 100}) {
+		t.Errorf("unexpected calls %v", history.Calls())
+	}
+	if !slices.Equal(history.FVals(), []float64{10, 5}) {
+		t.Errorf("unexpected fvals %v", history.FVals())
+	}
+	if !slices.Equal(history.Trace(1), []float64{2, 4}) {
+		t.Errorf("unexpected trace %v", history.Trace(1))
+	}
+
+	history.Reset()
+	if history.Len() != 0 {
+		t.Errorf("expected empty history after reset")
+	}
+}
+
+func TestFitHistoryOfJob(t *testing.T) {
+	job, err := StartFit(context.Background(), quadraticProblem(), FitConfig{Engine: EngineHC})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+
+	history := NewFitHistory()
+	for event := range job.Events() {
+		history.Record(event.Result)
+	}
+
+	fvals := history.FVals()
+	if len(fvals) < 2 {
+		t.Fatalf("expected several results but got %d", len(fvals))
+	}
+	// hill climbing never gets worse
+	for i := 1; i < len(fvals); i++ {
+		if fvals[i] > fvals[i-1] {
+			t.Errorf("penalty increased from %g to %g", fvals[i-1], fvals[i])
+		}
+	}
+	if !slices.IsSorted(history.Calls()) {
+		t.Errorf("expected increasing calls %v", history.Calls())
+	}
+}
@@ -0,0 +1,258 @@
+package minimizer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+)
+
+const (
+	// function calls per iteration, the job can be paused or cancelled between iterations
+	DefaultIterationCalls = 50
+	DefaultMaxCalls       = 100000
+	// Migrad stops at an EDM of 0.002 * tolerance * up, with the default tolerance of 0.1 and up = 1
+	DefaultEDMTolerance = 2e-4
+	// progress events buffered for slow receivers, further progress is dropped while the buffer is full
+	fitEventBuffer = 16
+)
+
+var ErrMaxCalls = errors.New("maximum number of function calls reached")
+
+// FitEventKind tells why a FitEvent was sent
+type FitEventKind int
+
+const (
+	// the fit advanced by an iteration
+	FitProgress FitEventKind = iota
+	// the engine converged or the EDM is below the tolerance
+	FitConverged
+	// the maximum number of function calls was reached before convergence
+	FitMaxCalls
+	// the context of the job was cancelled
+	FitCancelled
+	// the engine returned an error
+	FitFailed
+)
+
+func (k FitEventKind) String() string {
+	switch k {
+	case FitProgress:
+		return "Running"
+	case FitConverged:
+		return "Converged"
+	case FitMaxCalls:
+		return "Call Limit"
+	case FitCancelled:
+		return "Cancelled"
+	case FitFailed:
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+// Final reports whether the event is the last event of a job
+func (k FitEventKind) Final() bool {
+	return k != FitProgress
+}
+
+// FitEvent is sent by a FitJob after every iteration and once when the job ends
+type FitEvent struct {
+	Kind FitEventKind
+	// latest result of the engine, nil if the job ended before the first iteration
+	Result *FitResult
+	// set for FitFailed (engine error), FitCancelled (context error) and FitMaxCalls
+	Err error
+}
+
+// FitConfig configures a FitJob
+type FitConfig struct {
+	// name of the registered engine, empty uses DefaultEngine
+	Engine string
+	// the job stops after this number of function calls, 0 uses DefaultMaxCalls
+	MaxCalls int
+	// function calls per iteration, 0 uses DefaultIterationCalls
+	IterationCalls int
+	// the job converges once the EDM of the engine is below the tolerance, 0 uses DefaultEDMTolerance
+	// negative values disable the criterion, engines without EDM only stop on their own convergence
+	EDMTolerance float64
+	// strategy of the Minuit engines, the other engines ignore it
+	Strategy Strategy
+	// seed of the engines with random numbers (see EngineUses), the same seed repeats the fit,
+	// 0 draws a random seed which is reported by FitJob.Seed
+	Seed uint64
+}
+
+// FitJob runs a fit engine in the background until convergence, the call limit or cancellation of its context
+type FitJob struct {
+	config FitConfig
+	engine FitEngine
+	cancel context.CancelFunc
+	events chan FitEvent
+	done   chan struct{}
+	final  FitEvent
+
+	lock   sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+// StartFit creates the engine of the problem and starts the job, errors of the setup are returned directly
+func StartFit(ctx context.Context, problem *FitProblem, config FitConfig) (*FitJob, error) {
+	config.Engine = cmp.Or(config.Engine, DefaultEngine)
+	config.MaxCalls = cmp.Or(config.MaxCalls, DefaultMaxCalls)
+	config.IterationCalls = cmp.Or(config.IterationCalls, DefaultIterationCalls)
+	config.EDMTolerance = cmp.Or(config.EDMTolerance, DefaultEDMTolerance)
+
+	config.Seed = cmp.Or(config.Seed, rand.Uint64())
+
+	problem = problem.withConfig(config)
+	engine, err := NewEngine(config.Engine, problem)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	job := &FitJob{
+		config: config,
+		engine: engine,
+		cancel: cancel,
+		events: make(chan FitEvent, fitEventBuffer),
+		done:   make(chan struct{}),
+	}
+	go job.run(ctx)
+	return job, nil
+}
+
+// Seed returns the seed of the engine of the job
+func (j *FitJob) Seed() uint64 {
+	return j.config.Seed
+}
+
+// RunFit runs a job and waits for its end, it returns the final event
+func RunFit(ctx context.Context, problem *FitProblem, config FitConfig) (FitEvent, error) {
+	job, err := StartFit(ctx, problem, config)
+	if err != nil {
+		return FitEvent{}, err
+	}
+	return job.Wait(), nil
+}
+
+func (j *FitJob) run(ctx context.Context) {
+	defer close(j.done)
+	defer close(j.events)
+	defer j.cancel()
+
+	var last *FitResult
+	for {
+		if err := j.waitWhilePaused(ctx); err != nil {
+			j.finish(FitEvent{Kind: FitCancelled, Result: last, Err: err})
+			return
+		}
+
+		res, err := j.engine.Iterate(j.config.IterationCalls)
+		if err != nil {
+			j.finish(FitEvent{Kind: FitFailed, Result: last, Err: err})
+			return
+		}
+		last = res
+
+		switch {
+		case j.converged(res):
+			j.finish(FitEvent{Kind: FitConverged, Result: res})
+			return
+		case res.NFcn >= j.config.MaxCalls:
+			j.finish(FitEvent{Kind: FitMaxCalls, Result: res, Err: ErrMaxCalls})
+			return
+		}
+
+		// the last slot of the buffer is kept for the final event, so the job never blocks
+		if len(j.events) < cap(j.events)-1 {
+			j.events <- FitEvent{Kind: FitProgress, Result: res}
+		}
+	}
+}
+
+func (j *FitJob) converged(res *FitResult) bool {
+	if res.Converged {
+		return true
+	}
+	return res.Valid && j.config.EDMTolerance > 0 && !math.IsNaN(res.EDM) && res.EDM < j.config.EDMTolerance
+}
+
+func (j *FitJob) finish(event FitEvent) {
+	j.final = event
+	j.events <- event
+}
+
+// blocks while the job is paused, returns the context error once the context is done
+func (j *FitJob) waitWhilePaused(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		j.lock.Lock()
+		if !j.paused {
+			j.lock.Unlock()
+			return nil
+		}
+		resume := j.resume
+		j.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+		case <-resume:
+		}
+	}
+}
+
+// Events returns the channel of progress events, the final event is always delivered before the channel is closed
+// progress events are dropped if the receiver falls behind
+func (j *FitJob) Events() <-chan FitEvent {
+	return j.events
+}
+
+// Pause stops the job after the current iteration until Resume is called
+func (j *FitJob) Pause() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if !j.paused {
+		j.paused = true
+		j.resume = make(chan struct{})
+	}
+}
+
+// Resume continues a paused job
+func (j *FitJob) Resume() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.paused {
+		j.paused = false
+		close(j.resume)
+	}
+}
+
+// Paused reports whether the job is paused
+func (j *FitJob) Paused() bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.paused
+}
+
+// Cancel stops the job after the current iteration, the final event is FitCancelled
+func (j *FitJob) Cancel() {
+	j.cancel()
+}
+
+// Done is closed once the job ended and the events channel is closed
+func (j *FitJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// Wait blocks until the job ended and returns the final event, it does not receive from the events channel
+func (j *FitJob) Wait() FitEvent {
+	<-j.done
+	return j.final
+}
@@ -0,0 +1,204 @@
+package minimizer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// collects the events of a job until the channel is closed
+func collectEvents(job *FitJob) []FitEvent {
+	events := make([]FitEvent, 0)
+	for e := range job.Events() {
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestFitJobConverged(t *testing.T) {
+	job, err := StartFit(context.Background(), quadraticProblem(), FitConfig{Engine: EngineMigrad})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+
+	events := collectEvents(job)
+	final := events[len(events)-1]
+	if final.Kind != FitConverged {
+		t.Fatalf("expected converged job but got %s: %v", final.Kind, final.Err)
+	}
+	for _, e := range events[:len(events)-1] {
+		if e.Kind != FitProgress {
+			t.Errorf("expected only progress before the final event but got %s", e.Kind)
+		}
+	}
+	if res := final.Result; math.Abs(res.Parameters[0]-1) > 1e-3 || math.Abs(res.Parameters[1]+2) > 1e-3 {
+		t.Errorf("expected minimum at (1, -2) but got %v", res.Parameters)
+	}
+	if final != job.Wait() {
+		t.Errorf("expected Wait to return the final event")
+	}
+	if !final.Result.Valid || final.Result.EDM >= DefaultEDMTolerance {
+		t.Errorf("expected a valid minimum below the EDM tolerance but got EDM %g", final.Result.EDM)
+	}
+}
+
+func TestFitJobInvalidMinimum(t *testing.T) {
+	// the EDM of an invalid minimum is not trusted
+	job := &FitJob{config: FitConfig{EDMTolerance: DefaultEDMTolerance}}
+	if job.converged(&FitResult{EDM: 0}) {
+		t.Errorf("expected no convergence at an invalid minimum")
+	}
+	if !job.converged(&FitResult{EDM: 0, Valid: true}) {
+		t.Errorf("expected convergence at a valid minimum below the tolerance")
+	}
+}
+
+func TestFitJobSeed(t *testing.T) {
+	// the same seed repeats a global fit
+	results := make([]*FitResult, 2)
+	for i := range results {
+		event, err := RunFit(context.Background(), quadraticProblem(), FitConfig{Engine: EngineDE, MaxCalls: 2000, Seed: 42})
+		if err != nil {
+			t.Fatalf("could not start job: %s", err)
+		}
+		results[i] = event.Result
+	}
+	if !slices.Equal(results[0].Parameters, results[1].Parameters) || results[0].NFcn != results[1].NFcn {
+		t.Errorf("expected the same fit for the same seed but got %v and %v", results[0].Parameters, results[1].Parameters)
+	}
+
+	job, err := StartFit(context.Background(), quadraticProblem(), FitConfig{Engine: EngineDE, MaxCalls: 100})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+	job.Wait()
+	if job.Seed() == 0 {
+		t.Errorf("expected a random seed to be drawn")
+	}
+}
+
+func TestFitJobLimits(t *testing.T) {
+	// the minimum of Migrad at a limit is found by the EDM criterion
+	problem := quadraticProblem()
+	problem.Minima[0] = -5
+	problem.Maxima[0] = 0.5
+
+	event, err := RunFit(context.Background(), problem, FitConfig{Engine: EngineMigrad, MaxCalls: 5000})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+	if event.Kind != FitConverged {
+		t.Fatalf("expected converged job but got %s: %v", event.Kind, event.Err)
+	}
+	if math.Abs(event.Result.Parameters[0]-0.5) > 1e-2 {
+		t.Errorf("expected parameter at the limit 0.5 but got %g", event.Result.Parameters[0])
+	}
+}
+
+func TestFitJobMaxCalls(t *testing.T) {
+	// the rosenbrock valley takes more than 50 calls
+	problem := NewFitProblem(func(par []float64) float64 {
+		return math.Pow(1-par[0], 2) + 100*math.Pow(par[1]-par[0]*par[0], 2)
+	}, []float64{-1.5, 2})
+
+	event, err := RunFit(context.Background(), problem, FitConfig{Engine: EngineMigrad, MaxCalls: 50, IterationCalls: 10})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+	if event.Kind != FitMaxCalls || !errors.Is(event.Err, ErrMaxCalls) {
+		t.Fatalf("expected call limit but got %s: %v", event.Kind, event.Err)
+	}
+	if event.Result.NFcn < 50 {
+		t.Errorf("expected at least 50 calls but got %d", event.Result.NFcn)
+	}
+}
+
+// an unbounded slope never converging, every call is slow enough to cancel the job in between
+func slowProblem() *FitProblem {
+	return NewFitProblem(func(par []float64) float64 {
+		time.Sleep(100 * time.Microsecond)
+		return -par[0]
+	}, []float64{0})
+}
+
+func TestFitJobCancel(t *testing.T) {
+	job, err := StartFit(context.Background(), slowProblem(), FitConfig{Engine: EngineHC, EDMTolerance: -1})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	job.Cancel()
+
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job did not stop after cancel")
+	}
+	if final := job.Wait(); final.Kind != FitCancelled || !errors.Is(final.Err, context.Canceled) {
+		t.Errorf("expected cancelled job but got %s: %v", final.Kind, final.Err)
+	}
+}
+
+func TestFitJobContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	event, err := RunFit(ctx, slowProblem(), FitConfig{Engine: EngineHC, EDMTolerance: -1})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+	if event.Kind != FitCancelled || !errors.Is(event.Err, context.DeadlineExceeded) {
+		t.Errorf("expected cancelled job but got %s: %v", event.Kind, event.Err)
+	}
+}
+
+func TestFitJobPause(t *testing.T) {
+	job, err := StartFit(context.Background(), slowProblem(), FitConfig{Engine: EngineHC, EDMTolerance: -1})
+	if err != nil {
+		t.Fatalf("could not start job: %s", err)
+	}
+	job.Pause()
+	if !job.Paused() {
+		t.Fatalf("expected paused job")
+	}
+
+	// wait for the current iteration, afterwards no events arrive until the job is resumed
+	time.Sleep(50 * time.Millisecond)
+	for len(job.Events()) > 0 {
+		<-job.Events()
+	}
+	select {
+	case e := <-job.Events():
+		t.Fatalf("expected no events while paused but got %s", e.Kind)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	job.Resume()
+	select {
+	case <-job.Events():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected events after resume")
+	}
+
+	// a paused job can be cancelled
+	job.Pause()
+	job.Cancel()
+	if final := job.Wait(); final.Kind != FitCancelled {
+		t.Errorf("expected cancelled job but got %s", final.Kind)
+	}
+}
+
+func TestFitJobErrors(t *testing.T) {
+	if _, err := StartFit(context.Background(), quadraticProblem(), FitConfig{Engine: "unknown"}); !errors.Is(err, ErrUnknownEngine) {
+		t.Errorf("expected unknown engine error but got %v", err)
+	}
+
+	problem := quadraticProblem()
+	problem.Fixed = []bool{true, true, true}
+	if _, err := StartFit(context.Background(), problem, FitConfig{}); !errors.Is(err, ErrNoFreeParam) {
+		t.Errorf("expected no free parameter error but got %v", err)
+	}
+}
@@ -73,7 +73,11 @@ func (p *AsyncMinimiserProblem[_]) Pause() error {
 	}
 	if !p.config.ParallelReads {
 		return errors.New("can not pause with without parallel read option")
-	} else if p.config.LoopCount == 0 {
+	}
+	p.lock.RLock()
+	completed := p.config.LoopCount == 0
+	p.lock.RUnlock()
+	if completed {
 		return errors.New("can not pause what is already completed")
 	}
 	p.lock.Lock()
@@ -0,0 +1,368 @@
+package minimizer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// MCMCConfig configures the ensemble sampler
+type MCMCConfig struct {
+	// number of walkers, must be even and greater than twice the free parameters, 0 uses 4 walkers per free parameter
+	Walkers int
+	// stretch move scale a, 0 uses 2
+	StretchScale float64
+	// the log-likelihood is -LikelihoodScale*penalty, 0 uses 0.5 which is exact if the penalty is a χ²,
+	// for other penalties f.e. the qz² weighted χ² of the sessions the scale sets the width of the posterior
+	// and the errors are only as meaningful as the chosen scale
+	LikelihoodScale float64
+	// seed of the random number generator, the same seed gives the same chains
+	Seed uint64
+}
+
+// Interval is a central credible interval of a marginal distribution
+type Interval struct {
+	Lower float64
+	Upper float64
+}
+
+// Marginal summarizes the samples of one parameter
+type Marginal struct {
+	Mean   float64
+	StdDev float64
+	Median float64
+	// 68.3% central credible interval (±1σ for a gaussian)
+	Sigma1 Interval
+	// 95.4% central credible interval (±2σ for a gaussian)
+	Sigma2 Interval
+}
+
+// Sampler is an affine-invariant ensemble MCMC sampler (stretch move of Goodman & Weare as in emcee)
+// only the free parameters of the problem are sampled, the prior is uniform within the parameter limits
+// the walkers of each half of the ensemble are evaluated in parallel
+type Sampler struct {
+	lock    sync.RWMutex
+	problem *FitProblem
+	config  MCMCConfig
+	rng     *rand.Rand
+	free    []int
+
+	walkers [][]float64
+	logP    []float64
+
+	// chain[step][walker][free parameter]
+	chain     [][][]float64
+	logPChain [][]float64
+	// guarded by the lock like the chain
+	accepted int
+	proposed int
+}
+
+// NewSampler creates an ensemble sampler, the walkers start in a small ball around the problem values
+func NewSampler(problem *FitProblem, config MCMCConfig) (*Sampler, error) {
+	if err := problem.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Sampler{
+		problem: problem,
+		config:  config,
+		free:    problem.Free(),
+	}
+	dim := len(s.free)
+	if s.config.Walkers == 0 {
+		s.config.Walkers = max(4*dim, 8)
+	}
+	if s.config.Walkers%2 != 0 || s.config.Walkers <= 2*dim {
+		return nil, fmt.Errorf("mcmc: the number of walkers must be even and greater than %d", 2*dim)
+	}
+	if s.config.StretchScale == 0 {
+		s.config.StretchScale = 2
+	}
+	if s.config.StretchScale <= 1 {
+		return nil, errors.New("mcmc: stretch scale must be greater than 1")
+	}
+	if s.config.LikelihoodScale == 0 {
+		s.config.LikelihoodScale = 0.5
+	}
+	if s.config.LikelihoodScale < 0 {
+		return nil, errors.New("mcmc: likelihood scale must be positive")
+	}
+	s.rng = rand.New(rand.NewPCG(s.config.Seed, s.config.Seed^0x9e3779b97f4a7c15))
+
+	// start in a ball of a tenth of the step size, reflected into the limits
+	s.walkers = make([][]float64, s.config.Walkers)
+	for w := range s.walkers {
+		walker := make([]float64, dim)
+		for i, id := range s.free {
+			v := problem.Values[id] + 0.1*problem.Steps[id]*s.rng.NormFloat64()
+			walker[i] = reflectInto(v, problem.Minima[id], problem.Maxima[id])
+		}
+		s.walkers[w] = walker
+	}
+	s.logP = s.evaluate(s.walkers)
+
+	if !slices.ContainsFunc(s.logP, func(l float64) bool { return !math.IsInf(l, -1) }) {
+		return nil, errors.New("mcmc: the penalty is invalid for all start values")
+	}
+
+	return s, nil
+}
+
+// log-probability of a point in the space of the free parameters
+func (s *Sampler) logProbability(point []float64) float64 {
+	par := slices.Clone(s.problem.Values)
+	for i, id := range s.free {
+		if point[i] < s.problem.Minima[id] || point[i] > s.problem.Maxima[id] {
+			return math.Inf(-1)
+		}
+		par[id] = point[i]
+	}
+	penalty := s.problem.Function(par)
+	if math.IsNaN(penalty) || penalty == math.MaxFloat64 {
+		return math.Inf(-1)
+	}
+	return -s.config.LikelihoodScale * penalty
+}
+
+func (s *Sampler) evaluate(points [][]float64) []float64 {
+	logP := make([]float64, len(points))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(points))
+	for i, p := range points {
+		go func(id int, point []float64) {
+			logP[id] = s.logProbability(point)
+			wg.Done()
+		}(i, p)
+	}
+	wg.Wait()
+	return logP
+}
+
+// Run advances all walkers by the given number of steps
+func (s *Sampler) Run(steps int) {
+	for range steps {
+		s.step()
+	}
+}
+
+// one stretch move of every walker, the halves of the ensemble are moved one after another
+func (s *Sampler) step() {
+	dim := float64(len(s.free))
+	half := s.config.Walkers / 2
+	a := s.config.StretchScale
+	// counted here and added with the chain, the counts are read while the sampler runs
+	var accepted, proposed int
+
+	for h := 0; h < 2; h++ {
+		moving := h * half
+		other := (1 - h) * half
+
+		// all random numbers are drawn here so the chains only depend on the seed
+		proposals := make([][]float64, half)
+		zs := make([]float64, half)
+		us := make([]float64, half)
+		for k := range half {
+			z := math.Pow((a-1)*s.rng.Float64()+1, 2) / a
+			partner := s.walkers[other+s.rng.IntN(half)]
+			walker := s.walkers[moving+k]
+			proposal := make([]float64, len(walker))
+			for i := range walker {
+				proposal[i] = partner[i] + z*(walker[i]-partner[i])
+			}
+			proposals[k] = proposal
+			zs[k] = z
+			us[k] = s.rng.Float64()
+		}
+
+		logP := s.evaluate(proposals)
+		proposed += half
+		for k := range half {
+			logAccept := (dim-1)*math.Log(zs[k]) + logP[k] - s.logP[moving+k]
+			if math.Log(us[k]) < logAccept {
+				s.walkers[moving+k] = proposals[k]
+				s.logP[moving+k] = logP[k]
+				accepted++
+			}
+		}
+	}
+
+	chain := make([][]float64, len(s.walkers))
+	for w, walker := range s.walkers {
+		chain[w] = slices.Clone(walker)
+	}
+
+	s.lock.Lock()
+	s.accepted += accepted
+	s.proposed += proposed
+	s.chain = append(s.chain, chain)
+	s.logPChain = append(s.logPChain, slices.Clone(s.logP))
+	s.lock.Unlock()
+}
+
+// Free returns the indices of the sampled parameters in the problem
+func (s *Sampler) Free() []int {
+	return slices.Clone(s.free)
+}
+
+// Steps returns the number of steps sampled so far
+func (s *Sampler) Steps() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.chain)
+}
+
+// Walkers returns the number of walkers of the ensemble
+func (s *Sampler) Walkers() int {
+	return s.config.Walkers
+}
+
+// AcceptanceFraction returns the fraction of accepted proposals, it should be between 0.2 and 0.5
+func (s *Sampler) AcceptanceFraction() float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.proposed == 0 {
+		return 0
+	}
+	return float64(s.accepted) / float64(s.proposed)
+}
+
+// Chain returns the values of a free parameter for every walker and step, chain[walker][step]
+func (s *Sampler) Chain(param int) [][]float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	chain := make([][]float64, s.config.Walkers)
+	for w := range chain {
+		chain[w] = make([]float64, len(s.chain))
+		for step, walkers := range s.chain {
+			chain[w][step] = walkers[w][param]
+		}
+	}
+	return chain
+}
+
+// Samples returns the samples of a free parameter of all walkers, the first burnIn steps are dropped
+func (s *Sampler) Samples(param, burnIn int) []float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	samples := make([]float64, 0, max(len(s.chain)-burnIn, 0)*s.config.Walkers)
+	for step := burnIn; step < len(s.chain); step++ {
+		for _, walker := range s.chain[step] {
+			samples = append(samples, walker[param])
+		}
+	}
+	return samples
+}
+
+// quantile of sorted samples with linear interpolation
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := min(lower+1, len(sorted)-1)
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// Marginal summarizes the samples of a free parameter, the first burnIn steps are dropped
+func (s *Sampler) Marginal(param, burnIn int) Marginal {
+	samples := s.Samples(param, burnIn)
+	slices.Sort(samples)
+
+	mean := 0.0
+	for _, v := range samples {
+		mean += v
+	}
+	mean /= float64(len(samples))
+	variance := 0.0
+	for _, v := range samples {
+		variance += (v - mean) * (v - mean)
+	}
+
+	sigma1 := math.Erf(1 / math.Sqrt2)
+	sigma2 := math.Erf(2 / math.Sqrt2)
+	return Marginal{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance / float64(len(samples))),
+		Median: quantile(samples, 0.5),
+		Sigma1: Interval{quantile(samples, (1-sigma1)/2), quantile(samples, (1+sigma1)/2)},
+		Sigma2: Interval{quantile(samples, (1-sigma2)/2), quantile(samples, (1+sigma2)/2)},
+	}
+}
+
+// Histogram bins the samples of a free parameter, it returns the centers of the bins and the normalized densities
+func (s *Sampler) Histogram(param, burnIn, bins int) ([]float64, []float64) {
+	samples := s.Samples(param, burnIn)
+	centers := make([]float64, bins)
+	density := make([]float64, bins)
+	if len(samples) == 0 || bins <= 0 {
+		return centers, density
+	}
+
+	lower, upper := slices.Min(samples), slices.Max(samples)
+	width := (upper - lower) / float64(bins)
+	if width == 0 {
+		width = smallestBinWidth
+	}
+	for i := range centers {
+		centers[i] = lower + (float64(i)+0.5)*width
+	}
+	for _, v := range samples {
+		i := min(int((v-lower)/width), bins-1)
+		density[i]++
+	}
+	for i := range density {
+		density[i] /= float64(len(samples)) * width
+	}
+	return centers, density
+}
+
+const smallestBinWidth = 1e-12
+
+// WriteCSV writes the chains as comma separated values with one row per step and walker
+// the columns are step, walker, log-probability and the free parameters named by names (indexed like the problem values)
+func (s *Sampler) WriteCSV(w io.Writer, names []string) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	writer := csv.NewWriter(w)
+	header := []string{"step", "walker", "log_probability"}
+	for _, id := range s.free {
+		name := fmt.Sprintf("p%d", id)
+		if id < len(names) {
+			name = names[id]
+		}
+		header = append(header, name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for step, walkers := range s.chain {
+		for walker, values := range walkers {
+			record := []string{
+				strconv.Itoa(step),
+				strconv.Itoa(walker),
+				strconv.FormatFloat(s.logPChain[step][walker], 'g', -1, 64),
+			}
+			for _, v := range values {
+				record = append(record, strconv.FormatFloat(v, 'g', -1, 64))
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
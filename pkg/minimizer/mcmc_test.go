@@ -0,0 +1,122 @@
+package minimizer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"slices"
+	"testing"
+)
+
+// chi² of two independent gaussians N(1, 0.5) and N(-2, 2), the third parameter is fixed
+func gaussianChi2(par []float64) float64 {
+	return math.Pow((par[0]-1)/0.5, 2) + math.Pow((par[1]+2)/2, 2) + par[2]
+}
+
+func gaussianProblem() *FitProblem {
+	problem := NewFitProblem(gaussianChi2, []float64{1.2, -1, 5})
+	problem.Fixed[2] = true
+	return problem
+}
+
+func TestSamplerGaussian(t *testing.T) {
+	sampler, err := NewSampler(gaussianProblem(), MCMCConfig{Walkers: 16, Seed: 1})
+	if err != nil {
+		t.Fatalf("could not create sampler: %s", err)
+	}
+	sampler.Run(3000)
+
+	if f := sampler.AcceptanceFraction(); f < 0.2 || f > 0.9 {
+		t.Errorf("unexpected acceptance fraction %g", f)
+	}
+
+	expected := []struct{ mean, sigma float64 }{{1, 0.5}, {-2, 2}}
+	for i, e := range expected {
+		m := sampler.Marginal(i, 500)
+		if math.Abs(m.Median-e.mean) > 0.1*e.sigma {
+			t.Errorf("parameter %d: expected median %g but got %g", i, e.mean, m.Median)
+		}
+		if math.Abs(m.StdDev-e.sigma) > 0.1*e.sigma {
+			t.Errorf("parameter %d: expected standard deviation %g but got %g", i, e.sigma, m.StdDev)
+		}
+		if math.Abs(m.Sigma1.Lower-(e.mean-e.sigma)) > 0.15*e.sigma || math.Abs(m.Sigma1.Upper-(e.mean+e.sigma)) > 0.15*e.sigma {
+			t.Errorf("parameter %d: expected 1σ interval [%g, %g] but got [%g, %g]", i, e.mean-e.sigma, e.mean+e.sigma, m.Sigma1.Lower, m.Sigma1.Upper)
+		}
+	}
+
+	centers, density := sampler.Histogram(0, 500, 30)
+	integral := 0.0
+	for _, d := range density {
+		integral += d * (centers[1] - centers[0])
+	}
+	if math.Abs(integral-1) > 1e-9 {
+		t.Errorf("expected normalized histogram but integral is %g", integral)
+	}
+}
+
+func TestSamplerLimits(t *testing.T) {
+	problem := gaussianProblem()
+	problem.Minima[0] = 0.9
+	problem.Maxima[0] = 5
+
+	sampler, err := NewSampler(problem, MCMCConfig{Walkers: 16, Seed: 2})
+	if err != nil {
+		t.Fatalf("could not create sampler: %s", err)
+	}
+	sampler.Run(500)
+
+	if m := slices.Min(sampler.Samples(0, 0)); m < 0.9 {
+		t.Errorf("sample %g is outside of the limits", m)
+	}
+}
+
+func TestSamplerSeed(t *testing.T) {
+	run := func() []float64 {
+		sampler, err := NewSampler(gaussianProblem(), MCMCConfig{Seed: 3})
+		if err != nil {
+			t.Fatalf("could not create sampler: %s", err)
+		}
+		sampler.Run(50)
+		return sampler.Samples(1, 0)
+	}
+
+	if !slices.Equal(run(), run()) {
+		t.Errorf("expected the same chains for the same seed")
+	}
+}
+
+func TestSamplerConfig(t *testing.T) {
+	if _, err := NewSampler(gaussianProblem(), MCMCConfig{Walkers: 4}); err == nil {
+		t.Errorf("expected error for too few walkers")
+	}
+	if _, err := NewSampler(gaussianProblem(), MCMCConfig{Walkers: 9}); err == nil {
+		t.Errorf("expected error for an odd number of walkers")
+	}
+	if _, err := NewSampler(gaussianProblem(), MCMCConfig{LikelihoodScale: -0.5}); err == nil {
+		t.Errorf("expected error for a negative likelihood scale")
+	}
+}
+
+func TestSamplerWriteCSV(t *testing.T) {
+	sampler, err := NewSampler(gaussianProblem(), MCMCConfig{Walkers: 8, Seed: 4})
+	if err != nil {
+		t.Fatalf("could not create sampler: %s", err)
+	}
+	sampler.Run(10)
+
+	buf := new(bytes.Buffer)
+	if err := sampler.WriteCSV(buf, []string{"x", "y", "fixed"}); err != nil {
+		t.Fatalf("could not write csv: %s", err)
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("could not read csv: %s", err)
+	}
+	if !slices.Equal(records[0], []string{"step", "walker", "log_probability", "x", "y"}) {
+		t.Errorf("unexpected header %v", records[0])
+	}
+	if len(records) != 1+10*8 {
+		t.Errorf("expected %d rows but got %d", 1+10*8, len(records))
+	}
+}
@@ -0,0 +1,376 @@
+package minimizer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	minuit "github.com/empack/minuit2go/pkg"
+)
+
+const (
+	// maximum number of points of a scan
+	MaxScanPoints = 101
+	// up of the 68.3% confidence region of two parameters, 1 is the 68.3% interval of a single parameter
+	ContourUp68 = 2.3
+	// up of the 95.4% confidence region of two parameters
+	ContourUp95 = 6.18
+)
+
+// ErrInvalidMinimum is returned when Migrad finds no valid minimum to start an error analysis at
+var ErrInvalidMinimum = errors.New("migrad found no valid minimum")
+
+// MinuitConfig configures the Minuit error analyses
+type MinuitConfig struct {
+	Strategy Strategy
+	// function calls of every minimization, 0 uses DefaultMaxCalls
+	MaxCalls int
+}
+
+// ScanPoint is the value of the function at a value of the scanned parameter
+type ScanPoint struct {
+	Value float64
+	FVal  float64
+}
+
+// MinosError is the asymmetric error of a parameter where the function rises by 1 above its minimum,
+// the other free parameters are minimized at every point
+type MinosError struct {
+	Parameter int
+	Value     float64
+	// parabolic error of Migrad
+	Error float64
+	// offsets of the crossings from the value, lower is negative
+	Lower float64
+	Upper float64
+	// the crossing is beyond the limit of the parameter, the offset is the one of the limit
+	AtLowerLimit bool
+	AtUpperLimit bool
+	// both crossings were found or lie beyond a limit
+	Valid bool
+}
+
+// ContourPoint is a point of a contour of two parameters
+type ContourPoint struct {
+	X float64
+	Y float64
+}
+
+// checks that the parameter exists and is varied by the problem
+func checkFree(problem *FitProblem, par int) error {
+	if par < 0 || par >= len(problem.Values) {
+		return fmt.Errorf("parameter %d does not exist", par)
+	}
+	if problem.Fixed[par] {
+		return fmt.Errorf("parameter %d is fixed", par)
+	}
+	return nil
+}
+
+// Scan evaluates the function at points values of the parameter from low to high, the other parameters keep
+// their values, so the scan shows the function along a line and not its profile
+// without a range the parameter is scanned ±2 steps around its value, the range is clipped to its limits
+func Scan(problem *FitProblem, par, points int, low, high float64) ([]ScanPoint, error) {
+	if err := problem.validate(); err != nil {
+		return nil, err
+	}
+	if err := checkFree(problem, par); err != nil {
+		return nil, err
+	}
+	if points < 2 || points > MaxScanPoints {
+		return nil, fmt.Errorf("a scan needs 2 to %d points", MaxScanPoints)
+	}
+	if low > high {
+		return nil, errors.New("the lower end of the scan is greater than the upper one")
+	}
+
+	if low == 0 && high == 0 {
+		low = problem.Values[par] - 2*problem.Steps[par]
+		high = problem.Values[par] + 2*problem.Steps[par]
+	}
+	low, high = max(low, problem.Minima[par]), min(high, problem.Maxima[par])
+	if low > high {
+		return nil, errors.New("the range of the scan is outside the limits of the parameter")
+	}
+
+	values := slices.Clone(problem.Values)
+	step := (high - low) / float64(points-1)
+	scanned := make([]ScanPoint, points)
+	for i := range scanned {
+		values[par] = low + float64(i)*step
+		scanned[i] = ScanPoint{Value: values[par], FVal: problem.Function(values)}
+	}
+	return scanned, nil
+}
+
+// Profile evaluates the profile of the function at points values of the parameter from low to high, the other free
+// parameters are minimized by Migrad at every point, the context is checked between the points
+// without a range the parameter is profiled ±2 steps around its value, the range is clipped to its limits
+func Profile(ctx context.Context, problem *FitProblem, par, points int, low, high float64, config MinuitConfig) ([]ScanPoint, error) {
+	if err := problem.validate(); err != nil {
+		return nil, err
+	}
+	if err := checkFree(problem, par); err != nil {
+		return nil, err
+	}
+	if points < 2 {
+		return nil, errors.New("a profile needs at least 2 points")
+	}
+	if low > high {
+		return nil, errors.New("the lower end of the profile is greater than the upper one")
+	}
+	if low == high {
+		low, high = problem.Values[par]-2*problem.Steps[par], problem.Values[par]+2*problem.Steps[par]
+	}
+	low, high = max(low, problem.Minima[par]), min(high, problem.Maxima[par])
+
+	profiled := make([]ScanPoint, points)
+	for i := range profiled {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		value := low + (high-low)*float64(i)/float64(points-1)
+		fval, _, err := profile(problem, map[int]float64{par: value}, config)
+		if err != nil {
+			return nil, err
+		}
+		profiled[i] = ScanPoint{Value: value, FVal: fval}
+	}
+	return profiled, nil
+}
+
+// minimizes the problem with Migrad, the auto strategy continues an invalid minimum with the precise strategy
+func minuitMinimum(problem *FitProblem, config MinuitConfig) (*minuit.FunctionMinimum, *FitResult, error) {
+	if err := problem.validate(); err != nil {
+		return nil, nil, err
+	}
+	fcn := fitFunction(problem.Function)
+	maxCalls := cmp.Or(config.MaxCalls, DefaultMaxCalls)
+	state := minuit.NewUserParameterStateFromUserParameter(minuitParameters(problem))
+
+	minimum, err := newMigrad(fcn, state, config.Strategy.minuit()).MinimizeWithMaxfcn(maxCalls)
+	if err != nil {
+		return nil, nil, err
+	}
+	calls := minimum.Nfcn()
+	if !minimum.IsValid() && config.Strategy == StrategyAuto {
+		if minimum, err = newMigrad(fcn, minimum.UserState(), StrategyPrecise.minuit()).MinimizeWithMaxfcn(maxCalls); err != nil {
+			return nil, nil, err
+		}
+		calls += minimum.Nfcn()
+	}
+	if !minimum.IsValid() {
+		return nil, nil, ErrInvalidMinimum
+	}
+	return minimum, &FitResult{
+		Parameters: minimum.UserParameters().Params(),
+		Errors:     minimum.UserParameters().Errors(),
+		FVal:       minimum.Fval(),
+		EDM:        minimum.Edm(),
+		Valid:      true,
+		NFcn:       calls,
+		Converged:  true,
+	}, nil
+}
+
+// errNoCrossing is returned by crossing if the rise is not reached
+var errNoCrossing = errors.New("no crossing found")
+
+// profile minimizes the problem with the parameters fixed at the values and returns the minimum and its calls
+func profile(problem *FitProblem, fixed map[int]float64, config MinuitConfig) (float64, int, error) {
+	c := *problem
+	c.Values = slices.Clone(problem.Values)
+	c.Fixed = slices.Clone(problem.Fixed)
+	for par, value := range fixed {
+		c.Values[par], c.Fixed[par] = value, true
+	}
+	if len(c.Free()) == 0 {
+		return c.Function(c.Values), 1, nil
+	}
+	_, res, err := minuitMinimum(&c, config)
+	if err != nil {
+		return 0, 0, err
+	}
+	return res.FVal, res.NFcn, nil
+}
+
+// crossing searches the offset t > 0 where rise(t) becomes 0, rise is negative at 0 and grows with t
+// the offset is doubled from start until the rise is reached or limit is hit, then the crossing is interpolated
+func crossing(ctx context.Context, rise func(t float64) (float64, error), up, start, limit float64) (float64, bool, error) {
+	lo, rlo := 0.0, -up
+	hi := min(start, limit)
+	var rhi float64
+	for i := 0; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+		r, err := rise(hi)
+		if err != nil {
+			return 0, false, err
+		}
+		if r >= 0 {
+			rhi = r
+			break
+		}
+		if hi == limit {
+			return limit, true, nil
+		}
+		if i == 30 {
+			return 0, false, errNoCrossing
+		}
+		lo, rlo = hi, r
+		hi = min(2*hi, limit)
+	}
+
+	// false position, the Illinois variant halves the rise of an end kept twice
+	side := 0
+	for range 50 {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+		t := hi - rhi*(hi-lo)/(rhi-rlo)
+		r, err := rise(t)
+		if err != nil {
+			return 0, false, err
+		}
+		if math.Abs(r) < 1e-4*up || hi-lo < 1e-9*hi {
+			return t, false, nil
+		}
+		if r > 0 {
+			hi, rhi = t, r
+			if side == 1 {
+				rlo /= 2
+			}
+			side = 1
+		} else {
+			lo, rlo = t, r
+			if side == -1 {
+				rhi /= 2
+			}
+			side = -1
+		}
+	}
+	return 0, false, errNoCrossing
+}
+
+// distance from the value to the limit in the direction, infinite without limit
+func limitDistance(problem *FitProblem, par int, value, dir float64) float64 {
+	if dir < 0 {
+		return value - problem.Minima[par]
+	}
+	return problem.Maxima[par] - value
+}
+
+// Minos minimizes the problem with Migrad and calculates the Minos errors of the parameters, all free ones if none
+// are given, the context is checked between the minimizations
+// the errors of minuit2go break on copied parameter states, so the crossings are searched on the profile of the
+// function, minimized by Migrad with the parameter fixed
+func Minos(ctx context.Context, problem *FitProblem, pars []int, config MinuitConfig) ([]MinosError, *FitResult, error) {
+	if pars == nil {
+		pars = problem.Free()
+	}
+	for _, par := range pars {
+		if err := checkFree(problem, par); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	_, res, err := minuitMinimum(problem, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	minimum := problem.WithValues(res.Parameters)
+
+	errs := make([]MinosError, 0, len(pars))
+	for _, par := range pars {
+		e := MinosError{Parameter: par, Value: res.Parameters[par], Error: res.Errors[par], Valid: true}
+		for _, dir := range []float64{-1, 1} {
+			rise := func(t float64) (float64, error) {
+				fval, calls, err := profile(minimum, map[int]float64{par: e.Value + dir*t}, config)
+				res.NFcn += calls
+				return fval - res.FVal - 1, err
+			}
+			offset, atLimit, err := crossing(ctx, rise, 1, cmp.Or(e.Error, problem.Steps[par]), limitDistance(problem, par, e.Value, dir))
+			switch {
+			case errors.Is(err, errNoCrossing):
+				e.Valid = false
+			case err != nil:
+				return errs, res, err
+			case dir < 0:
+				e.Lower, e.AtLowerLimit = -offset, atLimit
+			default:
+				e.Upper, e.AtUpperLimit = offset, atLimit
+			}
+		}
+		errs = append(errs, e)
+	}
+	return errs, res, nil
+}
+
+// Contour minimizes the problem with Migrad and returns points of the contour of the parameters px and py where
+// the function rises by up above its minimum, the other free parameters are minimized at every point
+// up is 1 for the 1σ errors of the single parameters, ContourUp68 and ContourUp95 for the confidence regions of both
+// the points are searched on rays from the minimum in the directions of equal angles, scaled by the errors of the
+// parameters, a ray without crossing is left out and one crossing a limit ends at it
+func Contour(ctx context.Context, problem *FitProblem, px, py, points int, up float64, config MinuitConfig) ([]ContourPoint, *FitResult, error) {
+	for _, par := range []int{px, py} {
+		if err := checkFree(problem, par); err != nil {
+			return nil, nil, err
+		}
+	}
+	if px == py {
+		return nil, nil, errors.New("a contour needs two different parameters")
+	}
+	if points < 4 {
+		return nil, nil, errors.New("a contour needs at least 4 points")
+	}
+	if up <= 0 {
+		return nil, nil, errors.New("the rise of the function has to be positive")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	_, res, err := minuitMinimum(problem, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	minimum := problem.WithValues(res.Parameters)
+	x, y := res.Parameters[px], res.Parameters[py]
+	ex, ey := cmp.Or(res.Errors[px], problem.Steps[px]), cmp.Or(res.Errors[py], problem.Steps[py])
+
+	result := make([]ContourPoint, 0, points)
+	for k := range points {
+		angle := 2 * math.Pi * float64(k) / float64(points)
+		dx, dy := ex*math.Cos(angle), ey*math.Sin(angle)
+		// the ray ends at the first limit it hits
+		limit := math.Inf(1)
+		if dx != 0 {
+			limit = min(limit, limitDistance(problem, px, x, dx)/math.Abs(dx))
+		}
+		if dy != 0 {
+			limit = min(limit, limitDistance(problem, py, y, dy)/math.Abs(dy))
+		}
+		rise := func(t float64) (float64, error) {
+			fval, calls, err := profile(minimum, map[int]float64{px: x + t*dx, py: y + t*dy}, config)
+			res.NFcn += calls
+			return fval - res.FVal - up, err
+		}
+		t, _, err := crossing(ctx, rise, up, math.Sqrt(up), limit)
+		if errors.Is(err, errNoCrossing) {
+			continue
+		}
+		if err != nil {
+			return nil, res, err
+		}
+		result = append(result, ContourPoint{X: x + t*dx, Y: y + t*dy})
+	}
+	if len(result) == 0 {
+		return nil, res, errors.New("no point of the contour was found")
+	}
+	return result, res, nil
+}
@@ -0,0 +1,196 @@
+package minimizer
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	problem := quadraticProblem()
+	points, err := Scan(problem, 0, 11, -4, 6)
+	if err != nil {
+		t.Fatalf("scan failed: %s", err)
+	}
+	if len(points) != 11 {
+		t.Fatalf("expected 11 points but got %d", len(points))
+	}
+	// the other parameters keep their values (0, 3)
+	for _, p := range points {
+		if math.Abs(p.FVal-(math.Pow(p.Value-1, 2)+49)) > 1e-9 {
+			t.Errorf("expected %g at %g but got %g", math.Pow(p.Value-1, 2)+49, p.Value, p.FVal)
+		}
+	}
+	if points[0].Value != -4 || points[10].Value != 6 {
+		t.Errorf("expected the scan from -4 to 6 but got %g to %g", points[0].Value, points[10].Value)
+	}
+
+	// the range is clipped to the limits
+	problem.Minima[0], problem.Maxima[0] = 0, 2
+	points, err = Scan(problem, 0, 5, -4, 6)
+	if err != nil {
+		t.Fatalf("scan failed: %s", err)
+	}
+	if points[0].Value != 0 || points[4].Value != 2 {
+		t.Errorf("expected the scan from 0 to 2 but got %g to %g", points[0].Value, points[4].Value)
+	}
+
+	// without a range ±2 steps around the value
+	points, err = Scan(quadraticProblem(), 1, 5, 0, 0)
+	if err != nil {
+		t.Fatalf("scan failed: %s", err)
+	}
+	if points[0].Value != -2 || points[2].Value != 0 || points[4].Value != 2 {
+		t.Errorf("expected the scan from -2 to 2 but got %g to %g", points[0].Value, points[4].Value)
+	}
+	if _, err := Scan(problem, 0, 5, 3, 6); err == nil {
+		t.Errorf("expected an error for a range outside the limits")
+	}
+
+	if _, err := Scan(problem, 2, 5, 0, 1); err == nil {
+		t.Errorf("expected an error for a fixed parameter")
+	}
+	if _, err := Scan(problem, 0, MaxScanPoints+1, 0, 1); err == nil {
+		t.Errorf("expected an error for too many points")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	points, err := Profile(context.Background(), quadraticProblem(), 0, 5, -1, 3, MinuitConfig{})
+	if err != nil {
+		t.Fatalf("profile failed: %s", err)
+	}
+	if len(points) != 5 {
+		t.Fatalf("expected 5 points but got %d", len(points))
+	}
+	// y is minimized at every point, z keeps its value 3
+	for _, p := range points {
+		if expected := math.Pow(p.Value-1, 2) + 9; math.Abs(p.FVal-expected) > 1e-3 {
+			t.Errorf("expected %g at %g but got %g", expected, p.Value, p.FVal)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Profile(ctx, quadraticProblem(), 0, 5, -1, 3, MinuitConfig{}); err == nil {
+		t.Errorf("expected an error for a cancelled context")
+	}
+	if _, err := Profile(context.Background(), quadraticProblem(), 0, 5, 3, -1, MinuitConfig{}); err == nil {
+		t.Errorf("expected an error for an inverted range")
+	}
+}
+
+func TestMinos(t *testing.T) {
+	errs, res, err := Minos(context.Background(), quadraticProblem(), nil, MinuitConfig{})
+	if err != nil {
+		t.Fatalf("minos failed: %s", err)
+	}
+	if math.Abs(res.Parameters[0]-1) > 1e-3 || math.Abs(res.Parameters[1]+2) > 1e-3 {
+		t.Errorf("expected minimum at (1, -2) but got (%g, %g)", res.Parameters[0], res.Parameters[1])
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected errors of the 2 free parameters but got %d", len(errs))
+	}
+	// the function rises by 1 at ±1 for x and ±1/√10 for y
+	for i, expected := range []float64{1, 1 / math.Sqrt(10)} {
+		e := errs[i]
+		if !e.Valid || math.Abs(e.Lower+expected) > 1e-3 || math.Abs(e.Upper-expected) > 1e-3 {
+			t.Errorf("parameter %d: expected ±%g but got %g/+%g (valid %t)", i, expected, e.Lower, e.Upper, e.Valid)
+		}
+	}
+
+	// a crossing beyond the limit ends at the limit
+	problem := quadraticProblem()
+	problem.Minima[0], problem.Maxima[0] = 0.5, 10
+	errs, _, err = Minos(context.Background(), problem, []int{0}, MinuitConfig{Strategy: StrategyPrecise})
+	if err != nil {
+		t.Fatalf("minos failed: %s", err)
+	}
+	if !errs[0].AtLowerLimit || math.Abs(errs[0].Lower+0.5) > 1e-2 {
+		t.Errorf("expected the lower error at the limit -0.5 but got %g (at limit %t)", errs[0].Lower, errs[0].AtLowerLimit)
+	}
+
+	if _, _, err := Minos(context.Background(), quadraticProblem(), []int{2}, MinuitConfig{}); err == nil {
+		t.Errorf("expected an error for a fixed parameter")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := Minos(ctx, quadraticProblem(), nil, MinuitConfig{}); err == nil {
+		t.Errorf("expected an error for a cancelled context")
+	}
+}
+
+func TestContour(t *testing.T) {
+	points, res, err := Contour(context.Background(), quadraticProblem(), 0, 1, 12, 1, MinuitConfig{})
+	if err != nil {
+		t.Fatalf("contour failed: %s", err)
+	}
+	if len(points) < 4 {
+		t.Fatalf("expected at least 4 points but got %d", len(points))
+	}
+	if res.NFcn <= 0 {
+		t.Errorf("expected function calls to be counted")
+	}
+	// every point is on the ellipse (x-1)² + 10(y+2)² = 1
+	for _, p := range points {
+		if r := math.Pow(p.X-1, 2) + 10*math.Pow(p.Y+2, 2); math.Abs(r-1) > 1e-2 {
+			t.Errorf("point (%g, %g) is not on the contour, its rise is %g", p.X, p.Y, r)
+		}
+	}
+
+	if _, _, err := Contour(context.Background(), quadraticProblem(), 0, 0, 12, 1, MinuitConfig{}); err == nil {
+		t.Errorf("expected an error for the same parameters")
+	}
+	if _, _, err := Contour(context.Background(), quadraticProblem(), 0, 2, 12, 1, MinuitConfig{}); err == nil {
+		t.Errorf("expected an error for a fixed parameter")
+	}
+}
+
+func TestStrategy(t *testing.T) {
+	for _, s := range Strategies {
+		parsed, err := ParseStrategy(s.String())
+		if err != nil || parsed != s {
+			t.Errorf("expected %s but got %s (%v)", s, parsed, err)
+		}
+	}
+	if _, err := ParseStrategy("Slow"); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+
+	// every strategy converges
+	for _, s := range Strategies {
+		event, err := RunFit(context.Background(), quadraticProblem(), FitConfig{Strategy: s})
+		if err != nil || event.Kind != FitConverged {
+			t.Errorf("%s: expected convergence but got %s (%v)", s, event.Kind, err)
+		}
+	}
+}
+
+func TestStrategyAutoCallLimit(t *testing.T) {
+	rosenbrock := func(par []float64) float64 {
+		return 100*math.Pow(par[1]-par[0]*par[0], 2) + math.Pow(1-par[0], 2)
+	}
+	problem := NewFitProblem(rosenbrock, []float64{-1.2, 1})
+	engine, err := NewMigradEngine(problem.withConfig(FitConfig{Strategy: StrategyAuto}))
+	if err != nil {
+		t.Fatalf("could not create engine: %s", err)
+	}
+
+	// a minimum invalid only due to the calls of the iteration stays with the standard strategy
+	res, err := engine.Iterate(20)
+	if err != nil {
+		t.Fatalf("iteration failed: %s", err)
+	}
+	if res.Valid || engine.(*minuitEngine).precise {
+		t.Errorf("expected an invalid minimum at the call limit continued with the standard strategy")
+	}
+
+	for i := 0; i < 500 && !res.Converged; i++ {
+		if res, err = engine.Iterate(20); err != nil {
+			t.Fatalf("iteration failed: %s", err)
+		}
+	}
+	if !res.Converged || math.Abs(res.Parameters[0]-1) > 1e-2 || math.Abs(res.Parameters[1]-1) > 1e-2 {
+		t.Errorf("expected the minimum at (1, 1) but got (%g, %g)", res.Parameters[0], res.Parameters[1])
+	}
+}
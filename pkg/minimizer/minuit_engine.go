@@ -0,0 +1,170 @@
+package minimizer
+
+import (
+	"fmt"
+	"math"
+
+	minuit "github.com/empack/minuit2go/pkg"
+)
+
+// adapts a plain function to the minuit function interface
+type fitFunction func(parameter []float64) float64
+
+func (f fitFunction) ValueOf(par []float64) float64 {
+	return f(par)
+}
+
+// Strategy is the Minuit strategy, it trades function calls for the precision of the gradient and the errors
+type Strategy int
+
+const (
+	// the standard strategy, continued with the precise one if the minimum is invalid
+	StrategyAuto Strategy = iota
+	StrategyFast
+	StrategyStandard
+	StrategyPrecise
+)
+
+// Strategies are all strategies in the order of their precision, starting with the default
+var Strategies = []Strategy{StrategyAuto, StrategyFast, StrategyStandard, StrategyPrecise}
+
+func (s Strategy) String() string {
+	switch s {
+	case StrategyAuto:
+		return "Auto"
+	case StrategyFast:
+		return "Fast"
+	case StrategyStandard:
+		return "Standard"
+	case StrategyPrecise:
+		return "Precise"
+	}
+	return "Unknown"
+}
+
+// ParseStrategy returns the strategy with the name
+func ParseStrategy(name string) (Strategy, error) {
+	for _, s := range Strategies {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return StrategyAuto, fmt.Errorf("unknown strategy %q", name)
+}
+
+// minuit strategy of the strategy, auto starts with the standard one
+func (s Strategy) minuit() *minuit.MnStrategy {
+	switch s {
+	case StrategyFast:
+		return minuit.NewMnStrategyWithStra(minuit.FastStrategy)
+	case StrategyPrecise:
+		return minuit.NewMnStrategyWithStra(minuit.PreciseStrategy)
+	}
+	return minuit.NewMnStrategyWithStra(minuit.StandardStrategy)
+}
+
+// minuitApplication is a Minuit minimizer like MnMigrad
+// MnSimplex and MnMinimize of minuit2go aren't used, they aren't linked to their base application and panic
+type minuitApplication interface {
+	MinimizeWithMaxfcn(maxfcn int) (*minuit.FunctionMinimum, error)
+}
+
+// creates a Minuit minimizer starting at the parameter state
+type minuitFactory func(fcn minuit.FCNBase, state *minuit.MnUserParameterState, strategy *minuit.MnStrategy) minuitApplication
+
+func newMigrad(fcn minuit.FCNBase, state *minuit.MnUserParameterState, strategy *minuit.MnStrategy) minuitApplication {
+	return minuit.NewMnMigradWithParameterStateStrategy(fcn, state, strategy)
+}
+
+// minuitEngine runs a Minuit minimizer, with the auto strategy an invalid minimum is continued with the precise strategy
+// once, a minimum ending at the calls of the iteration is continued by the next iteration with the same strategy
+// it converges at a valid minimum with an EDM below the tolerance
+type minuitEngine struct {
+	fcn       fitFunction
+	params    *minuit.MnUserParameters
+	strategy  Strategy
+	tolerance float64
+	factory   minuitFactory
+	current   minuitApplication
+	precise   bool
+	calls     int
+}
+
+// NewMigradEngine creates a fit engine using Minuit Migrad
+func NewMigradEngine(problem *FitProblem) (FitEngine, error) {
+	return newMinuitEngine(problem, newMigrad), nil
+}
+
+func newMinuitEngine(problem *FitProblem, factory minuitFactory) *minuitEngine {
+	engine := &minuitEngine{
+		fcn:       problem.Function,
+		params:    minuitParameters(problem),
+		strategy:  problem.strategy,
+		tolerance: DefaultEDMTolerance,
+		factory:   factory,
+	}
+	// without job or with a disabled criterion of the job the engine stops at the tolerance of Migrad
+	if problem.edmTolerance > 0 {
+		engine.tolerance = problem.edmTolerance
+	}
+	return engine
+}
+
+// minuitParameters converts the parameters of the problem, the fixed ones are constant
+func minuitParameters(problem *FitProblem) *minuit.MnUserParameters {
+	params := minuit.NewEmptyMnUserParameters()
+	for i, v := range problem.Values {
+		id := fmt.Sprintf("p%d", i)
+		lower, upper := problem.Minima[i], problem.Maxima[i]
+
+		switch {
+		case problem.Fixed[i]:
+			params.Add(id, v)
+		case !math.IsInf(lower, 0) && !math.IsInf(upper, 0):
+			params.AddLimited(id, v, problem.Steps[i], lower, upper)
+		default:
+			params.AddFree(id, v, problem.Steps[i])
+			if !math.IsInf(lower, 0) {
+				params.SetLowerLimit(i, lower)
+			}
+			if !math.IsInf(upper, 0) {
+				params.SetUpperLimit(i, upper)
+			}
+		}
+	}
+	return params
+}
+
+func (m *minuitEngine) Iterate(maxCalls int) (*FitResult, error) {
+	if m.current == nil {
+		m.current = m.factory(m.fcn, minuit.NewUserParameterStateFromUserParameter(m.params), m.strategy.minuit())
+	}
+
+	res, err := m.current.MinimizeWithMaxfcn(maxCalls)
+	if err != nil {
+		return nil, err
+	}
+	m.calls += res.Nfcn()
+
+	// the reasons of an invalid minimum are unexported in minuit2go, f.e. an EDM above the maximum or a failed
+	// covariance, a minimum using all calls of the iteration reached the call limit and isn't switched
+	if !res.IsValid() && res.Nfcn() < maxCalls && m.strategy == StrategyAuto && !m.precise {
+		m.precise = true
+		m.current = m.factory(m.fcn, res.UserState(), StrategyPrecise.minuit())
+		res, err = m.current.MinimizeWithMaxfcn(maxCalls)
+		if err != nil {
+			return nil, err
+		}
+		m.calls += res.Nfcn()
+	}
+
+	return &FitResult{
+		Parameters: res.UserParameters().Params(),
+		Errors:     res.UserParameters().Errors(),
+		FVal:       res.Fval(),
+		EDM:        res.Edm(),
+		Valid:      res.IsValid(),
+		NFcn:       m.calls,
+		Converged:  res.IsValid() && res.Edm() < m.tolerance,
+	}, nil
+}
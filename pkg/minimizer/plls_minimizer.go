@@ -50,8 +50,9 @@ func (p *parallelLinearLocalSearch[T]) plsWorker(id int, wg *sync.WaitGroup, pro
 	// run minimisation
 	for i := 0; i < maxIterations; i++ {
 		if useLock {
+			// other workers write to the shared parameters, so take a copy while locked
 			problem.lock.RLock()
-			parameters = problem.parameter
+			parameters = slices.Clone(problem.parameter)
 			problem.lock.RUnlock()
 		}
 		var parameter T
@@ -0,0 +1,158 @@
+package minimizer
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// SAConfig configures the simulated annealing
+type SAConfig struct {
+	// number of walkers moving in parallel, 0 uses 8 walkers
+	Walkers int
+	// start temperature, 0 estimates it from the spread of the errors in the search range
+	Temperature float64
+	// the temperature is multiplied by the cooling factor after every loop, 0 uses 0.99
+	Cooling float64
+	// the annealing stops once the temperature dropped by this factor, 0 uses 1e-6
+	Tolerance float64
+	// seed of the random number generator, the same seed gives the same result
+	Seed uint64
+}
+
+type simulatedAnnealing struct {
+	config SAConfig
+	rng    *rand.Rand
+
+	walkers          [][]float64
+	errors           []float64
+	best             []float64
+	bestError        float64
+	startTemperature float64
+	temperature      float64
+	lower            []float64
+	upper            []float64
+	done             bool
+}
+
+// NewSimulatedAnnealing creates a global minimizer moving several walkers randomly through the parameter space,
+// moves uphill are accepted with a probability shrinking with the temperature (Metropolis criterion)
+// every loop moves all walkers once, the moves are evaluated in parallel
+// the minimizer keeps its walkers, so create one per problem
+func NewSimulatedAnnealing(config SAConfig) Minimizer[float64] {
+	if config.Walkers <= 0 {
+		config.Walkers = 8
+	}
+	if config.Cooling == 0 {
+		config.Cooling = 0.99
+	}
+	if config.Tolerance == 0 {
+		config.Tolerance = 1e-6
+	}
+	return &simulatedAnnealing{
+		config: config,
+		rng:    rand.New(rand.NewPCG(config.Seed, config.Seed^0x9e3779b97f4a7c15)),
+	}
+}
+
+func (s *simulatedAnnealing) callsPerLoop(int) int {
+	return s.config.Walkers
+}
+
+func (s *simulatedAnnealing) converged() bool {
+	return s.done
+}
+
+// all walkers start at the start value
+func (s *simulatedAnnealing) initialize(problem *AsyncMinimiserProblem[float64]) {
+	problem.lock.RLock()
+	x0 := slices.Clone(problem.parameter)
+	s.lower, s.upper = searchBounds(x0, problem.minima, problem.maxima)
+	problem.lock.RUnlock()
+
+	s.walkers = make([][]float64, s.config.Walkers)
+	s.errors = make([]float64, s.config.Walkers)
+	s.best = x0
+	s.bestError = problem.errorFunction(x0)
+	for i := range s.walkers {
+		s.walkers[i] = slices.Clone(x0)
+		s.errors[i] = s.bestError
+	}
+
+	s.temperature = s.config.Temperature
+	if s.temperature <= 0 {
+		s.temperature = s.estimateTemperature(problem)
+	}
+	s.startTemperature = s.temperature
+}
+
+// uses the standard deviation of the errors of random points in the search range as start temperature
+func (s *simulatedAnnealing) estimateTemperature(problem *AsyncMinimiserProblem[float64]) float64 {
+	samples := make([][]float64, s.config.Walkers)
+	for i := range samples {
+		samples[i] = s.randomPoint()
+	}
+	errs := evaluateParallel(problem, samples)
+
+	mean := 0.0
+	for _, e := range errs {
+		mean += e
+	}
+	mean /= float64(len(errs))
+	variance := 0.0
+	for _, e := range errs {
+		variance += (e - mean) * (e - mean)
+	}
+	t := math.Sqrt(variance / float64(len(errs)))
+	if t == 0 || math.IsNaN(t) || math.IsInf(t, 0) {
+		return 1
+	}
+	return t
+}
+
+func (s *simulatedAnnealing) randomPoint() []float64 {
+	p := make([]float64, len(s.lower))
+	for j := range p {
+		p[j] = s.lower[j] + s.rng.Float64()*(s.upper[j]-s.lower[j])
+	}
+	return p
+}
+
+func (s *simulatedAnnealing) Minimize(problem *AsyncMinimiserProblem[float64]) {
+	if s.walkers == nil {
+		s.initialize(problem)
+	}
+
+	for shouldContinue(problem) && !s.done {
+		// the moves get smaller while cooling down, all random numbers are drawn here so the result only depends on the seed
+		width := 0.1 * math.Sqrt(s.temperature/s.startTemperature)
+		moves := make([][]float64, len(s.walkers))
+		accept := make([]float64, len(s.walkers))
+		for i, walker := range s.walkers {
+			move := slices.Clone(walker)
+			for j := range move {
+				v := move[j] + s.rng.NormFloat64()*width*(s.upper[j]-s.lower[j])
+				move[j] = reflectInto(v, s.lower[j], s.upper[j])
+			}
+			moves[i] = move
+			accept[i] = s.rng.Float64()
+		}
+
+		moveErrors := evaluateParallel(problem, moves)
+		for i := range moves {
+			delta := moveErrors[i] - s.errors[i]
+			if delta <= 0 || accept[i] < math.Exp(-delta/s.temperature) {
+				s.walkers[i] = moves[i]
+				s.errors[i] = moveErrors[i]
+			}
+			if s.errors[i] < s.bestError {
+				s.best = slices.Clone(s.walkers[i])
+				s.bestError = s.errors[i]
+			}
+		}
+
+		s.temperature *= s.config.Cooling
+		s.done = s.temperature < s.startTemperature*s.config.Tolerance
+		publishBest(problem, slices.Clone(s.best), s.done)
+	}
+}
@@ -0,0 +1,41 @@
+package minimizer
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSimulatedAnnealingGlobalMinimum(t *testing.T) {
+	problem := NewProblem([]float64{3.2, -2.9}, []float64{-5.12, -5.12}, []float64{5.12, 5.12}, rastrigin, &MinimiserConfig{
+		LoopCount:     1e5,
+		ParallelReads: true,
+	})
+
+	NewSimulatedAnnealing(SAConfig{Seed: 1}).Minimize(problem)
+
+	res, err := problem.GetCurrentParameters()
+	if err != nil {
+		t.Fatalf("Failed to get parameters from problem after minimizer should have finished: %s", err.Error())
+	}
+	// the result is refined locally afterwards, it only has to be in the basin of the global minimum
+	if math.Abs(res[0]) > 0.1 || math.Abs(res[1]) > 0.1 {
+		t.Errorf("Minimizer failed to find the global minimum expected {0,0} but got {%f,%f}", res[0], res[1])
+	}
+}
+
+func TestSimulatedAnnealingSeed(t *testing.T) {
+	run := func(seed uint64) []float64 {
+		problem := NewProblem([]float64{3.2, -2.9}, []float64{-5.12, -5.12}, []float64{5.12, 5.12}, rastrigin, &MinimiserConfig{
+			LoopCount:     100,
+			ParallelReads: true,
+		})
+		NewSimulatedAnnealing(SAConfig{Seed: seed}).Minimize(problem)
+		res, _ := problem.GetCurrentParameters()
+		return res
+	}
+
+	if a, b := run(7), run(7); !slices.Equal(a, b) {
+		t.Errorf("expected the same result for the same seed but got %v and %v", a, b)
+	}
+}
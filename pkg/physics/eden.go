@@ -11,7 +11,7 @@ import (
 // => give each function a unique name (GetEdensities1/GetXYEdensities)
 // => insert the kind of parameters the calculation needs to the first bracket GetEdensities(param_1 type_1, ..., param_n type_n)
 // => insert your calculation
-// => continue by adapting RecalculateData and penaltyFunction in PortGUIPhysics\pkg\gui\main.go
+// => continue by adapting RecalculateData and calculatePenalty in PortGUIPhysics\pkg\gui\main.go
 
 // GetEdensities returns DataPoints based on the old implementation of the old getEden function
 // - eden is an array with all the eden values {eden_a,eden_1,eden_2,...,eden_n,eden_b} (edensity)
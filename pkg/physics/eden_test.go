@@ -0,0 +1,117 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEdensities(t *testing.T) {
+	eden := []float64{0, 0.3, 0.45, 0.334}
+	d := []float64{15, 25}
+	edens, err := GetEdensities(eden, d, []float64{3, 2, 4})
+	assert.NoError(t, err)
+	assert.Len(t, edens, ZNUMBER)
+
+	// the profile has the layer values far from the interfaces at 0, 15 and 40 Å
+	zaxis := GetZAxis(d, ZNUMBER)
+	for i, p := range edens {
+		assert.Equal(t, zaxis[i], p.X)
+		switch {
+		case p.X < -20:
+			assert.InDelta(t, eden[0], p.Y, 1e-6)
+		case p.X > 3*4 && p.X < 15-3*4:
+			assert.InDelta(t, eden[1], p.Y, 1e-3)
+		case p.X > 15+3*2 && p.X < 40-3*4:
+			assert.InDelta(t, eden[2], p.Y, 1e-3)
+		case p.X > 40+5*4:
+			assert.InDelta(t, eden[3], p.Y, 1e-6)
+		}
+	}
+
+	// a single interface is an error function, its center is the mean of both sides
+	edens, err = GetEdensities([]float64{0, 0.334}, []float64{}, []float64{3})
+	assert.NoError(t, err)
+	for _, p := range edens {
+		assert.InDelta(t, 0.334*0.5*(1+math.Erf(p.X/(3*math.Sqrt2))), p.Y, 1e-12)
+	}
+
+	_, err = GetEdensities([]float64{0, 0.3, 0.334}, []float64{15, 25}, []float64{3, 2, 4})
+	assert.Error(t, err)
+	_, err = GetEdensities(eden, d, []float64{3, 2})
+	assert.Error(t, err)
+}
+
+func TestZeroRoughnessProfile(t *testing.T) {
+	eden := []float64{0, 0.45, 0.334}
+	edens, err := GetEdensities(eden, []float64{20}, []float64{1e-9, 1e-9})
+	assert.NoError(t, err)
+
+	// a step profile, the samples lie beside the interfaces
+	for _, p := range edens {
+		switch {
+		case p.X < 0:
+			assert.Equal(t, eden[0], p.Y, "z = %g", p.X)
+		case p.X > 0 && p.X < 20:
+			assert.InDelta(t, eden[1], p.Y, 1e-15, "z = %g", p.X)
+		case p.X > 20:
+			assert.InDelta(t, eden[2], p.Y, 1e-15, "z = %g", p.X)
+		}
+	}
+}
+
+func TestGetZAxis(t *testing.T) {
+	// 20 Å before the first interface and 30 Å after the last one
+	zaxis := GetZAxis([]float64{15, 25}, 100)
+	assert.Len(t, zaxis, 100)
+	assert.Equal(t, -20.0, zaxis[0])
+	assert.InDelta(t, 0.9, zaxis[1]-zaxis[0], 1e-12)
+	assert.InDelta(t, 70-0.9, zaxis[99], 1e-9)
+}
+
+func TestConvolute(t *testing.T) {
+	zaxis := GetZAxis([]float64{20}, ZNUMBER)
+	step := make(function.Points, len(zaxis))
+	constant := make(function.Points, len(zaxis))
+	for i, z := range zaxis {
+		step[i] = &function.Point{X: z, Y: 0, Error: 0.01}
+		if z >= 10 {
+			step[i].Y = 1
+		}
+		constant[i] = &function.Point{X: z, Y: 0.334}
+	}
+
+	// a roughness below the sampling changes nothing
+	sharp := convolute(len(zaxis), zaxis, step, 0.1)
+	for i := range step {
+		assert.Equal(t, step[i].Y, sharp[i].Y)
+		assert.Equal(t, step[i].X, sharp[i].X)
+		assert.Equal(t, step[i].Error, sharp[i].Error)
+	}
+
+	for _, p := range convolute(len(zaxis), zaxis, constant, 3) {
+		assert.InDelta(t, 0.334, p.Y, 1e-12)
+	}
+
+	// the smoothed step rises monotonically from 0 to 1 within the window of ±2σ
+	smooth := convolute(len(zaxis), zaxis, step, 3)
+	for i, p := range smooth {
+		assert.GreaterOrEqual(t, p.Y, 0.0)
+		assert.LessOrEqual(t, p.Y, 1+1e-12)
+		if i > 0 {
+			assert.GreaterOrEqual(t, p.Y, smooth[i-1].Y-1e-12)
+		}
+		if p.X < 10-6 {
+			assert.Equal(t, 0.0, p.Y)
+		}
+		if p.X > 10+6 {
+			assert.InDelta(t, 1.0, p.Y, 1e-12)
+		}
+		if p.X > 10-3 && p.X < 10+3 {
+			assert.Greater(t, p.Y, 0.0)
+			assert.Less(t, p.Y, 1.0)
+		}
+	}
+}
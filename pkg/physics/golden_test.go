@@ -0,0 +1,80 @@
+package physics
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test ./pkg/physics -run TestReferenceCurves -update rewrites the reference curves after an intended change
+var update = flag.Bool("update", false, "rewrite the reference curves in testdata")
+
+// referenceCurve is a model with its intensity stored in testdata
+type referenceCurve struct {
+	file   string
+	eden   []float64
+	d      []float64
+	sigma  []float64
+	deltaq float64
+	opts   *IntensityOptions
+}
+
+var referenceCurves = []referenceCurve{
+	{file: "reference_substrate.dat", eden: []float64{0, 0.334}, d: []float64{}, sigma: []float64{3}},
+	{file: "reference_one_layer.dat", eden: []float64{0, 0.45, 0.334}, d: []float64{20}, sigma: []float64{3, 3}},
+	{
+		file: "reference_two_layers.dat", eden: []float64{0, 0.3, 0.45, 0.334}, d: []float64{15, 25}, sigma: []float64{3, 2, 4},
+		deltaq: 0.001, opts: &IntensityOptions{Background: 1e-7, Scaling: 0.9},
+	},
+}
+
+// qz axis of the reference curves
+func referenceQZ() []float64 {
+	qz := make([]float64, 100)
+	for i := range qz {
+		qz[i] = 0.005 * float64(i+1)
+	}
+	return qz
+}
+
+// writes the points in the format of the data import, with zero errors
+func writeReferenceCurve(file string, points function.Points) error {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "%d\n", len(points))
+	for _, p := range points {
+		fmt.Fprintf(b, "%.10E\t%.10E\t%.10E\n", p.X, p.Y, p.Error)
+	}
+	return os.WriteFile(file, b.Bytes(), 0644)
+}
+
+func TestReferenceCurves(t *testing.T) {
+	for _, reference := range referenceCurves {
+		t.Run(reference.file, func(t *testing.T) {
+			edens, err := GetEdensities(reference.eden, reference.d, reference.sigma)
+			assert.NoError(t, err)
+			intensity := CalculateIntensityPointsOn(referenceQZ(), edens, reference.deltaq, reference.opts)
+
+			file := path.Join("..", "..", "testdata", reference.file)
+			if *update {
+				assert.NoError(t, writeReferenceCurve(file, intensity))
+			}
+
+			content, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			expected, err := data.Parse(content)
+			assert.NoError(t, err)
+			assert.Len(t, intensity, len(expected))
+			for i := range min(len(expected), len(intensity)) {
+				assert.InEpsilon(t, expected[i].X, intensity[i].X, 1e-9)
+				assert.InEpsilon(t, expected[i].Y, intensity[i].Y, 1e-8, "q = %g", expected[i].X)
+			}
+		})
+	}
+}
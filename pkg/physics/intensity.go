@@ -10,16 +10,15 @@ import (
 	"sort"
 )
 
-// need more than one? => Copy and rename it. Be careful when to use which axis.
-var qzAxis = GetDefaultQZAxis(qzNumber)
-var qzNumber = 500
+const defaultQZNumber = 500
 
 type IntensityOptions struct {
 	Background float64
 	Scaling    float64
 }
 
-func CalculateIntensityPoints(edenPoints function.Points, deltaq float64, opts *IntensityOptions) function.Points {
+// CalculateIntensityPointsOn calculates the intensity on the given qz axis, f.e. the axis of the data sets of a sample
+func CalculateIntensityPointsOn(qz []float64, edenPoints function.Points, deltaq float64, opts *IntensityOptions) function.Points {
 	// transform points into sld floats
 	sld := make([]float64, ZNUMBER)
 	for i, e := range edenPoints {
@@ -34,15 +33,15 @@ func CalculateIntensityPoints(edenPoints function.Points, deltaq float64, opts *
 
 	// calculate intensity
 
-	modifiedQzAxis := helper.Map(qzAxis, func(xPoint float64) float64 { return xPoint + deltaq })
+	modifiedQzAxis := helper.Map(qz, func(xPoint float64) float64 { return xPoint + deltaq })
 
 	intensity := CalculateIntensity(modifiedQzAxis, deltaz, sld, opts)
 
 	// creates list with intensity points based on edenPoints x and error and calculated intensity as y
-	intensityPoints := make(function.Points, qzNumber)
+	intensityPoints := make(function.Points, len(qz))
 	for i := range intensity {
 		intensityPoints[i] = &function.Point{
-			X:     qzAxis[i],
+			X:     qz[i],
 			Y:     intensity[i],
 			Error: 0.0,
 		}
@@ -137,6 +136,11 @@ func CalculateReflectivity(qzaxis []float64, deltaz float64, sld []float64) []fl
 	return refl
 }
 
+// FresnelReflectivity calculates the reflectivity of a single interface between the ambient medium and the substrate
+func FresnelReflectivity(qzaxis []float64, sldAmbient, sldSubstrate float64) []float64 {
+	return CalculateReflectivity(qzaxis, 0, []float64{sldAmbient, sldSubstrate})
+}
+
 func GetDefaultQZAxis(qzNumber int) []float64 {
 	qzAxis := make([]float64, qzNumber)
 	for i := 0; i < qzNumber; i++ {
@@ -145,28 +149,28 @@ func GetDefaultQZAxis(qzNumber int) []float64 {
 	return qzAxis
 }
 
-// use the combined experimental axis as current qz axis
-func AlterQZAxis(dataSets function.Functions, graphID string) {
-	if graphID == "intensity" {
-		var qzValues []float64
-		for _, dataSet := range dataSets {
-			for _, point := range dataSet.GetData() {
-				qzValues = append(qzValues, point.X)
-			}
+// QZAxisOf returns the combined sorted qz values of the data sets
+func QZAxisOf(dataSets ...function.Points) []float64 {
+	var qzValues []float64
+	for _, dataSet := range dataSets {
+		for _, point := range dataSet {
+			qzValues = append(qzValues, point.X)
 		}
-		sort.Float64s(qzValues)
-		qzValues = slices.Compact(qzValues)
-		qzAxis = qzValues
-		qzNumber = len(qzAxis)
 	}
-
+	sort.Float64s(qzValues)
+	return slices.Compact(qzValues)
 }
 
-// calculate a penalty between the calculated intensity and the loaded data sets
-func Sim2SigRMS(dataSets []function.Points, intensity function.Points) (float64, error) {
-	if len(intensity) != qzNumber {
-		return math.MaxFloat64, fmt.Errorf("rms calculation: intensity slice has the wrong length: %d vs %d", len(intensity), qzNumber)
+// QZAxisOrDefault returns the combined qz axis of the data sets, without data the default axis is used
+func QZAxisOrDefault(dataSets ...function.Points) []float64 {
+	if qz := QZAxisOf(dataSets...); len(qz) > 0 {
+		return qz
 	}
+	return GetDefaultQZAxis(defaultQZNumber)
+}
+
+// Sim2SigRMSOn calculates the qz² weighted penalty between the data sets and an intensity calculated on their qz values
+func Sim2SigRMSOn(dataSets []function.Points, intensity function.Points) (float64, error) {
 	var diff float64
 	for _, dataSet := range dataSets {
 		for _, point := range dataSet {
@@ -179,3 +183,77 @@ func Sim2SigRMS(dataSets []function.Points, intensity function.Points) (float64,
 	}
 	return diff, nil
 }
+
+// Chi2On is the χ² of the data sets against an intensity calculated on their qz values
+func Chi2On(dataSets []function.Points, intensity function.Points) (float64, error) {
+	var chi2 float64
+	for _, dataSet := range dataSets {
+		for _, point := range dataSet {
+			y_intensity, err := function.GetY(intensity, point.X)
+			if err != nil {
+				return math.MaxFloat64, fmt.Errorf("chi2 calculation: there is no intensity for: %f", point.X)
+			}
+			chi2 += math.Pow((y_intensity-point.Y)/point.Error, 2)
+		}
+	}
+	return chi2, nil
+}
+
+// LogDiffOn is the sum of the squared differences of the decadic logarithms of the data sets and the intensity,
+// every decade of the reflectivity is weighted alike, points <= 0 are skipped
+func LogDiffOn(dataSets []function.Points, intensity function.Points) (float64, error) {
+	var diff float64
+	for _, dataSet := range dataSets {
+		for _, point := range dataSet {
+			y_intensity, err := function.GetY(intensity, point.X)
+			if err != nil {
+				return math.MaxFloat64, fmt.Errorf("log calculation: there is no intensity for: %f", point.X)
+			}
+			if point.Y <= 0 || y_intensity <= 0 {
+				continue
+			}
+			diff += math.Pow(math.Log10(y_intensity)-math.Log10(point.Y), 2)
+		}
+	}
+	return diff, nil
+}
+
+// Merit is a figure of merit of an intensity against the data sets, the fits minimize it
+type Merit func(dataSets []function.Points, intensity function.Points) (float64, error)
+
+// DefaultMerit is the name of the figure of merit used if none is chosen
+const DefaultMerit = "q2chi2"
+
+// Merits are the figures of merit selectable by their names
+var Merits = map[string]Merit{
+	DefaultMerit: Sim2SigRMSOn,
+	"chi2":       Chi2On,
+	"log":        LogDiffOn,
+}
+
+// MeritNames returns the sorted names of the figures of merit
+func MeritNames() []string {
+	names := make([]string, 0, len(Merits))
+	for name := range Merits {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Residuals returns the normalized residuals (data - model) / error of a data set against the intensity
+// points without an error are skipped
+func Residuals(dataSet function.Points, intensity function.Points) (function.Points, error) {
+	residuals := make(function.Points, 0, len(dataSet))
+	for _, point := range dataSet {
+		if point.Error == 0 {
+			continue
+		}
+		y_intensity, err := function.GetY(intensity, point.X)
+		if err != nil {
+			return nil, fmt.Errorf("residuals: there is no intensity for: %f", point.X)
+		}
+		residuals = append(residuals, &function.Point{X: point.X, Y: (point.Y - y_intensity) / point.Error})
+	}
+	return residuals, nil
+}
@@ -0,0 +1,194 @@
+package physics
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// critical momentum transfer of total reflection at an interface with the sld step
+func criticalQ(deltaSLD float64) float64 {
+	return math.Sqrt(16 * math.Pi * deltaSLD)
+}
+
+// analytic reflectivity of a slab of the thickness between the ambient medium and the substrate (Airy formula)
+func slabReflectivity(q, sldAmbient, sldSlab, sldSubstrate, thickness float64) float64 {
+	kz := func(sld float64) complex128 {
+		return cmplx.Sqrt(complex(q*q/4-4*math.Pi*(sld-sldAmbient), 0))
+	}
+	k0, k1, k2 := kz(sldAmbient), kz(sldSlab), kz(sldSubstrate)
+	r01 := (k0 - k1) / (k0 + k1)
+	r12 := (k1 - k2) / (k1 + k2)
+	phase := cmplx.Exp(2i * k1 * complex(thickness, 0))
+	r := (r01 + r12*phase) / (1 + r01*r12*phase)
+	return math.Pow(cmplx.Abs(r), 2)
+}
+
+func TestFresnelReflectivity(t *testing.T) {
+	sld := 0.334 * ELECTRON_RADIUS
+	qc := criticalQ(sld)
+	for q := 0.001; q < 0.5; q += 0.001 {
+		r := FresnelReflectivity([]float64{q}, 0, sld)[0]
+		if q < qc {
+			assert.InDelta(t, 1, r, 1e-12, "total reflection at q = %g", q)
+			continue
+		}
+		// R = |(q - √(q² - qc²)) / (q + √(q² - qc²))|²
+		root := math.Sqrt(q*q - qc*qc)
+		assert.InEpsilon(t, math.Pow((q-root)/(q+root), 2), r, 1e-9, "q = %g", q)
+	}
+
+	// far above qc, R ≈ (qc / 2q)⁴
+	assert.InEpsilon(t, math.Pow(qc/(2*0.5), 4), FresnelReflectivity([]float64{0.5}, 0, sld)[0], 5e-3)
+}
+
+func TestSlabReflectivity(t *testing.T) {
+	// a slab of n media with the same sld is a single slab of the thickness n·Δz
+	const n, deltaz = 200, 0.5
+	sldSlab, sldSubstrate := 0.45*ELECTRON_RADIUS, 0.334*ELECTRON_RADIUS
+	sld := []float64{0}
+	for range n {
+		sld = append(sld, sldSlab)
+	}
+	sld = append(sld, sldSubstrate)
+
+	qz := make([]float64, 0)
+	for q := 0.001; q < 0.5; q += 0.001 {
+		qz = append(qz, q)
+	}
+	refl := CalculateReflectivity(qz, deltaz, sld)
+	for i, q := range qz {
+		assert.InEpsilon(t, slabReflectivity(q, 0, sldSlab, sldSubstrate, n*deltaz), refl[i], 1e-9, "q = %g", q)
+	}
+
+	// the minima of the Kiessig fringes are 2π/d apart far above qc
+	minima := make([]float64, 0)
+	for i := 1; i+1 < len(qz); i++ {
+		if qz[i] > 0.1 && refl[i] < refl[i-1] && refl[i] < refl[i+1] {
+			minima = append(minima, qz[i])
+		}
+	}
+	assert.GreaterOrEqual(t, len(minima), 3)
+	period := (minima[len(minima)-1] - minima[0]) / float64(len(minima)-1)
+	assert.InEpsilon(t, 2*math.Pi/(n*deltaz), period, 0.02)
+}
+
+func TestZeroRoughnessLimit(t *testing.T) {
+	// a nearly sharp profile sampled on the z axis is a stack of slabs, the interfaces lie between the samples
+	eden := []float64{0, 0.45, 0.334}
+	edens, err := GetEdensities(eden, []float64{20}, []float64{1e-6, 1e-6})
+	assert.NoError(t, err)
+
+	deltaz := edens[1].X - edens[0].X
+	layers := 0
+	for _, p := range edens {
+		if p.Y == 0.45 {
+			layers++
+		}
+	}
+	qz := []float64{0.05, 0.1, 0.2, 0.3}
+	intensity := CalculateIntensityPointsOn(qz, edens, 0, nil)
+	for i, q := range qz {
+		// the samples of the ambient medium and the substrate are slabs of their own sld, which reflect nothing
+		expected := slabReflectivity(q, 0, 0.45*ELECTRON_RADIUS, 0.334*ELECTRON_RADIUS, float64(layers)*deltaz)
+		assert.InEpsilon(t, expected, intensity[i].Y, 1e-9, "q = %g", q)
+	}
+}
+
+func TestReflectivityProperties(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	qz := make([]float64, 0)
+	for q := 0.0005; q < 0.6; q += 0.0005 {
+		qz = append(qz, q)
+	}
+
+	for range 20 {
+		// random profiles with up to four layers
+		layers := random.Intn(4) + 1
+		eden := []float64{0}
+		d := make([]float64, layers)
+		sigma := []float64{}
+		for i := range layers {
+			eden = append(eden, random.Float64())
+			d[i] = 5 + 40*random.Float64()
+			sigma = append(sigma, 0.5+5*random.Float64())
+		}
+		eden = append(eden, 0.2+0.6*random.Float64())
+		sigma = append(sigma, 0.5+5*random.Float64())
+
+		edens, err := GetEdensities(eden, d, sigma)
+		assert.NoError(t, err)
+		intensity := CalculateIntensityPointsOn(qz, edens, 0, nil)
+
+		// without absorption no wave enters the substrate below its critical edge
+		qc := criticalQ(edens[len(edens)-1].Y * ELECTRON_RADIUS)
+		for _, p := range intensity {
+			assert.False(t, math.IsNaN(p.Y), "eden %v", eden)
+			assert.GreaterOrEqual(t, p.Y, 0.0, "eden %v, q = %g", eden, p.X)
+			assert.LessOrEqual(t, p.Y, 1+1e-12, "eden %v, q = %g", eden, p.X)
+			if p.X < 0.99*qc {
+				assert.InDelta(t, 1, p.Y, 1e-9, "eden %v, q = %g", eden, p.X)
+			}
+		}
+	}
+}
+
+func TestIntensityOptions(t *testing.T) {
+	edens, err := GetEdensities([]float64{0, 0.45, 0.334}, []float64{20}, []float64{3, 3})
+	assert.NoError(t, err)
+	qz := []float64{0.02, 0.1, 0.3}
+
+	reflectivity := CalculateIntensityPointsOn(qz, edens, 0, nil)
+	intensity := CalculateIntensityPointsOn(qz, edens, 0, &IntensityOptions{Background: 1e-6, Scaling: 0.5})
+	for i := range qz {
+		assert.Equal(t, qz[i], intensity[i].X)
+		assert.InEpsilon(t, 0.5*reflectivity[i].Y+1e-6, intensity[i].Y, 1e-12)
+	}
+
+	// the qz offset shifts the calculation but not the axis
+	shifted := CalculateIntensityPointsOn([]float64{0.1 - 0.01}, edens, 0.01, nil)
+	assert.Equal(t, 0.09, shifted[0].X)
+	assert.InEpsilon(t, reflectivity[1].Y, shifted[0].Y, 1e-12)
+}
+
+func TestSim2SigRMS(t *testing.T) {
+	intensity := function.Points{{X: 0.1, Y: 1}, {X: 0.15, Y: 0.75}, {X: 0.2, Y: 0.5}}
+	dataSets := []function.Points{
+		{{X: 0.1, Y: 1.2, Error: 0.1}},
+		{{X: 0.2, Y: 0.4, Error: 0.05}, {X: 0.15, Y: 0.75, Error: 0.25}},
+	}
+	// Σ q² ((model - data) / σ)² over all data sets
+	expected := 0.01*4 + 0.04*4 + 0.0225*0
+	rms, err := Sim2SigRMSOn(dataSets, intensity)
+	assert.NoError(t, err)
+	assert.InDelta(t, expected, rms, 1e-12)
+
+	// the intensity is not interpolated
+	_, err = Sim2SigRMSOn([]function.Points{{{X: 0.12, Y: 1, Error: 1}}}, intensity)
+	assert.Error(t, err)
+}
+
+func TestMerits(t *testing.T) {
+	intensity := function.Points{{X: 0.1, Y: 1}, {X: 0.2, Y: 0.01}}
+	dataSets := []function.Points{{{X: 0.1, Y: 0.1, Error: 0.5}, {X: 0.2, Y: 0.01, Error: 0.001}}}
+
+	chi2, err := Chi2On(dataSets, intensity)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.9*0.9/0.25, chi2, 1e-12)
+
+	// one decade off
+	diff, err := LogDiffOn(dataSets, intensity)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, diff, 1e-12)
+	diff, err = LogDiffOn([]function.Points{{{X: 0.1, Y: -1, Error: 1}}}, intensity)
+	assert.NoError(t, err)
+	assert.Zero(t, diff)
+
+	_, err = Chi2On([]function.Points{{{X: 0.15, Y: 1, Error: 1}}}, intensity)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"chi2", "log", "q2chi2"}, MeritNames())
+}
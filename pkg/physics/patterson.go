@@ -0,0 +1,59 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"sort"
+)
+
+// Patterson returns the autocorrelation P(z) = ∫ ρ'(z') ρ'(z'+z) dz' of the gradient of an electron density profile
+// on an equidistant z axis (see GetZAxis) for z >= 0 in e²/Å⁷, the peaks are at the distances between the interfaces
+func Patterson(edens function.Points) function.Points {
+	if len(edens) < 3 {
+		return function.Points{}
+	}
+	dz := edens[1].X - edens[0].X
+
+	gradient := make([]float64, len(edens)-1)
+	for i := range gradient {
+		gradient[i] = (edens[i+1].Y - edens[i].Y) / dz
+	}
+
+	patterson := make(function.Points, len(gradient))
+	for k := range patterson {
+		sum := 0.0
+		for i := 0; i+k < len(gradient); i++ {
+			sum += gradient[i] * gradient[i+k]
+		}
+		patterson[k] = &function.Point{X: float64(k) * dz, Y: sum * dz}
+	}
+	return patterson
+}
+
+// PattersonOfData estimates the Patterson function of a measured reflectivity in the Born approximation
+// P(z) = 1/(16π³ r_e²) ∫ R(q) q⁴ cos(qz) dq on the z axis, the limited q range of the data adds ripples
+func PattersonOfData(data function.Points, deltaq float64, zaxis []float64) function.Points {
+	// R·q⁴ at the corrected qz values, sorted for the integration
+	type sample struct{ q, rq4 float64 }
+	samples := make([]sample, 0, len(data))
+	for _, p := range data {
+		q := p.X + deltaq
+		if q > 0 {
+			samples = append(samples, sample{q: q, rq4: p.Y * math.Pow(q, 4)})
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].q < samples[j].q })
+
+	factor := 1 / (16 * math.Pow(math.Pi, 3) * ELECTRON_RADIUS * ELECTRON_RADIUS)
+	patterson := make(function.Points, len(zaxis))
+	for k, z := range zaxis {
+		// trapezoidal rule
+		sum := 0.0
+		for i := 1; i < len(samples); i++ {
+			a, b := samples[i-1], samples[i]
+			sum += 0.5 * (a.rq4*math.Cos(a.q*z) + b.rq4*math.Cos(b.q*z)) * (b.q - a.q)
+		}
+		patterson[k] = &function.Point{X: z, Y: factor * sum}
+	}
+	return patterson
+}
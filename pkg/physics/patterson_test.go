@@ -0,0 +1,51 @@
+package physics
+
+import (
+	"math"
+	"physicsGUI/pkg/function"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattersonOfLayer(t *testing.T) {
+	// a 40 Å layer on a substrate, the Patterson function has a peak at the layer thickness
+	edens, err := GetEdensities([]float64{0, 0.5, 0.3}, []float64{40}, []float64{3, 3})
+	assert.NoError(t, err)
+	patterson := Patterson(edens)
+	assert.Equal(t, 0.0, patterson[0].X)
+
+	// the cross term of the two interfaces is negative, as the density increases at the first and decreases at the second
+	peak := 0
+	for i, p := range patterson {
+		if p.X > 10 && p.Y < patterson[peak].Y {
+			peak = i
+		}
+	}
+	assert.InDelta(t, 40, patterson[peak].X, 1)
+	assert.Less(t, patterson[peak].Y, 0.0)
+}
+
+func TestPattersonOfData(t *testing.T) {
+	// a rough interface in the Born approximation R = 16π² r_e² Δ² exp(-q²σ²) / q⁴, the gradient is a Gaussian of
+	// the area Δ, its autocorrelation at z = 0 is Δ² / (2σ√π)
+	delta, sigma := 0.3, 3.0
+	data := make(function.Points, 0)
+	for q := 0.001; q < 1.5; q += 0.001 {
+		r := 16 * math.Pi * math.Pi * ELECTRON_RADIUS * ELECTRON_RADIUS * delta * delta * math.Exp(-q*q*sigma*sigma) / math.Pow(q, 4)
+		data = append(data, &function.Point{X: q, Y: r})
+	}
+	patterson := PattersonOfData(data, 0, []float64{0, 5})
+	assert.InDelta(t, delta*delta/(2*sigma*math.Sqrt(math.Pi)), patterson[0].Y, 1e-4)
+
+	edens, err := GetEdensities([]float64{0, delta}, []float64{}, []float64{sigma})
+	assert.NoError(t, err)
+	model := Patterson(edens)
+	assert.InDelta(t, model[0].Y, patterson[0].Y, 1e-3)
+	// both decay like a Gaussian of the width √2σ
+	assert.InDelta(t, patterson[0].Y*math.Exp(-25/(4*sigma*sigma)), patterson[1].Y, 1e-4)
+
+	// the qz offset moves the data
+	shifted := PattersonOfData(data, 1, []float64{0})
+	assert.NotEqual(t, patterson[0].Y, shifted[0].Y)
+}
@@ -0,0 +1,215 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	io2 "io"
+	"os"
+	"os/signal"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"physicsGUI/pkg/simulate"
+	"physicsGUI/pkg/state"
+	"strconv"
+	"strings"
+)
+
+// files are flags which can be given more than once
+type files []string
+
+func (f *files) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *files) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Command is the recipe subcommand, it fits the model of a project file to data sets with the steps of a recipe
+// and writes the result of every step as JSON
+//
+//	spirit recipe -config project.json -recipe recipe.yaml -data a.dat -data b.dat -o stages.json -save fitted.json
+func Command(args []string, stdout io2.Writer) error {
+	flags := flag.NewFlagSet("recipe", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	config := flags.String("config", "", "project file with the model parameters (.json, .xml or binary)")
+	recipePath := flags.String("recipe", "", "recipe file with the fit steps (.yaml or .json)")
+	var dataPaths files
+	flags.Var(&dataPaths, "data", "data set to fit, can be given more than once")
+	output := flags.String("o", "", "output file of the stages, the standard output if empty")
+	save := flags.String("save", "", "project file the fitted parameters are written to")
+	if err := flags.Parse(args); err != nil {
+		// the usage was printed
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *config == "" || *recipePath == "" || len(dataPaths) == 0 {
+		return errors.New("a project file, a recipe and data sets are needed, set them with -config, -recipe and -data")
+	}
+	project, err := io.ReadProject(*config)
+	if err != nil {
+		return err
+	}
+	r, err := Load(*recipePath)
+	if err != nil {
+		return err
+	}
+	session, merits, err := modelSession(project)
+	if err != nil {
+		return err
+	}
+	for _, path := range dataPaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		points, err := data.Parse(content)
+		if err == nil {
+			err = data.CheckErrors(points)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		session.AddDataset(state.Dataset{Name: path, Source: path, Points: points})
+	}
+
+	// Ctrl+C stops the running step, the finished stages are still written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stages, runErr := Run(ctx, session, r, merits, func(stage Stage) {
+		fmt.Fprintf(os.Stderr, "%s: %s after %d calls, fval %g\n", stage.Step, stage.Status, stage.Calls, stage.FVal)
+	})
+	if stages == nil {
+		return runErr
+	}
+
+	w := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, "error while closing output:", err)
+			}
+		}()
+		w = file
+	}
+	if err := WriteJSON(w, stages); err != nil {
+		return err
+	}
+	if *save != "" {
+		if err := io.WriteProject(*save, fittedProject(project, session)); err != nil {
+			return err
+		}
+	}
+	return runErr
+}
+
+// returns the penalties of the reflectivity model with the numbers of electron densities, thicknesses and roughnesses
+func modelMerits(layers [3]int) Merits {
+	return func(name string) (state.Objective, error) {
+		if name == "" {
+			name = physics.DefaultMerit
+		}
+		merit, ok := physics.Merits[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown figure of merit %q, use one of %s", name, strings.Join(physics.MeritNames(), ", "))
+		}
+		return func(values []float64, datasets []function.Points) (float64, error) {
+			model, err := simulate.ModelFromValues(values, layers)
+			if err != nil {
+				return 0, err
+			}
+			edens, err := physics.GetEdensities(model.Eden, model.Thickness, model.Roughness)
+			if err != nil {
+				return 0, err
+			}
+			intensity := physics.CalculateIntensityPointsOn(physics.QZAxisOf(datasets...), edens, model.DeltaQ, &physics.IntensityOptions{
+				Background: model.Background,
+				Scaling:    model.Scaling,
+			})
+			return merit(datasets, intensity)
+		}, nil
+	}
+}
+
+// creates a session with the model parameters of a project file, ordered like simulate.ModelParameters,
+// and the figures of merit of its model
+func modelSession(project *io.ConfigInformation) (*state.Session, Merits, error) {
+	params, err := simulate.ModelParameters(project)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := state.NewSession(nil)
+	for _, info := range params {
+		p := state.Parameter{Group: info.Group, Name: info.Name, Fit: info.UseInFit, Limited: info.IsLimited, Order: info.FitOrder}
+		if p.Value, err = strconv.ParseFloat(info.FieldValue, 64); err != nil {
+			return nil, nil, fmt.Errorf("invalid value of %s: %w", info.Name, err)
+		}
+		if info.FieldStep != "" {
+			if p.Step, err = strconv.ParseFloat(info.FieldStep, 64); err != nil {
+				return nil, nil, fmt.Errorf("invalid step of %s: %w", info.Name, err)
+			}
+		}
+		if info.IsLimited {
+			if p.Min, err = strconv.ParseFloat(info.FieldMinimum, 64); err != nil {
+				return nil, nil, fmt.Errorf("invalid minimum of %s: %w", info.Name, err)
+			}
+			if p.Max, err = strconv.ParseFloat(info.FieldMaximum, 64); err != nil {
+				return nil, nil, fmt.Errorf("invalid maximum of %s: %w", info.Name, err)
+			}
+		}
+		if err := s.AddParameter(p); err != nil {
+			return nil, nil, err
+		}
+	}
+	merits := modelMerits(simulate.Layers(params))
+	objective, err := merits("")
+	if err != nil {
+		return nil, nil, err
+	}
+	s.SetObjective(objective)
+	return s, merits, nil
+}
+
+// returns a copy of the project with the values, fit flags and limits of the session
+func fittedProject(project *io.ConfigInformation, s *state.Session) *io.ConfigInformation {
+	fitted := *project
+	fitted.Parameter = make([]io.ParameterInformation, 0, len(project.Parameter))
+	added := make(map[string]bool)
+	for _, info := range project.Parameter {
+		if p, err := s.Parameter(info.Group, info.Name); err == nil {
+			info = parameterInformation(info, p)
+			added[info.Group+"/"+info.Name] = true
+		}
+		fitted.Parameter = append(fitted.Parameter, info)
+	}
+	// general parameters which had their defaults
+	for _, p := range s.Parameters() {
+		if !added[p.Group+"/"+p.Name] {
+			fitted.Parameter = append(fitted.Parameter, parameterInformation(io.ParameterInformation{Group: p.Group, Name: p.Name, FieldType: "float64"}, p))
+		}
+	}
+	return &fitted
+}
+
+func parameterInformation(info io.ParameterInformation, p state.Parameter) io.ParameterInformation {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	info.FieldValue = format(p.Value)
+	info.UseInFit = p.Fit
+	info.IsLimited = p.Limited
+	if p.Limited {
+		info.FieldMinimum, info.FieldMaximum = format(p.Min), format(p.Max)
+	}
+	return info
+}
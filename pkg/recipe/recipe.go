@@ -0,0 +1,331 @@
+// Package recipe runs fits in stages which are described in a file, f.e. first only the scaling and the background,
+// then the thicknesses and at last every parameter with tighter limits
+//
+//	name: three stages
+//	steps:
+//	  - name: scaling and background
+//	    free: [scaling, background]
+//	    max_calls: 2000
+//	  - name: thicknesses
+//	    free: [thick]
+//	    engine: Minuit Migrad
+//	  - name: everything
+//	    free: ["*"]
+//	    limits:
+//	      Thickness 1: {min: 10, max: 20}
+//	    merit: chi2
+package recipe
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/state"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Recipe is a list of fit steps which are run one after another, every step starts at the result of the previous one
+type Recipe struct {
+	Name  string `json:"name" yaml:"name"`
+	Steps []Step `json:"steps" yaml:"steps"`
+}
+
+// Step is a fit of a recipe, empty fields use the defaults
+type Step struct {
+	Name string `json:"name" yaml:"name"`
+	// parameters varied by the step given by their names or groups, "*" selects all parameters, the others are fixed
+	Free []string `json:"free" yaml:"free"`
+	// limits of parameters given by their names or groups, the other parameters keep their limits
+	Limits map[string]Limit `json:"limits,omitempty" yaml:"limits,omitempty"`
+	// name of the registered engine
+	Engine string `json:"engine,omitempty" yaml:"engine,omitempty"`
+	// name of the strategy of the Minuit engines
+	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	MaxCalls int    `json:"max_calls,omitempty" yaml:"max_calls,omitempty"`
+	// seed of the engines with random numbers, a random seed if empty
+	Seed uint64 `json:"seed,omitempty" yaml:"seed,omitempty"`
+	// name of the figure of merit
+	Merit string `json:"merit,omitempty" yaml:"merit,omitempty"`
+}
+
+// Limit is the range of a parameter in a step
+type Limit struct {
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+}
+
+// ErrStopped is returned when a step was stopped by StopFit of the session
+var ErrStopped = errors.New("the fit was stopped")
+
+// Merits returns the penalty of a figure of merit, the default one for an empty name
+type Merits func(name string) (state.Objective, error)
+
+// Stage is the result of a step
+type Stage struct {
+	Step   string `json:"step"`
+	Engine string `json:"engine"`
+	// seed of the engine, repeats the step with the same data
+	Seed   uint64 `json:"seed,omitempty"`
+	Merit  string `json:"merit,omitempty"`
+	Status string `json:"status"`
+	// names of the varied parameters
+	Free  []string `json:"free"`
+	FVal  float64  `json:"fval"`
+	Calls int      `json:"calls"`
+	// duration of the step in seconds
+	Seconds float64 `json:"seconds"`
+	// values and errors of all parameters after the step, the errors of the varied parameters of converged steps
+	Values map[string]float64 `json:"values"`
+	Errors map[string]float64 `json:"errors"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// Parse reads a recipe in YAML or JSON, JSON is a subset of YAML
+func Parse(data []byte) (*Recipe, error) {
+	var r Recipe
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&r); err != nil {
+		return nil, fmt.Errorf("invalid recipe: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Load reads a recipe file
+func Load(path string) (*Recipe, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if r.Name == "" {
+		r.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return r, nil
+}
+
+// Validate checks the steps without a session, the names of the parameters are checked by Run
+func (r *Recipe) Validate() error {
+	if len(r.Steps) == 0 {
+		return errors.New("the recipe has no steps")
+	}
+	for i, step := range r.Steps {
+		if len(step.Free) == 0 {
+			return fmt.Errorf("step %d has no free parameters", i+1)
+		}
+		if step.Engine != "" && !slices.Contains(minimizer.EngineNames(), step.Engine) {
+			return fmt.Errorf("step %d: %w: %s", i+1, minimizer.ErrUnknownEngine, step.Engine)
+		}
+		if step.Strategy != "" {
+			if _, err := minimizer.ParseStrategy(step.Strategy); err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+		if step.MaxCalls < 0 {
+			return fmt.Errorf("step %d: the maximum calls cannot be negative", i+1)
+		}
+		for name, limit := range step.Limits {
+			if limit.Min >= limit.Max {
+				return fmt.Errorf("step %d: the minimum of %s has to be smaller than the maximum", i+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+// name of a step, its number if it has no name
+func (r *Recipe) stepName(i int) string {
+	if r.Steps[i].Name != "" {
+		return r.Steps[i].Name
+	}
+	return fmt.Sprintf("Step %d", i+1)
+}
+
+// reports whether a selector of a step matches the parameter
+func matches(selector string, p state.Parameter) bool {
+	return selector == "*" || selector == p.Name || selector == p.Group
+}
+
+// checks that every selector of the step matches a parameter of the session
+func checkSelectors(step Step, parameters []state.Parameter) error {
+	selectors := slices.Clone(step.Free)
+	for name := range step.Limits {
+		selectors = append(selectors, name)
+	}
+	for _, selector := range selectors {
+		if !slices.ContainsFunc(parameters, func(p state.Parameter) bool { return matches(selector, p) }) {
+			return fmt.Errorf("%w: %s", state.ErrParameterNotFound, selector)
+		}
+	}
+	return nil
+}
+
+// Run fits the session with the steps of the recipe one after another, onStage is called after every step
+// the parameters keep the fitted values, their fit flags and limits and the objective are restored afterwards,
+// also if a step failed or was stopped
+// the recipe stops at the first failed step, steps ending at their maximum calls are no failure
+func Run(ctx context.Context, s *state.Session, r *Recipe, merits Merits, onStage func(Stage)) ([]Stage, error) {
+	parameters := s.Parameters()
+	for i, step := range r.Steps {
+		if err := checkSelectors(step, parameters); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.stepName(i), err)
+		}
+		if _, err := merits(step.Merit); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.stepName(i), err)
+		}
+	}
+
+	objective := s.Objective()
+	defer s.SetObjective(objective)
+	defer restoreSettings(s, parameters)
+
+	stages := make([]Stage, 0, len(r.Steps))
+	for i, step := range r.Steps {
+		if err := ctx.Err(); err != nil {
+			return stages, err
+		}
+		stage, err := runStep(ctx, s, step, merits)
+		stage.Step = r.stepName(i)
+		stages = append(stages, stage)
+		if onStage != nil {
+			onStage(stage)
+		}
+		if err != nil {
+			return stages, fmt.Errorf("%s: %w", stage.Step, err)
+		}
+	}
+	return stages, nil
+}
+
+// restores the fit flags and limits of the parameters changed by the steps
+func restoreSettings(s *state.Session, parameters []state.Parameter) {
+	for _, saved := range parameters {
+		err := s.UpdateParameter(saved.Group, saved.Name, func(p *state.Parameter) {
+			p.Fit, p.Min, p.Max, p.Limited = saved.Fit, saved.Min, saved.Max, saved.Limited
+		})
+		if err != nil {
+			log.Println("Error while restoring the fit settings:", err)
+		}
+	}
+}
+
+// fits the session with the settings of the step
+func runStep(ctx context.Context, s *state.Session, step Step, merits Merits) (Stage, error) {
+	stage := Stage{Engine: step.Engine, Merit: step.Merit}
+	objective, err := merits(step.Merit)
+	if err != nil {
+		return stage, err
+	}
+	s.SetObjective(objective)
+
+	for _, p := range s.Parameters() {
+		err := s.UpdateParameter(p.Group, p.Name, func(p *state.Parameter) {
+			p.Fit = slices.ContainsFunc(step.Free, func(selector string) bool { return matches(selector, *p) })
+			for selector, limit := range step.Limits {
+				if matches(selector, *p) {
+					p.Min, p.Max, p.Limited = limit.Min, limit.Max, true
+				}
+			}
+		})
+		if err != nil {
+			return stage, err
+		}
+	}
+
+	config := minimizer.FitConfig{Engine: step.Engine, MaxCalls: step.MaxCalls, Seed: step.Seed}
+	if step.Strategy != "" {
+		// checked by Validate
+		config.Strategy, _ = minimizer.ParseStrategy(step.Strategy)
+	}
+	start := time.Now()
+	if err := s.StartFit(ctx, config); err != nil {
+		stage.Status, stage.Error = state.FitFailed.String(), err.Error()
+		return stage, err
+	}
+	fit := s.WaitFit()
+	stage.Seconds = time.Since(start).Seconds()
+	stage.Engine, stage.Seed, stage.Status = fit.Engine, fit.Seed, fit.Status.String()
+
+	names := s.Names()
+	stage.Free = make([]string, len(fit.Free))
+	for i, index := range fit.Free {
+		stage.Free[i] = names[index]
+	}
+	if res := fit.Result; res != nil {
+		stage.FVal, stage.Calls = res.FVal, res.NFcn
+	}
+	stage.Values = make(map[string]float64)
+	stage.Errors = make(map[string]float64)
+	for _, p := range s.Parameters() {
+		stage.Values[p.Name] = p.Value
+		stage.Errors[p.Name] = p.Error
+	}
+
+	switch {
+	case fit.Status == state.FitIdle:
+		// the fit was cancelled
+		return stage, cmp.Or(ctx.Err(), ErrStopped)
+	case fit.Err != nil:
+		stage.Error = fit.Err.Error()
+		if !errors.Is(fit.Err, minimizer.ErrMaxCalls) {
+			return stage, fit.Err
+		}
+	}
+	return stage, nil
+}
+
+// FromOrder creates the steps of a fit by the orders of the parameters, the checked parameters of the lowest order are
+// fitted first and every following step frees the parameters of the next order as well
+// the steps use the settings of the template, returns nil if all checked parameters have the same order,
+// they are fitted in a single fit
+func FromOrder(parameters []state.Parameter, template Step) *Recipe {
+	orders := make([]int, 0)
+	for _, p := range parameters {
+		if p.Fit {
+			orders = append(orders, p.Order)
+		}
+	}
+	slices.Sort(orders)
+	orders = slices.Compact(orders)
+	if len(orders) < 2 {
+		return nil
+	}
+
+	r := &Recipe{Name: "Fit Order"}
+	for _, order := range orders {
+		step := template
+		step.Name, step.Free = fmt.Sprintf("Order %d", order), nil
+		for _, p := range parameters {
+			if p.Fit && p.Order <= order {
+				step.Free = append(step.Free, p.Name)
+			}
+		}
+		r.Steps = append(r.Steps, step)
+	}
+	return r
+}
+
+// WriteJSON writes the stages as json
+func WriteJSON(w io.Writer, stages []Stage) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stages)
+}
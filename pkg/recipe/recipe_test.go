@@ -0,0 +1,234 @@
+package recipe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/minimizer"
+	"physicsGUI/pkg/simulate"
+	"physicsGUI/pkg/state"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(`
+name: line
+steps:
+  - name: offset
+    free: [b]
+    max_calls: 500
+  - free: ["*"]
+    limits:
+      a: {min: 0, max: 5}
+    engine: Minuit Migrad
+    merit: chi2
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Recipe{Name: "line", Steps: []Step{
+		{Name: "offset", Free: []string{"b"}, MaxCalls: 500},
+		{Free: []string{"*"}, Limits: map[string]Limit{"a": {Min: 0, Max: 5}}, Engine: minimizer.EngineMigrad, Merit: "chi2"},
+	}}, r)
+	assert.Equal(t, "Step 2", r.stepName(1))
+
+	// JSON is read the same way
+	r, err = Parse([]byte(`{"steps": [{"free": ["line"], "max_calls": 100}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Step{{Free: []string{"line"}, MaxCalls: 100}}, r.Steps)
+
+	for _, invalid := range []string{
+		`steps: []`,
+		`steps: [{name: nothing free}]`,
+		`steps: [{free: [a], engine: unknown}]`,
+		`steps: [{free: [a], max_calls: -1}]`,
+		`steps: [{free: [a], limits: {a: {min: 1, max: 0}}}]`,
+		`steps: [{free: [a], maxcalls: 10}]`,
+		`steps: [{free: [a], strategy: Slow}]`,
+	} {
+		_, err := Parse([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+
+	path := filepath.Join(t.TempDir(), "two steps.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("steps: [{free: [a]}]"), 0644))
+	r, err = Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "two steps", r.Name)
+}
+
+// session of a line y = a·x + b with the data of y = 2x + 1
+func lineSession() (*state.Session, Merits) {
+	line := func(values []float64, datasets []function.Points) (float64, error) {
+		chi2 := 0.0
+		for _, points := range datasets {
+			for _, p := range points {
+				chi2 += math.Pow((values[0]*p.X+values[1]-p.Y)/p.Error, 2)
+			}
+		}
+		return chi2, nil
+	}
+	s := state.NewSession(line)
+	_ = s.AddParameter(state.Parameter{Group: "line", Name: "a", Value: 2})
+	_ = s.AddParameter(state.Parameter{Group: "line", Name: "b", Value: 0, Fit: true})
+	points := make(function.Points, 0)
+	for x := 0.0; x < 10; x++ {
+		points = append(points, &function.Point{X: x, Y: 2*x + 1, Error: 0.1})
+	}
+	s.AddDataset(state.Dataset{Name: "line.dat", Points: points})
+
+	merits := func(name string) (state.Objective, error) {
+		if name != "" && name != "chi2" {
+			return nil, errors.New("unknown figure of merit")
+		}
+		return line, nil
+	}
+	return s, merits
+}
+
+func TestRun(t *testing.T) {
+	s, merits := lineSession()
+	// the steps use the objectives of their merits, the one of the session is restored
+	s.SetObjective(func(values []float64, datasets []function.Points) (float64, error) { return 42, nil })
+	r := &Recipe{Steps: []Step{
+		{Name: "offset", Free: []string{"b"}},
+		{Name: "all", Free: []string{"line"}, Limits: map[string]Limit{"a": {Min: 0, Max: 5}}, Merit: "chi2"},
+	}}
+
+	var reported []string
+	stages, err := Run(context.Background(), s, r, merits, func(stage Stage) { reported = append(reported, stage.Step) })
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"offset", "all"}, reported)
+	assert.Len(t, stages, 2)
+
+	assert.Equal(t, []string{"b"}, stages[0].Free)
+	assert.Equal(t, "Completed", stages[0].Status)
+	assert.Equal(t, minimizer.DefaultEngine, stages[0].Engine)
+	assert.Equal(t, 2.0, stages[0].Values["a"])
+	assert.InDelta(t, 1, stages[0].Values["b"], 1e-6)
+	assert.Greater(t, stages[0].Calls, 0)
+
+	assert.Equal(t, []string{"a", "b"}, stages[1].Free)
+	assert.InDelta(t, 2, stages[1].Values["a"], 1e-6)
+	assert.Greater(t, stages[1].Errors["a"], 0.0)
+
+	// the fitted values are kept, the fit flags and limits are restored
+	a, _ := s.Parameter("line", "a")
+	assert.InDelta(t, 2, a.Value, 1e-6)
+	assert.False(t, a.Fit)
+	assert.False(t, a.Limited)
+	b, _ := s.Parameter("line", "b")
+	assert.True(t, b.Fit)
+	penalty, _ := s.Objective()([]float64{2, 1}, nil)
+	assert.Equal(t, 42.0, penalty)
+
+	stdout := &bytes.Buffer{}
+	assert.NoError(t, WriteJSON(stdout, stages))
+	var decoded []Stage
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &decoded))
+	assert.Equal(t, "offset", decoded[0].Step)
+
+	// selectors and merits are checked before the first step
+	_, err = Run(context.Background(), s, &Recipe{Steps: []Step{{Free: []string{"c"}}}}, merits, nil)
+	assert.ErrorIs(t, err, state.ErrParameterNotFound)
+	_, err = Run(context.Background(), s, &Recipe{Steps: []Step{{Free: []string{"a"}, Merit: "log"}}}, merits, nil)
+	assert.Error(t, err)
+
+	// steps at their maximum calls are no failure, other failures stop the recipe
+	stages, err = Run(context.Background(), s, &Recipe{Steps: []Step{
+		{Free: []string{"*"}, Engine: minimizer.EngineDE, MaxCalls: 10},
+		{Free: []string{"a"}, Engine: minimizer.EngineMigrad},
+	}}, merits, nil)
+	assert.NoError(t, err)
+	assert.Len(t, stages, 2)
+	assert.Equal(t, "Failed", stages[0].Status)
+	assert.Contains(t, stages[0].Error, minimizer.ErrMaxCalls.Error())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stages, err = Run(ctx, s, r, merits, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, stages)
+}
+
+func TestFromOrder(t *testing.T) {
+	parameters := []state.Parameter{
+		{Name: "a", Fit: true, Order: 1},
+		{Name: "b", Fit: true},
+		{Name: "c", Order: 2},
+		{Name: "d", Fit: true, Order: 3},
+	}
+	template := Step{Engine: minimizer.EngineDEMigrad, Strategy: "Precise", Seed: 7}
+	r := FromOrder(parameters, template)
+	assert.NoError(t, r.Validate())
+	assert.Equal(t, []Step{
+		{Name: "Order 0", Free: []string{"b"}, Engine: minimizer.EngineDEMigrad, Strategy: "Precise", Seed: 7},
+		{Name: "Order 1", Free: []string{"a", "b"}, Engine: minimizer.EngineDEMigrad, Strategy: "Precise", Seed: 7},
+		{Name: "Order 3", Free: []string{"a", "b", "d"}, Engine: minimizer.EngineDEMigrad, Strategy: "Precise", Seed: 7},
+	}, r.Steps)
+
+	// a single order is a single fit
+	assert.Nil(t, FromOrder(parameters[1:2], template))
+	parameters[0].Order = 0
+	parameters[3].Fit = false
+	assert.Nil(t, FromOrder(parameters, template))
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	model := simulate.Model{Eden: []float64{0, 0.334}, Roughness: []float64{3}, Scaling: 1}
+	points, err := simulate.Generate(model, simulate.Settings{QMin: 0.01, QMax: 0.3, Points: 60, Flux: 1e9, Seed: 1})
+	assert.NoError(t, err)
+	dataPath := filepath.Join(dir, "substrate.dat")
+	file, err := os.Create(dataPath)
+	assert.NoError(t, err)
+	assert.NoError(t, simulate.WriteDat(file, points))
+	assert.NoError(t, file.Close())
+
+	project := filepath.Join(dir, "project.json")
+	encoded, err := io.EncodeJSONToBytes(&io.ConfigInformation{Parameter: []io.ParameterInformation{
+		{Group: "eden", Name: "Eden a", FieldValue: "0"},
+		{Group: "eden", Name: "Eden b", FieldValue: "0.334"},
+		{Group: "rough", Name: "Roughness a/b", FieldValue: "5", IsLimited: true, FieldMinimum: "0", FieldMaximum: "10"},
+		{Group: "general", Name: "scaling", FieldValue: "0.8"},
+	}})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(project, encoded, 0644))
+
+	recipePath := filepath.Join(dir, "recipe.yaml")
+	assert.NoError(t, os.WriteFile(recipePath, []byte(`
+steps:
+  - name: scaling
+    free: [scaling]
+  - name: roughness
+    free: [scaling, rough]
+    merit: chi2
+`), 0644))
+
+	output, saved := filepath.Join(dir, "stages.json"), filepath.Join(dir, "fitted.json")
+	assert.NoError(t, Command([]string{"-config", project, "-recipe", recipePath, "-data", dataPath, "-o", output, "-save", saved}, &bytes.Buffer{}))
+
+	content, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	var stages []Stage
+	assert.NoError(t, json.Unmarshal(content, &stages))
+	assert.Len(t, stages, 2)
+	assert.Equal(t, "roughness", stages[1].Step)
+	assert.InDelta(t, 3, stages[1].Values["Roughness a/b"], 0.1)
+	assert.InDelta(t, 1, stages[1].Values["scaling"], 0.01)
+
+	fitted, err := io.ReadProject(saved)
+	assert.NoError(t, err)
+	fittedModel, err := simulate.ModelFromConfig(fitted)
+	assert.NoError(t, err)
+	assert.InDelta(t, 3, fittedModel.Roughness[0], 0.1)
+
+	assert.Error(t, Command([]string{"-config", project}, &bytes.Buffer{}))
+	assert.Error(t, Command([]string{"-config", project, "-recipe", recipePath, "-data", filepath.Join(dir, "missing.dat")}, &bytes.Buffer{}))
+}
@@ -0,0 +1,91 @@
+package simulate
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	io2 "io"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/io"
+	"strings"
+	"time"
+)
+
+// Run is the simulate subcommand, it reads the model from a project file and writes the simulated data set
+//
+//	spirit simulate -config project.json -o simulated.dat -flux 1e7 -resolution 0.03
+func Run(args []string, stdout io2.Writer) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	config := flags.String("config", "", "project file with the model parameters (.json, .xml or binary)")
+	output := flags.String("o", "", "output file, the standard output if empty")
+	format := flags.String("format", "", "output format dat or orso, by default orso for .ort files and dat otherwise")
+	settings := DefaultSettings
+	flags.Float64Var(&settings.QMin, "qmin", settings.QMin, "smallest qz in 1/Å")
+	flags.Float64Var(&settings.QMax, "qmax", settings.QMax, "largest qz in 1/Å")
+	flags.IntVar(&settings.Points, "n", settings.Points, "number of points")
+	flags.BoolVar(&settings.LogSpacing, "log", settings.LogSpacing, "space the points with a constant dq/q")
+	flags.Float64Var(&settings.Resolution, "resolution", settings.Resolution, "relative resolution dq/q (standard deviation)")
+	flags.Float64Var(&settings.Flux, "flux", settings.Flux, "incident counts per point")
+	flags.Uint64Var(&settings.Seed, "seed", settings.Seed, "seed of the noise")
+	background := flags.Float64("background", 0, "background of the reflectivity, the one of the project file if not set")
+	if err := flags.Parse(args); err != nil {
+		// the usage was printed
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *config == "" {
+		return errors.New("a project file is needed, set it with -config")
+	}
+	project, err := io.ReadProject(*config)
+	if err != nil {
+		return err
+	}
+	model, err := ModelFromConfig(project)
+	if err != nil {
+		return err
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "background" {
+			model.Background = *background
+		}
+	})
+
+	points, err := Generate(model, settings)
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = "dat"
+		if strings.EqualFold(filepath.Ext(*output), ".ort") {
+			*format = "orso"
+		}
+	}
+	w := stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, "error while closing output:", err)
+			}
+		}()
+		w = file
+	}
+
+	switch strings.ToLower(*format) {
+	case "dat":
+		return WriteDat(w, points)
+	case "orso":
+		return WriteORSO(w, points, model, settings, time.Now())
+	default:
+		return fmt.Errorf("unknown format %q, use dat or orso", *format)
+	}
+}
@@ -0,0 +1,44 @@
+package simulate
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// poisson draws a Poisson distributed number of counts with the mean, by multiplying uniform numbers for small
+// means and by the transformed rejection of Hörmann (PTRS) for large ones
+func poisson(rng *rand.Rand, mean float64) float64 {
+	if mean <= 0 {
+		return 0
+	}
+	if mean < 30 {
+		limit := math.Exp(-mean)
+		k := 0.0
+		for p := rng.Float64(); p > limit; p *= rng.Float64() {
+			k++
+		}
+		return k
+	}
+
+	logMean := math.Log(mean)
+	b := 0.931 + 2.53*math.Sqrt(mean)
+	a := -0.059 + 0.02483*b
+	invAlpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+	for {
+		u := rng.Float64() - 0.5
+		v := rng.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + mean + 0.43)
+		if us >= 0.07 && v <= vr {
+			return k
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		logFactorial, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invAlpha)-math.Log(a/(us*us)+b) <= -mean+k*logMean-logFactorial {
+			return k
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package simulate generates synthetic reflectivity data sets with the resolution and the counting noise of a
+// measurement, f.e. to plan a beamtime or to test whether a parameter can be determined at all
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// number of points of the resolution function, they span ±3σ
+const resolutionPoints = 21
+
+// Model is the layer model of the sample, the slices are ordered from the ambient medium to the substrate
+type Model struct {
+	// electron densities in e/Å³ {eden_a, eden_1, ..., eden_n, eden_b}
+	Eden []float64
+	// layer thicknesses in Å {d_1, ..., d_n}
+	Thickness []float64
+	// roughnesses in Å {sigma_a1, sigma_12, ..., sigma_nb}
+	Roughness []float64
+	DeltaQ    float64
+	// background and scaling of the reflectivity
+	Background float64
+	Scaling    float64
+}
+
+// Settings describe the simulated measurement
+type Settings struct {
+	// qz range in 1/Å and the number of points, log spacing has a constant dq/q between the points
+	QMin, QMax float64
+	Points     int
+	LogSpacing bool
+	// relative standard deviation dq/q of the Gaussian resolution, 0 is a perfect resolution
+	Resolution float64
+	// incident counts per point, the counts of a point are Poisson distributed around flux·(scaling·R + background)
+	Flux float64
+	Seed uint64
+}
+
+// DefaultSettings are typical settings of a laboratory x-ray reflectometer
+var DefaultSettings = Settings{QMin: 0.01, QMax: 0.5, Points: 200, Resolution: 0.05, Flux: 1e8, Seed: 1}
+
+// QZ returns the qz axis of the settings
+func (s Settings) QZ() []float64 {
+	qz := make([]float64, s.Points)
+	for i := range qz {
+		t := float64(i) / float64(max(s.Points-1, 1))
+		if s.LogSpacing {
+			qz[i] = s.QMin * math.Pow(s.QMax/s.QMin, t)
+		} else {
+			qz[i] = s.QMin + (s.QMax-s.QMin)*t
+		}
+	}
+	return qz
+}
+
+func (s Settings) validate() error {
+	switch {
+	case s.QMin <= 0 || s.QMax <= s.QMin:
+		return errors.New("the q range has to be positive and qmax larger than qmin")
+	case s.Points < 2:
+		return errors.New("at least two points are needed")
+	case s.Resolution < 0:
+		return errors.New("the resolution cannot be negative")
+	case s.Flux <= 0:
+		return errors.New("the flux has to be positive")
+	}
+	return nil
+}
+
+// Reflectivity returns scaling·R + background of the model smeared by the resolution, without noise
+// the errors of the points are the standard deviations of qz
+func Reflectivity(model Model, settings Settings) (function.Points, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	edens, err := physics.GetEdensities(model.Eden, model.Thickness, model.Roughness)
+	if err != nil {
+		return nil, err
+	}
+
+	// every point is the weighted mean of the reflectivity at the points of its resolution function
+	qz := settings.QZ()
+	offsets := make([]float64, resolutionPoints)
+	weights := make([]float64, resolutionPoints)
+	sum := 0.0
+	for k := range offsets {
+		offsets[k] = 6*float64(k)/float64(resolutionPoints-1) - 3
+		weights[k] = math.Exp(-offsets[k] * offsets[k] / 2)
+		sum += weights[k]
+	}
+	smeared := make([]float64, 0, len(qz)*resolutionPoints)
+	for _, q := range qz {
+		for _, o := range offsets {
+			smeared = append(smeared, q*(1+settings.Resolution*o))
+		}
+	}
+	reflectivity := physics.CalculateIntensityPointsOn(smeared, edens, model.DeltaQ, nil)
+
+	points := make(function.Points, len(qz))
+	for i, q := range qz {
+		r := 0.0
+		for k := range offsets {
+			r += weights[k] / sum * reflectivity[i*resolutionPoints+k].Y
+		}
+		points[i] = &function.Point{X: q, Y: model.Scaling*r + model.Background, Error: q * settings.Resolution}
+	}
+	return points, nil
+}
+
+// Generate simulates a measurement of the model, the intensities are counts per incident count with their
+// Poisson errors, points without counts get the error of a single count
+func Generate(model Model, settings Settings) (function.Points, error) {
+	expected, err := Reflectivity(model, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(settings.Seed, settings.Seed^0x9e3779b97f4a7c15))
+	points := make(function.Points, len(expected))
+	for i, p := range expected {
+		counts := poisson(rng, settings.Flux*p.Y)
+		points[i] = &function.Point{X: p.X, Y: counts / settings.Flux, Error: math.Sqrt(max(counts, 1)) / settings.Flux}
+	}
+	return points, nil
+}
+
+// general parameters of the model and their defaults, in the order of the fit values of the GUI
+var generalParameters = []struct {
+	name  string
+	value float64
+}{{"deltaq", 0}, {"background", 0}, {"scaling", 1}}
+
+// ModelParameters returns the parameters of the model in a project file in the order of the fit values of the GUI:
+// the electron densities, thicknesses and roughnesses ordered by their layers, then deltaq, background and scaling
+// general parameters missing in the project file are added with their defaults
+func ModelParameters(config *io.ConfigInformation) ([]io.ParameterInformation, error) {
+	groups := make(map[string][]io.ParameterInformation)
+	for _, p := range config.Parameter {
+		groups[p.Group] = append(groups[p.Group], p)
+	}
+	if len(groups["eden"]) < 2 {
+		return nil, errors.New("the project file has no electron densities")
+	}
+
+	params := make([]io.ParameterInformation, 0, len(config.Parameter))
+	for _, group := range []string{"eden", "thick", "rough"} {
+		layers := groups[group]
+		slices.SortStableFunc(layers, func(a, b io.ParameterInformation) int {
+			return layerIndex(a.Name) - layerIndex(b.Name)
+		})
+		params = append(params, layers...)
+	}
+	for _, general := range generalParameters {
+		i := slices.IndexFunc(groups["general"], func(p io.ParameterInformation) bool { return p.Name == general.name })
+		if i == -1 {
+			params = append(params, io.ParameterInformation{
+				Group: "general", Name: general.name, FieldType: "float64",
+				FieldValue: strconv.FormatFloat(general.value, 'g', -1, 64),
+			})
+			continue
+		}
+		params = append(params, groups["general"][i])
+	}
+	return params, nil
+}
+
+// ModelFromValues creates the model from the values of the parameters ordered like ModelParameters,
+// the layers are the numbers of electron densities, thicknesses and roughnesses
+func ModelFromValues(values []float64, layers [3]int) (Model, error) {
+	if len(values) != layers[0]+layers[1]+layers[2]+len(generalParameters) {
+		return Model{}, fmt.Errorf("the model has %d values but expects %d", len(values), layers[0]+layers[1]+layers[2]+len(generalParameters))
+	}
+	thick, rough := layers[0], layers[0]+layers[1]
+	general := rough + layers[2]
+	return Model{
+		Eden:       values[:thick],
+		Thickness:  values[thick:rough],
+		Roughness:  values[rough:general],
+		DeltaQ:     values[general],
+		Background: values[general+1],
+		Scaling:    values[general+2],
+	}, nil
+}
+
+// ModelFromConfig reads the model from the parameters of a project file, the layers are ordered by the names
+// of the parameters like in the GUI, f.e. "Eden a", "Eden 1", "Eden 2", "Eden b"
+func ModelFromConfig(config *io.ConfigInformation) (Model, error) {
+	params, err := ModelParameters(config)
+	if err != nil {
+		return Model{}, err
+	}
+	values := make([]float64, len(params))
+	for i, p := range params {
+		if values[i], err = strconv.ParseFloat(p.FieldValue, 64); err != nil {
+			return Model{}, fmt.Errorf("invalid value of %s: %w", p.Name, err)
+		}
+	}
+	return ModelFromValues(values, Layers(params))
+}
+
+// Layers counts the electron densities, thicknesses and roughnesses of the parameters
+func Layers(params []io.ParameterInformation) [3]int {
+	var layers [3]int
+	for _, p := range params {
+		switch p.Group {
+		case "eden":
+			layers[0]++
+		case "thick":
+			layers[1]++
+		case "rough":
+			layers[2]++
+		}
+	}
+	return layers
+}
+
+// position of a layer by the end of a parameter name, "a" is the ambient medium and "b" the substrate,
+// roughnesses like "a/1" are ordered by their upper layer
+func layerIndex(name string) int {
+	layer := name[strings.LastIndex(name, " ")+1:]
+	layer, _, _ = strings.Cut(layer, "/")
+	switch layer {
+	case "a":
+		return 0
+	case "b":
+		return math.MaxInt32
+	}
+	i, err := strconv.Atoi(layer)
+	if err != nil {
+		return math.MaxInt32 - 1
+	}
+	return i
+}
@@ -0,0 +1,226 @@
+package simulate
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"physicsGUI/pkg/data"
+	"physicsGUI/pkg/io"
+	"physicsGUI/pkg/physics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testModel = Model{
+	Eden:       []float64{0, 0.346197, 0.458849, 0.334},
+	Thickness:  []float64{14.2657, 10.6906},
+	Roughness:  []float64{3.39544, 2.1598, 3.90204},
+	Background: 1e-7,
+	Scaling:    0.9,
+}
+
+func TestQZ(t *testing.T) {
+	linear := Settings{QMin: 0.01, QMax: 0.5, Points: 50}.QZ()
+	assert.Len(t, linear, 50)
+	assert.Equal(t, 0.01, linear[0])
+	assert.InDelta(t, 0.5, linear[49], 1e-15)
+	assert.InDelta(t, linear[1]-linear[0], linear[49]-linear[48], 1e-12)
+
+	logarithmic := Settings{QMin: 0.01, QMax: 0.5, Points: 50, LogSpacing: true}.QZ()
+	assert.InDelta(t, 0.5, logarithmic[49], 1e-15)
+	assert.InDelta(t, logarithmic[1]/logarithmic[0], logarithmic[49]/logarithmic[48], 1e-12)
+}
+
+func TestReflectivity(t *testing.T) {
+	settings := DefaultSettings
+	settings.Resolution = 0
+	exact, err := Reflectivity(testModel, settings)
+	assert.NoError(t, err)
+
+	edens, err := physics.GetEdensities(testModel.Eden, testModel.Thickness, testModel.Roughness)
+	assert.NoError(t, err)
+	expected := physics.CalculateIntensityPointsOn(settings.QZ(), edens, 0, &physics.IntensityOptions{Background: 1e-7, Scaling: 0.9})
+	for i := range expected {
+		assert.InEpsilon(t, expected[i].Y, exact[i].Y, 1e-12)
+	}
+
+	// the resolution fills the minima of the Kiessig fringes
+	settings.Resolution = 0.1
+	smeared, err := Reflectivity(testModel, settings)
+	assert.NoError(t, err)
+	deepest, smearedDeepest := math.Inf(1), math.Inf(1)
+	for i, p := range exact {
+		if p.X > 0.1 {
+			deepest = min(deepest, p.Y/physics.FresnelReflectivity([]float64{p.X}, 0, 0.334*physics.ELECTRON_RADIUS)[0])
+			smearedDeepest = min(smearedDeepest, smeared[i].Y/physics.FresnelReflectivity([]float64{p.X}, 0, 0.334*physics.ELECTRON_RADIUS)[0])
+		}
+	}
+	assert.Greater(t, smearedDeepest, deepest)
+
+	for _, invalid := range []Settings{
+		{QMin: 0, QMax: 0.5, Points: 10, Flux: 1},
+		{QMin: 0.5, QMax: 0.1, Points: 10, Flux: 1},
+		{QMin: 0.01, QMax: 0.5, Points: 1, Flux: 1},
+		{QMin: 0.01, QMax: 0.5, Points: 10, Flux: 0},
+		{QMin: 0.01, QMax: 0.5, Points: 10, Flux: 1, Resolution: -1},
+	} {
+		_, err := Reflectivity(testModel, invalid)
+		assert.Error(t, err, "%+v", invalid)
+	}
+	_, err = Reflectivity(Model{Eden: []float64{0, 1}}, DefaultSettings)
+	assert.Error(t, err)
+}
+
+func TestPoisson(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, mean := range []float64{0.5, 5, 29, 100, 1e4} {
+		const n = 20000
+		sum, squares := 0.0, 0.0
+		for range n {
+			k := poisson(rng, mean)
+			assert.Equal(t, math.Floor(k), k)
+			assert.GreaterOrEqual(t, k, 0.0)
+			sum += k
+			squares += k * k
+		}
+		// mean and variance of a Poisson distribution are equal
+		m := sum / n
+		variance := squares/n - m*m
+		assert.InDelta(t, mean, m, 5*math.Sqrt(mean/n), "mean %g", mean)
+		assert.InEpsilon(t, mean, variance, 0.05, "mean %g", mean)
+	}
+	assert.Equal(t, 0.0, poisson(rng, 0))
+}
+
+func TestGenerate(t *testing.T) {
+	settings := DefaultSettings
+	first, err := Generate(testModel, settings)
+	assert.NoError(t, err)
+	second, err := Generate(testModel, settings)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	expected, err := Reflectivity(testModel, settings)
+	assert.NoError(t, err)
+	normalized := 0.0
+	for i, p := range first {
+		assert.Equal(t, expected[i].X, p.X)
+		counts := math.Round(p.Y * settings.Flux)
+		assert.InEpsilon(t, math.Sqrt(max(counts, 1))/settings.Flux, p.Error, 1e-9)
+		normalized += math.Pow((p.Y-expected[i].Y)/p.Error, 2)
+	}
+	// the data scatter by their errors, χ²/n is about 1
+	assert.InDelta(t, 1, normalized/float64(len(first)), 0.3)
+
+	settings.Seed = 2
+	other, err := Generate(testModel, settings)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
+
+func TestModelFromConfig(t *testing.T) {
+	parameter := func(group, name, value string) io.ParameterInformation {
+		return io.ParameterInformation{Group: group, Name: name, FieldType: "float64", FieldValue: value}
+	}
+	// parameters are stored in any order
+	config := &io.ConfigInformation{Parameter: []io.ParameterInformation{
+		parameter("rough", "Roughness 2/b", "3.90204"),
+		parameter("eden", "Eden b", "0.334"),
+		parameter("thick", "Thickness 2", "10.6906"),
+		parameter("eden", "Eden 2", "0.458849"),
+		parameter("general", "scaling", "0.9"),
+		parameter("rough", "Roughness a/1", "3.39544"),
+		parameter("eden", "Eden a", "0"),
+		parameter("thick", "Thickness 1", "14.2657"),
+		parameter("general", "background", "1e-7"),
+		parameter("rough", "Roughness 1/2", "2.1598"),
+		parameter("eden", "Eden 1", "0.346197"),
+	}}
+	model, err := ModelFromConfig(config)
+	assert.NoError(t, err)
+	assert.Equal(t, testModel, model)
+
+	// the order of the fit values of the GUI, missing general parameters get their defaults
+	params, err := ModelParameters(config)
+	assert.NoError(t, err)
+	names := make([]string, len(params))
+	for i, p := range params {
+		names[i] = p.Name
+	}
+	assert.Equal(t, []string{"Eden a", "Eden 1", "Eden 2", "Eden b", "Thickness 1", "Thickness 2",
+		"Roughness a/1", "Roughness 1/2", "Roughness 2/b", "deltaq", "background", "scaling"}, names)
+	assert.Equal(t, "0", params[9].FieldValue)
+	assert.Equal(t, [3]int{4, 2, 3}, Layers(params))
+	_, err = ModelFromValues([]float64{1, 2}, Layers(params))
+	assert.Error(t, err)
+
+	config.Parameter[0].FieldValue = "x"
+	_, err = ModelFromConfig(config)
+	assert.Error(t, err)
+	_, err = ModelFromConfig(&io.ConfigInformation{})
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	points, err := Generate(testModel, DefaultSettings)
+	assert.NoError(t, err)
+
+	dat := &bytes.Buffer{}
+	assert.NoError(t, WriteDat(dat, points))
+	parsed, err := data.Parse(dat.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, parsed, len(points))
+	assert.InEpsilon(t, points[10].Y, parsed[10].Y, 1e-6)
+
+	orso := &bytes.Buffer{}
+	assert.NoError(t, WriteORSO(orso, points, testModel, DefaultSettings, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)))
+	lines := strings.Split(strings.TrimSpace(orso.String()), "\n")
+	assert.True(t, strings.HasPrefix(lines[0], "# # ORSO reflectivity data file | 1.1 standard"))
+	assert.Contains(t, orso.String(), "#     start_date: 2025-03-01T12:00:00Z\n")
+	assert.Contains(t, orso.String(), "#     eden: [0, 0.346197, 0.458849, 0.334]\n")
+	rows := lines[len(lines)-len(points):]
+	assert.Len(t, strings.Fields(rows[0]), 4)
+	assert.True(t, strings.HasPrefix(lines[len(lines)-len(points)-1], "# # Qz"))
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project.json")
+	config := &io.ConfigInformation{Parameter: []io.ParameterInformation{
+		{Group: "eden", Name: "Eden a", FieldValue: "0"},
+		{Group: "eden", Name: "Eden b", FieldValue: "0.334"},
+		{Group: "rough", Name: "Roughness a/b", FieldValue: "3"},
+		{Group: "general", Name: "background", FieldValue: "1e-6"},
+	}}
+	encoded, err := io.EncodeJSONToBytes(config)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(project, encoded, 0644))
+
+	out := filepath.Join(dir, "simulated.dat")
+	assert.NoError(t, Run([]string{"-config", project, "-o", out, "-n", "50", "-flux", "1e6", "-background", "0"}, &bytes.Buffer{}))
+	content, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	points, err := data.Parse(content)
+	assert.NoError(t, err)
+	assert.Len(t, points, 50)
+
+	// orso selected by the flag and written to the standard output, or selected by the extension of the file
+	stdout := &bytes.Buffer{}
+	assert.NoError(t, Run([]string{"-config", project, "-format", "orso", "-n", "5"}, stdout))
+	assert.Contains(t, stdout.String(), "# # ORSO")
+	assert.Contains(t, stdout.String(), "#     background: 1e-06\n")
+	assert.NoError(t, Run([]string{"-config", project, "-o", filepath.Join(dir, "simulated.ort")}, &bytes.Buffer{}))
+	content, err = os.ReadFile(filepath.Join(dir, "simulated.ort"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "# # ORSO"))
+
+	assert.Error(t, Run([]string{}, &bytes.Buffer{}))
+	assert.Error(t, Run([]string{"-config", project, "-format", "csv"}, &bytes.Buffer{}))
+	assert.Error(t, Run([]string{"-config", filepath.Join(dir, "missing.json")}, &bytes.Buffer{}))
+	assert.NoError(t, Run([]string{"-h"}, &bytes.Buffer{}))
+}
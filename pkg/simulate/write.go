@@ -0,0 +1,77 @@
+package simulate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"physicsGUI/pkg/function"
+	"strings"
+	"time"
+)
+
+// WriteDat writes the points in the format of the data import: the number of points followed by
+// one line "qz R error" per point
+func WriteDat(w io.Writer, points function.Points) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "%d\n", len(points))
+	for _, p := range points {
+		fmt.Fprintf(b, "%.6E\t%.6E\t%.6E\n", p.X, p.Y, p.Error)
+	}
+	return b.Flush()
+}
+
+// WriteORSO writes the points as ORSO text file (.ort) with the columns Qz, R, sR and sQz,
+// the model and the settings of the simulation are stored in the header
+func WriteORSO(w io.Writer, points function.Points, model Model, settings Settings, date time.Time) error {
+	floats := func(values []float64) string {
+		s := make([]string, len(values))
+		for i, v := range values {
+			s[i] = fmt.Sprintf("%g", v)
+		}
+		return "[" + strings.Join(s, ", ") + "]"
+	}
+
+	b := bufio.NewWriter(w)
+	header := []string{
+		"# ORSO reflectivity data file | 1.1 standard | YAML encoding | https://www.reflectometry.org/",
+		"data_source:",
+		"  owner: {name: null, affiliation: null}",
+		"  experiment:",
+		"    title: simulated data",
+		"    instrument: simulation",
+		"    start_date: " + date.Format(time.RFC3339),
+		"    probe: x-ray",
+		"  sample:",
+		"    name: simulated sample",
+		"  measurement:",
+		"    instrument_settings: {incident_angle: null, wavelength: null}",
+		"    data_files: []",
+		"reduction:",
+		"  software: {name: SPIRIT}",
+		"  timestamp: " + date.Format(time.RFC3339),
+		"simulation:",
+		fmt.Sprintf("  flux: %g", settings.Flux),
+		fmt.Sprintf("  resolution: %g", settings.Resolution),
+		fmt.Sprintf("  seed: %d", settings.Seed),
+		"  model:",
+		"    eden: " + floats(model.Eden),
+		"    thickness: " + floats(model.Thickness),
+		"    roughness: " + floats(model.Roughness),
+		fmt.Sprintf("    deltaq: %g", model.DeltaQ),
+		fmt.Sprintf("    background: %g", model.Background),
+		fmt.Sprintf("    scaling: %g", model.Scaling),
+		"columns:",
+		"- {name: Qz, unit: 1/angstrom, physical_quantity: normal momentum transfer}",
+		"- {name: R, physical_quantity: specular reflectivity}",
+		"- {error_of: R, error_type: uncertainty, value_is: sigma}",
+		"- {error_of: Qz, error_type: resolution, value_is: sigma}",
+		"# Qz (1/angstrom)    R                   sR                  sQz (1/angstrom)",
+	}
+	for _, line := range header {
+		fmt.Fprintf(b, "# %s\n", line)
+	}
+	for _, p := range points {
+		fmt.Fprintf(b, "%.12e  %.12e  %.12e  %.12e\n", p.X, p.Y, p.Error, p.X*settings.Resolution)
+	}
+	return b.Flush()
+}
@@ -0,0 +1,379 @@
+package state
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log"
+	"math"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/minimizer"
+	"slices"
+)
+
+// Objective is the penalty of the parameter values, ordered like Session.Values, against the data sets
+type Objective func(values []float64, datasets []function.Points) (float64, error)
+
+// Constraints derive parameters from other parameters
+type Constraints interface {
+	// Apply overwrites the derived values, the values are ordered like Session.Values
+	Apply(values []float64) error
+	// Derived reports whether the parameter at the index is derived, derived parameters are never fitted
+	Derived(i int) bool
+}
+
+// FitStatus is the status of the fit of a session
+type FitStatus int
+
+const (
+	FitIdle FitStatus = iota
+	FitRunning
+	FitPaused
+	FitConverged
+	FitFailed
+)
+
+func (s FitStatus) String() string {
+	switch s {
+	case FitIdle:
+		return "Not Initialized"
+	case FitRunning:
+		return "Running"
+	case FitPaused:
+		return "Paused"
+	case FitConverged:
+		return "Completed"
+	case FitFailed:
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+// Active reports whether a fit is running or paused
+func (s FitStatus) Active() bool {
+	return s == FitRunning || s == FitPaused
+}
+
+// FitState is the state of the current or the last fit of a session
+type FitState struct {
+	Status FitStatus
+	// number of the fit, counted up by every start
+	Run    int
+	Engine string
+	// seed of the engine, 0 if the engine uses no random numbers
+	Seed uint64
+	// indices of the parameters varied by the fit
+	Free []int
+	// latest result of the engine, nil before the first iteration
+	Result *minimizer.FitResult
+	// why the fit failed, also set if the fit reached the call limit
+	Err error
+}
+
+var (
+	// ErrFitRunning is returned when a fit is started while another one is running or paused
+	ErrFitRunning = errors.New("a fit is already running")
+	// ErrNoFit is returned when a fit is paused or resumed without a fit
+	ErrNoFit = errors.New("no fit is running")
+)
+
+// fit job of a session, the start values are restored when the fit is stopped
+type runningFit struct {
+	job   *minimizer.FitJob
+	start []float64
+	// closed once all events of the job are handled
+	handled chan struct{}
+}
+
+// SetConstraints sets the constraints of the fits, nil removes them
+func (s *Session) SetConstraints(c Constraints) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.constraints = c
+}
+
+// Objective returns the penalty of the fits
+func (s *Session) Objective() Objective {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.objective
+}
+
+// SetObjective changes the penalty of the following fits, f.e. to another figure of merit
+func (s *Session) SetObjective(objective Objective) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.objective = objective
+}
+
+// applies the constraints to the values in place
+func (s *Session) constrain(values []float64) error {
+	s.lock.RLock()
+	c := s.constraints
+	s.lock.RUnlock()
+	if c == nil {
+		return nil
+	}
+	return c.Apply(values)
+}
+
+// ApplyConstraints overwrites the derived parameters with the constraints evaluated on the current values,
+// observers are notified of the changed values
+func (s *Session) ApplyConstraints() error {
+	s.lock.Lock()
+	if s.constraints == nil {
+		s.lock.Unlock()
+		return nil
+	}
+	values := make([]float64, len(s.parameters))
+	for i, p := range s.parameters {
+		values[i] = p.Value
+	}
+	if err := s.constraints.Apply(values); err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	events := make([]Event, 0)
+	for i, v := range values {
+		if s.parameters[i].Value != v {
+			s.parameters[i].Value = v
+			events = append(events, parameterEvent(s.parameters[i]))
+		}
+	}
+	s.lock.Unlock()
+
+	s.notify(events...)
+	return nil
+}
+
+// FitProblem creates the fit problem of the current parameters against the data sets, the loaded ones if none are given
+// parameters without the fit flag and derived parameters are fixed
+func (s *Session) FitProblem(datasets ...function.Points) (*minimizer.FitProblem, error) {
+	if len(datasets) == 0 {
+		datasets = s.points()
+	}
+
+	s.lock.RLock()
+	objective, constraints := s.objective, s.constraints
+	parameters := slices.Clone(s.parameters)
+	s.lock.RUnlock()
+
+	values := make([]float64, len(parameters))
+	for i, p := range parameters {
+		values[i] = p.Value
+	}
+
+	problem := minimizer.NewFitProblem(func(par []float64) float64 {
+		// derived parameters are evaluated before every penalty call
+		par = slices.Clone(par)
+		if constraints != nil {
+			if err := constraints.Apply(par); err != nil {
+				log.Println("Error while applying constraints:", err)
+				return math.MaxFloat64
+			}
+		}
+		penalty, err := objective(par, datasets)
+		if err != nil {
+			log.Println("Error while calculating the penalty:", err)
+			return math.MaxFloat64
+		}
+		return penalty
+	}, values)
+
+	for i, p := range parameters {
+		if !p.Fit || (constraints != nil && constraints.Derived(i)) {
+			problem.Fixed[i] = true
+			continue
+		}
+		switch {
+		case p.Step > 0:
+			problem.Steps[i] = p.Step
+		case p.Value != 0:
+			// 10% of the value like the default step of the parameter widgets
+			problem.Steps[i] = math.Abs(p.Value) * 0.1
+		}
+		if p.Limited {
+			problem.Minima[i] = p.Min
+			problem.Maxima[i] = p.Max
+		}
+	}
+
+	if len(problem.Free()) == 0 {
+		return nil, minimizer.ErrNoFreeParam
+	}
+	return problem, nil
+}
+
+// Fit returns the state of the current or the last fit
+func (s *Session) Fit() FitState {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	state := s.fit
+	state.Free = slices.Clone(state.Free)
+	return state
+}
+
+// StartFit starts a fit of the loaded data sets in the background, the parameters follow its progress
+func (s *Session) StartFit(ctx context.Context, config minimizer.FitConfig) error {
+	problem, err := s.FitProblem()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	if s.job != nil {
+		s.lock.Unlock()
+		return ErrFitRunning
+	}
+	job, err := minimizer.StartFit(ctx, problem, config)
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	run := &runningFit{job: job, start: slices.Clone(problem.Values), handled: make(chan struct{})}
+	s.job = run
+	s.fit = FitState{
+		Status: FitRunning,
+		Run:    s.fit.Run + 1,
+		Engine: cmp.Or(config.Engine, minimizer.DefaultEngine),
+		Free:   problem.Free(),
+	}
+	if minimizer.EngineUses(s.fit.Engine, minimizer.SettingSeed) {
+		s.fit.Seed = job.Seed()
+	}
+	s.lock.Unlock()
+
+	s.notify(Event{Kind: FitChanged})
+	go s.handleFit(run)
+	return nil
+}
+
+// applies the events of a fit job to the session until the job ended
+func (s *Session) handleFit(run *runningFit) {
+	defer close(run.handled)
+
+	for event := range run.job.Events() {
+		// the values of a cancelled fit are restored by StopFit
+		if event.Kind == minimizer.FitCancelled {
+			s.finishFit(run, FitIdle, nil)
+			return
+		}
+
+		if res := event.Result; res != nil {
+			s.applyResult(run, res)
+		}
+
+		switch event.Kind {
+		case minimizer.FitConverged:
+			if err := s.SetErrors(event.Result.Errors); err != nil {
+				log.Println("Error while setting the fit errors:", err)
+			}
+			s.finishFit(run, FitConverged, nil)
+		case minimizer.FitMaxCalls, minimizer.FitFailed:
+			// the parameters keep the best values found so far
+			s.finishFit(run, FitFailed, event.Err)
+		}
+	}
+}
+
+func (s *Session) applyResult(run *runningFit, res *minimizer.FitResult) {
+	values := slices.Clone(res.Parameters)
+	if err := s.constrain(values); err != nil {
+		log.Println("Error while applying constraints:", err)
+	} else if err := s.SetValues(values); err != nil {
+		log.Println("Error while updating the parameters:", err)
+	}
+
+	s.lock.Lock()
+	if s.job == run {
+		s.fit.Result = res
+	}
+	s.lock.Unlock()
+	s.notify(Event{Kind: FitChanged})
+}
+
+func (s *Session) finishFit(run *runningFit, status FitStatus, err error) {
+	s.lock.Lock()
+	if s.job != run {
+		s.lock.Unlock()
+		return
+	}
+	s.job = nil
+	s.fit.Status = status
+	s.fit.Err = err
+	s.lock.Unlock()
+	s.notify(Event{Kind: FitChanged})
+}
+
+// PauseFit pauses the running fit after its current iteration
+func (s *Session) PauseFit() error {
+	return s.switchFit(FitRunning, FitPaused, (*minimizer.FitJob).Pause)
+}
+
+// ResumeFit continues a paused fit
+func (s *Session) ResumeFit() error {
+	return s.switchFit(FitPaused, FitRunning, (*minimizer.FitJob).Resume)
+}
+
+func (s *Session) switchFit(from, to FitStatus, action func(job *minimizer.FitJob)) error {
+	s.lock.Lock()
+	if s.job == nil || s.fit.Status != from {
+		s.lock.Unlock()
+		return ErrNoFit
+	}
+	action(s.job.job)
+	s.fit.Status = to
+	s.lock.Unlock()
+	s.notify(Event{Kind: FitChanged})
+	return nil
+}
+
+// StopFit cancels the running fit and restores the values the fit started with,
+// a fit which converged or failed before it was cancelled keeps its result
+// without a running fit only the status is reset
+func (s *Session) StopFit() {
+	s.lock.RLock()
+	run := s.job
+	s.lock.RUnlock()
+
+	if run == nil {
+		s.lock.Lock()
+		changed := s.fit.Status != FitIdle
+		s.fit.Status, s.fit.Err = FitIdle, nil
+		s.lock.Unlock()
+		if changed {
+			s.notify(Event{Kind: FitChanged})
+		}
+		return
+	}
+	s.stopRun(run)
+}
+
+// cancels the job of the fit and restores its start values unless the job finished on its own
+func (s *Session) stopRun(run *runningFit) {
+	// cancel the job and wait until its last events are handled
+	run.job.Cancel()
+	<-run.handled
+	s.lock.Lock()
+	if s.fit.Status == FitConverged || s.fit.Status == FitFailed {
+		s.lock.Unlock()
+		return
+	}
+	s.fit.Status, s.fit.Err = FitIdle, nil
+	s.lock.Unlock()
+	if err := s.SetValues(run.start); err != nil {
+		log.Println("Error while restoring the parameters:", err)
+	}
+	s.notify(Event{Kind: FitChanged})
+}
+
+// WaitFit blocks until the current fit ended and returns its final state
+func (s *Session) WaitFit() FitState {
+	s.lock.RLock()
+	run := s.job
+	s.lock.RUnlock()
+	if run != nil {
+		<-run.handled
+	}
+	return s.Fit()
+}
@@ -0,0 +1,225 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"math"
+	"physicsGUI/pkg/function"
+	"physicsGUI/pkg/minimizer"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// χ² of the line a·x + b against the data sets
+func line(values []float64, datasets []function.Points) (float64, error) {
+	if len(values) < 2 {
+		return 0, errors.New("line needs two parameters")
+	}
+	chi2 := 0.0
+	for _, points := range datasets {
+		for _, p := range points {
+			chi2 += math.Pow((values[0]*p.X+values[1]-p.Y)/p.Error, 2)
+		}
+	}
+	return chi2, nil
+}
+
+// derives the parameter at index target as factor times the parameter at index source
+type proportional struct {
+	target, source int
+	factor         float64
+}
+
+func (c proportional) Apply(values []float64) error {
+	values[c.target] = c.factor * values[c.source]
+	return nil
+}
+
+func (c proportional) Derived(i int) bool {
+	return i == c.target
+}
+
+// session of a line with the data of y = 2x + 1
+func lineSession(objective Objective) *Session {
+	s := NewSession(objective)
+	_ = s.AddParameter(Parameter{Group: "line", Name: "a", Value: 1, Fit: true})
+	_ = s.AddParameter(Parameter{Group: "line", Name: "b", Value: 0, Fit: true})
+	points := make(function.Points, 0)
+	for x := 0.0; x < 10; x++ {
+		points = append(points, &function.Point{X: x, Y: 2*x + 1, Error: 0.1})
+	}
+	s.AddDataset(Dataset{Name: "line.dat", Points: points})
+	return s
+}
+
+func TestFitProblem(t *testing.T) {
+	s := lineSession(line)
+	assert.NoError(t, s.UpdateParameter("line", "a", func(p *Parameter) {
+		p.Min, p.Max, p.Limited = 0, 5, true
+		p.Step = 0.5
+	}))
+
+	problem, err := s.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 0}, problem.Values)
+	assert.Equal(t, []float64{0, math.Inf(-1)}, problem.Minima)
+	assert.Equal(t, []float64{5, math.Inf(1)}, problem.Maxima)
+	assert.Equal(t, 0.5, problem.Steps[0])
+	assert.Equal(t, 0.1, problem.Steps[1])
+	assert.Equal(t, 0.0, problem.Function([]float64{2, 1}))
+
+	// without a step size the step is scaled to the value
+	assert.NoError(t, s.SetFloat("line", "b", -30))
+	problem, err = s.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, problem.Steps[1])
+	assert.NoError(t, s.SetFloat("line", "b", 0))
+
+	// other data sets than the loaded ones
+	other, err := s.FitProblem(function.Points{{X: 1, Y: 0, Error: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, 9.0, other.Function([]float64{2, 1}))
+
+	// the objective can be replaced for the following fits
+	s.SetObjective(func(values []float64, datasets []function.Points) (float64, error) { return 42, nil })
+	assert.NotNil(t, s.Objective())
+	problem, err = s.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, 42.0, problem.Function([]float64{2, 1}))
+	s.SetObjective(line)
+
+	// excluded data sets are not fitted
+	s.AddDataset(Dataset{Name: "outlier.dat", Points: function.Points{{X: 1, Y: 0, Error: 1}}, Excluded: true})
+	problem, err = s.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, problem.Function([]float64{2, 1}))
+	assert.NoError(t, s.RemoveDataset(1))
+
+	// derived parameters are fixed and evaluated before every call
+	s.SetConstraints(proportional{target: 1, source: 0, factor: 0.5})
+	problem, err = s.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, problem.Fixed)
+	assert.Equal(t, problem.Function([]float64{2, 1}), problem.Function([]float64{2, 100}))
+	s.SetConstraints(nil)
+
+	assert.NoError(t, s.UpdateParameter("line", "a", func(p *Parameter) { p.Fit = false }))
+	assert.NoError(t, s.UpdateParameter("line", "b", func(p *Parameter) { p.Fit = false }))
+	_, err = s.FitProblem()
+	assert.ErrorIs(t, err, minimizer.ErrNoFreeParam)
+
+	// errors of the objective are the worst penalty
+	failing := NewSession(line)
+	_ = failing.AddParameter(Parameter{Group: "line", Name: "a", Fit: true})
+	problem, err = failing.FitProblem()
+	assert.NoError(t, err)
+	assert.Equal(t, math.MaxFloat64, problem.Function([]float64{1}))
+}
+
+func TestStartFit(t *testing.T) {
+	s := lineSession(line)
+	events := record(s)
+
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	state := s.WaitFit()
+	assert.Equal(t, FitConverged, state.Status)
+	assert.Equal(t, 1, state.Run)
+	assert.Equal(t, minimizer.DefaultEngine, state.Engine)
+	assert.Equal(t, []int{0, 1}, state.Free)
+	assert.NotNil(t, state.Result)
+	assert.NoError(t, state.Err)
+
+	values := s.Values()
+	assert.InDelta(t, 2, values[0], 1e-6)
+	assert.InDelta(t, 1, values[1], 1e-6)
+	for _, p := range s.Parameters() {
+		assert.Greater(t, p.Error, 0.0)
+	}
+
+	kinds := make(map[EventKind]int)
+	for _, e := range events.take() {
+		kinds[e.Kind]++
+	}
+	assert.Greater(t, kinds[ParameterChanged], 0)
+	assert.Greater(t, kinds[FitChanged], 1)
+
+	// a finished fit can be started again
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	assert.Equal(t, 2, s.WaitFit().Run)
+}
+
+func TestFitFailed(t *testing.T) {
+	s := lineSession(line)
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{Engine: minimizer.EngineDE, MaxCalls: 10, IterationCalls: 5}))
+	state := s.WaitFit()
+	assert.Equal(t, FitFailed, state.Status)
+	assert.ErrorIs(t, state.Err, minimizer.ErrMaxCalls)
+
+	assert.Error(t, s.StartFit(context.Background(), minimizer.FitConfig{Engine: "unknown"}))
+	assert.Equal(t, FitFailed, s.Fit().Status)
+}
+
+func TestPauseResumeStop(t *testing.T) {
+	slow := func(values []float64, datasets []function.Points) (float64, error) {
+		time.Sleep(time.Millisecond)
+		return line(values, datasets)
+	}
+	s := lineSession(slow)
+	assert.ErrorIs(t, s.PauseFit(), ErrNoFit)
+
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	assert.Equal(t, FitRunning, s.Fit().Status)
+	assert.ErrorIs(t, s.StartFit(context.Background(), minimizer.FitConfig{}), ErrFitRunning)
+	assert.ErrorIs(t, s.ResumeFit(), ErrNoFit)
+
+	assert.NoError(t, s.PauseFit())
+	assert.Equal(t, FitPaused, s.Fit().Status)
+	assert.True(t, s.Fit().Status.Active())
+	assert.NoError(t, s.ResumeFit())
+	assert.Equal(t, FitRunning, s.Fit().Status)
+
+	// stopping restores the start values
+	s.StopFit()
+	assert.Equal(t, FitIdle, s.Fit().Status)
+	assert.Equal(t, []float64{1, 0}, s.Values())
+
+	// a stopped session can fit again
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	assert.Equal(t, FitConverged, s.WaitFit().Status)
+	s.StopFit()
+	assert.Equal(t, FitIdle, s.Fit().Status)
+}
+
+func TestStopFinishedFit(t *testing.T) {
+	s := lineSession(line)
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	s.lock.RLock()
+	run := s.job
+	s.lock.RUnlock()
+
+	// the fit converged between the cancellation and the handling of its last events
+	assert.Equal(t, FitConverged, s.WaitFit().Status)
+	s.stopRun(run)
+	assert.Equal(t, FitConverged, s.Fit().Status)
+	assert.InDelta(t, 2, s.Values()[0], 1e-6)
+	assert.InDelta(t, 1, s.Values()[1], 1e-6)
+}
+
+func TestFitConstraints(t *testing.T) {
+	s := lineSession(line)
+	// b = a/2, the best line through the data with this constraint
+	s.SetConstraints(proportional{target: 1, source: 0, factor: 0.5})
+	assert.NoError(t, s.StartFit(context.Background(), minimizer.FitConfig{}))
+	assert.Equal(t, FitConverged, s.WaitFit().Status)
+
+	values := s.Values()
+	assert.Equal(t, values[0]/2, values[1])
+	assert.Equal(t, []int{0}, s.Fit().Free)
+
+	// the derived values follow changes outside of fits
+	assert.NoError(t, s.SetFloat("line", "a", 3))
+	assert.NoError(t, s.ApplyConstraints())
+	assert.Equal(t, []float64{3, 1.5}, s.Values())
+}
@@ -0,0 +1,366 @@
+// Package state holds the state of a sample apart from the widgets that show it: the model parameters, the data
+// sets and the fit. Views observe a Session by Subscribe and change it by its methods, so the fit logic can be used
+// and tested without a window and several sessions can exist side by side.
+package state
+
+import (
+	"errors"
+	"fmt"
+	"physicsGUI/pkg/function"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventKind tells what changed in a session
+type EventKind int
+
+const (
+	// a value, the limits, the fit flag, the step or the error of a parameter changed or a parameter was added
+	ParameterChanged EventKind = iota
+	// data sets were added, removed or replaced
+	DatasetsChanged
+	// the status or the progress of the fit changed
+	FitChanged
+)
+
+// Event is sent to the observers of a session after every change
+type Event struct {
+	Kind EventKind
+	// group and name of the parameter of a ParameterChanged event
+	Group, Name string
+}
+
+// Parameter is a float parameter of the model
+type Parameter struct {
+	Group, Name string
+	Value       float64
+	// limits of the fit, only used if Limited is set
+	Min, Max float64
+	Limited  bool
+	// the parameter is varied by fits
+	Fit bool
+	// initial step size of the minimizer, 0 uses 10% of the value or the default step of the minimizer for a value of 0
+	Step float64
+	// error of the last fit, 0 if unknown
+	Error float64
+	// stage of the fit the parameter is freed in, parameters of lower orders are fitted first
+	// and stay free in the later stages
+	Order int
+}
+
+// Dataset is a loaded data set, the points are shared and never modified by the session
+type Dataset struct {
+	Name   string
+	Points function.Points
+	// file the data set was imported from and the time of the import, empty if unknown
+	Source   string
+	Imported time.Time
+	// excluded data sets are kept but not used by fits
+	Excluded bool
+}
+
+var (
+	// ErrParameterNotFound is returned when a parameter is not part of the session
+	ErrParameterNotFound = errors.New("parameter not found")
+	// ErrParameterExists is returned when a parameter is added twice
+	ErrParameterExists = errors.New("parameter already exists")
+)
+
+// Session is the state of one sample, all methods can be called from any goroutine
+type Session struct {
+	lock       sync.RWMutex
+	parameters []Parameter
+	datasets   []Dataset
+
+	objective   Objective
+	constraints Constraints
+	fit         FitState
+	job         *runningFit
+
+	observerLock sync.Mutex
+	observers    map[int]func(Event)
+	nextObserver int
+}
+
+// NewSession creates an empty session, the objective is the penalty of the fits
+func NewSession(objective Objective) *Session {
+	return &Session{
+		objective: objective,
+		observers: make(map[int]func(Event)),
+	}
+}
+
+// Subscribe registers an observer and returns the function to remove it again
+// observers are called after the change in the goroutine which changed the session, they may change the session
+func (s *Session) Subscribe(observer func(Event)) (unsubscribe func()) {
+	s.observerLock.Lock()
+	defer s.observerLock.Unlock()
+	id := s.nextObserver
+	s.nextObserver++
+	s.observers[id] = observer
+	return func() {
+		s.observerLock.Lock()
+		defer s.observerLock.Unlock()
+		delete(s.observers, id)
+	}
+}
+
+// calls the observers with the events, the lock of the session must not be held
+func (s *Session) notify(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+	s.observerLock.Lock()
+	ids := make([]int, 0, len(s.observers))
+	for id := range s.observers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	observers := make([]func(Event), len(ids))
+	for i, id := range ids {
+		observers[i] = s.observers[id]
+	}
+	s.observerLock.Unlock()
+
+	for _, e := range events {
+		for _, observer := range observers {
+			observer(e)
+		}
+	}
+}
+
+func parameterEvent(p Parameter) Event {
+	return Event{Kind: ParameterChanged, Group: p.Group, Name: p.Name}
+}
+
+// index of a parameter, -1 if not found, the lock must be held
+func (s *Session) index(group, name string) int {
+	return slices.IndexFunc(s.parameters, func(p Parameter) bool {
+		return p.Group == group && p.Name == name
+	})
+}
+
+// AddParameter adds a parameter to the session, the order of the parameters is the order of the fit values
+func (s *Session) AddParameter(p Parameter) error {
+	s.lock.Lock()
+	if s.index(p.Group, p.Name) != -1 {
+		s.lock.Unlock()
+		return fmt.Errorf("%w: %s in group %s", ErrParameterExists, p.Name, p.Group)
+	}
+	s.parameters = append(s.parameters, p)
+	s.lock.Unlock()
+
+	s.notify(parameterEvent(p))
+	return nil
+}
+
+// Parameter returns a copy of the parameter
+func (s *Session) Parameter(group, name string) (Parameter, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	i := s.index(group, name)
+	if i == -1 {
+		return Parameter{}, fmt.Errorf("%w: %s in group %s", ErrParameterNotFound, name, group)
+	}
+	return s.parameters[i], nil
+}
+
+// Parameters returns a copy of all parameters in the order of the fit values
+func (s *Session) Parameters() []Parameter {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return slices.Clone(s.parameters)
+}
+
+// Names returns the names of all parameters in the order of the fit values
+func (s *Session) Names() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	names := make([]string, len(s.parameters))
+	for i, p := range s.parameters {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Float returns the value of a parameter
+func (s *Session) Float(group, name string) (float64, error) {
+	p, err := s.Parameter(group, name)
+	return p.Value, err
+}
+
+// Floats returns the values of a group in the order the parameters were added
+func (s *Session) Floats(group string) ([]float64, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	values := make([]float64, 0)
+	for _, p := range s.parameters {
+		if p.Group == group {
+			values = append(values, p.Value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: group %s", ErrParameterNotFound, group)
+	}
+	return values, nil
+}
+
+// SetFloat sets the value of a parameter
+func (s *Session) SetFloat(group, name string, value float64) error {
+	return s.UpdateParameter(group, name, func(p *Parameter) {
+		p.Value = value
+	})
+}
+
+// UpdateParameter changes a parameter by the update function, observers are only notified if it changed
+// the group and the name cannot be changed
+func (s *Session) UpdateParameter(group, name string, update func(p *Parameter)) error {
+	s.lock.Lock()
+	i := s.index(group, name)
+	if i == -1 {
+		s.lock.Unlock()
+		return fmt.Errorf("%w: %s in group %s", ErrParameterNotFound, name, group)
+	}
+	p := s.parameters[i]
+	update(&p)
+	p.Group, p.Name = group, name
+	changed := p != s.parameters[i]
+	s.parameters[i] = p
+	s.lock.Unlock()
+
+	if changed {
+		s.notify(parameterEvent(p))
+	}
+	return nil
+}
+
+// Values returns the values of all parameters in the order they were added
+func (s *Session) Values() []float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	values := make([]float64, len(s.parameters))
+	for i, p := range s.parameters {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// SetValues sets the values of all parameters, ordered like Values
+func (s *Session) SetValues(values []float64) error {
+	return s.setFields(values, func(p *Parameter, v float64) { p.Value = v })
+}
+
+// SetErrors sets the fit errors of all parameters, ordered like Values
+func (s *Session) SetErrors(errors []float64) error {
+	return s.setFields(errors, func(p *Parameter, v float64) { p.Error = v })
+}
+
+func (s *Session) setFields(values []float64, set func(p *Parameter, v float64)) error {
+	s.lock.Lock()
+	if len(values) != len(s.parameters) {
+		s.lock.Unlock()
+		return fmt.Errorf("got %d values for %d parameters", len(values), len(s.parameters))
+	}
+	events := make([]Event, 0)
+	for i := range s.parameters {
+		p := s.parameters[i]
+		set(&p, values[i])
+		if p != s.parameters[i] {
+			s.parameters[i] = p
+			events = append(events, parameterEvent(p))
+		}
+	}
+	s.lock.Unlock()
+
+	s.notify(events...)
+	return nil
+}
+
+// CopyParameters takes the values of the parameters of the source with the same group and name,
+// with settings also their limits, fit flags and steps, parameters missing in one of the sessions are left alone
+// returns the number of copied parameters, the parameters of a running fit are not changed
+func (s *Session) CopyParameters(source *Session, settings bool) (int, error) {
+	if s.Fit().Status.Active() {
+		return 0, ErrFitRunning
+	}
+	parameters := source.Parameters()
+
+	s.lock.Lock()
+	copied := 0
+	events := make([]Event, 0)
+	for _, sp := range parameters {
+		i := s.index(sp.Group, sp.Name)
+		if i == -1 {
+			continue
+		}
+		copied++
+		p := s.parameters[i]
+		p.Value = sp.Value
+		if settings {
+			p.Min, p.Max, p.Limited, p.Fit, p.Step, p.Order = sp.Min, sp.Max, sp.Limited, sp.Fit, sp.Step, sp.Order
+		}
+		if p != s.parameters[i] {
+			s.parameters[i] = p
+			events = append(events, parameterEvent(p))
+		}
+	}
+	s.lock.Unlock()
+
+	s.notify(events...)
+	return copied, nil
+}
+
+// AddDataset appends a data set and returns its index
+func (s *Session) AddDataset(d Dataset) int {
+	s.lock.Lock()
+	s.datasets = append(s.datasets, d)
+	i := len(s.datasets) - 1
+	s.lock.Unlock()
+
+	s.notify(Event{Kind: DatasetsChanged})
+	return i
+}
+
+// RemoveDataset removes the data set at the index
+func (s *Session) RemoveDataset(i int) error {
+	s.lock.Lock()
+	if i < 0 || i >= len(s.datasets) {
+		s.lock.Unlock()
+		return fmt.Errorf("no data set %d", i)
+	}
+	s.datasets = slices.Delete(s.datasets, i, i+1)
+	s.lock.Unlock()
+
+	s.notify(Event{Kind: DatasetsChanged})
+	return nil
+}
+
+// SetDatasets replaces all data sets
+func (s *Session) SetDatasets(datasets []Dataset) {
+	s.lock.Lock()
+	s.datasets = slices.Clone(datasets)
+	s.lock.Unlock()
+
+	s.notify(Event{Kind: DatasetsChanged})
+}
+
+// Datasets returns a copy of the list of data sets
+func (s *Session) Datasets() []Dataset {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return slices.Clone(s.datasets)
+}
+
+// returns the points of the data sets used by fits
+func (s *Session) points() []function.Points {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	points := make([]function.Points, 0, len(s.datasets))
+	for _, d := range s.datasets {
+		if !d.Excluded {
+			points = append(points, d.Points)
+		}
+	}
+	return points
+}
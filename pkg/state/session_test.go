@@ -0,0 +1,151 @@
+package state
+
+import (
+	"physicsGUI/pkg/function"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// records the events of a session
+type recorder struct {
+	lock   sync.Mutex
+	events []Event
+}
+
+func record(s *Session) *recorder {
+	r := &recorder{}
+	s.Subscribe(func(e Event) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.events = append(r.events, e)
+	})
+	return r
+}
+
+func (r *recorder) take() []Event {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func TestParameters(t *testing.T) {
+	s := NewSession(nil)
+	events := record(s)
+
+	assert.NoError(t, s.AddParameter(Parameter{Group: "eden", Name: "Eden a", Value: 0}))
+	assert.NoError(t, s.AddParameter(Parameter{Group: "thick", Name: "Thickness 1", Value: 14}))
+	assert.NoError(t, s.AddParameter(Parameter{Group: "eden", Name: "Eden b", Value: 0.334}))
+	assert.ErrorIs(t, s.AddParameter(Parameter{Group: "eden", Name: "Eden a"}), ErrParameterExists)
+	assert.Len(t, events.take(), 3)
+
+	eden, err := s.Floats("eden")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0, 0.334}, eden)
+	_, err = s.Floats("rough")
+	assert.ErrorIs(t, err, ErrParameterNotFound)
+	assert.Equal(t, []string{"Eden a", "Thickness 1", "Eden b"}, s.Names())
+	assert.Equal(t, []float64{0, 14, 0.334}, s.Values())
+
+	assert.NoError(t, s.SetFloat("thick", "Thickness 1", 15))
+	v, err := s.Float("thick", "Thickness 1")
+	assert.NoError(t, err)
+	assert.Equal(t, 15.0, v)
+	assert.Equal(t, []Event{{Kind: ParameterChanged, Group: "thick", Name: "Thickness 1"}}, events.take())
+
+	// unchanged values send no events
+	assert.NoError(t, s.SetFloat("thick", "Thickness 1", 15))
+	assert.NoError(t, s.SetValues([]float64{0, 15, 0.4}))
+	assert.Equal(t, []Event{{Kind: ParameterChanged, Group: "eden", Name: "Eden b"}}, events.take())
+
+	assert.NoError(t, s.UpdateParameter("eden", "Eden b", func(p *Parameter) {
+		p.Fit = true
+		p.Min, p.Max, p.Limited = 0, 1, true
+		// the identity cannot be changed
+		p.Name = "Eden c"
+	}))
+	p, err := s.Parameter("eden", "Eden b")
+	assert.NoError(t, err)
+	assert.Equal(t, Parameter{Group: "eden", Name: "Eden b", Value: 0.4, Max: 1, Limited: true, Fit: true}, p)
+	assert.Len(t, events.take(), 1)
+
+	assert.ErrorIs(t, s.SetFloat("eden", "Eden c", 1), ErrParameterNotFound)
+	assert.Error(t, s.SetValues([]float64{1}))
+	assert.NoError(t, s.SetErrors([]float64{0.1, 0.2, 0.3}))
+	assert.Equal(t, 0.2, s.Parameters()[1].Error)
+}
+
+func TestDatasets(t *testing.T) {
+	s := NewSession(nil)
+	events := record(s)
+
+	first := Dataset{Name: "a.dat", Points: function.Points{{X: 0.1, Y: 1, Error: 0.1}}}
+	second := Dataset{Name: "b.dat", Points: function.Points{{X: 0.2, Y: 2, Error: 0.1}}}
+	assert.Equal(t, 0, s.AddDataset(first))
+	assert.Equal(t, 1, s.AddDataset(second))
+	assert.Equal(t, []Dataset{first, second}, s.Datasets())
+
+	assert.NoError(t, s.RemoveDataset(0))
+	assert.Error(t, s.RemoveDataset(1))
+	assert.Equal(t, []Dataset{second}, s.Datasets())
+
+	s.SetDatasets(nil)
+	assert.Empty(t, s.Datasets())
+	assert.Equal(t, []Event{{Kind: DatasetsChanged}, {Kind: DatasetsChanged}, {Kind: DatasetsChanged}, {Kind: DatasetsChanged}}, events.take())
+}
+
+func TestCopyParameters(t *testing.T) {
+	reference := NewSession(nil)
+	_ = reference.AddParameter(Parameter{Group: "thick", Name: "Thickness 1", Value: 14, Min: 10, Max: 20, Limited: true, Fit: true, Step: 0.5, Error: 0.1})
+	_ = reference.AddParameter(Parameter{Group: "thick", Name: "Thickness 3", Value: 5})
+	modified := NewSession(nil)
+	_ = modified.AddParameter(Parameter{Group: "thick", Name: "Thickness 1", Value: 12})
+	_ = modified.AddParameter(Parameter{Group: "thick", Name: "Thickness 2", Value: 8})
+	events := record(modified)
+
+	// only the values of parameters of both sessions are copied
+	copied, err := modified.CopyParameters(reference, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, copied)
+	assert.Equal(t, []float64{14, 8}, modified.Values())
+	assert.Equal(t, []Event{{Kind: ParameterChanged, Group: "thick", Name: "Thickness 1"}}, events.take())
+	p, _ := modified.Parameter("thick", "Thickness 1")
+	assert.False(t, p.Fit)
+
+	// with the settings, the fit error is never copied
+	_, err = modified.CopyParameters(reference, true)
+	assert.NoError(t, err)
+	p, _ = modified.Parameter("thick", "Thickness 1")
+	assert.Equal(t, Parameter{Group: "thick", Name: "Thickness 1", Value: 14, Min: 10, Max: 20, Limited: true, Fit: true, Step: 0.5}, p)
+	assert.Len(t, events.take(), 1)
+
+	// a copy of the same values sends no events
+	_, err = modified.CopyParameters(reference, true)
+	assert.NoError(t, err)
+	assert.Empty(t, events.take())
+}
+
+func TestSubscribe(t *testing.T) {
+	s := NewSession(nil)
+	assert.NoError(t, s.AddParameter(Parameter{Group: "general", Name: "scaling", Value: 1}))
+
+	calls := 0
+	unsubscribe := s.Subscribe(func(e Event) {
+		calls++
+		// observers may change the session
+		if v, _ := s.Float("general", "scaling"); v > 2 {
+			assert.NoError(t, s.SetFloat("general", "scaling", 2))
+		}
+	})
+	assert.NoError(t, s.SetFloat("general", "scaling", 3))
+	v, _ := s.Float("general", "scaling")
+	assert.Equal(t, 2.0, v)
+	assert.Equal(t, 2, calls)
+
+	unsubscribe()
+	assert.NoError(t, s.SetFloat("general", "scaling", 1))
+	assert.Equal(t, 2, calls)
+}
@@ -1,29 +1,135 @@
 package trigger
 
-var (
-	onChange    = func() {}
-	recalculate = make(chan bool)
+import (
+	"sync"
+	"time"
 )
 
-// trigger a recalculation
+// DefaultDelay is the time the scheduler waits for further changes before it calculates
+const DefaultDelay = 50 * time.Millisecond
+
+// Result of a calculation
+type Result struct {
+	Err      error
+	Duration time.Duration
+	// number of requests handled by the calculation
+	Requests int
+}
+
+// Scheduler runs a calculation in its own goroutine after changes have settled
 //
-// this should be used if you want to trigger a recalculation
-// after some input fields have been changed
-func Recalc() {
-	recalculate <- true
+// requests never block, requests which arrive while waiting or calculating are coalesced,
+// so a slow calculation is followed by at most one further calculation with the latest state
+type Scheduler struct {
+	delay time.Duration
+	wake  chan struct{}
+	stop  chan struct{}
+
+	lock      sync.Mutex
+	calculate func() error
+	onResult  func(Result)
+	requests  int
+	running   bool
+}
+
+// NewScheduler creates a scheduler, requests are kept until it is started
+func NewScheduler(delay time.Duration, calculate func() error) *Scheduler {
+	return &Scheduler{
+		delay:     delay,
+		wake:      make(chan struct{}, 1),
+		calculate: calculate,
+		onResult:  func(Result) {},
+	}
+}
+
+// Request a calculation
+func (s *Scheduler) Request() {
+	s.lock.Lock()
+	s.requests++
+	s.lock.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SetCalculate sets the calculation of the following requests
+func (s *Scheduler) SetCalculate(calculate func() error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calculate = calculate
 }
 
-// sets the onchange function which is getting called after a recalculation trigger
-func SetOnChange(f func()) {
-	onChange = f
+// SetOnResult sets the function which gets the result of every calculation, it is called in the goroutine of the scheduler
+func (s *Scheduler) SetOnResult(f func(Result)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onResult = f
 }
 
-// Initializes the channel for notifications
-func Init() {
-	go func() {
-		for {
-			<-recalculate
-			onChange()
+// Start the goroutine of the scheduler, starting a running scheduler does nothing
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	go s.run(s.stop)
+}
+
+// Stop the goroutine of the scheduler, a running calculation is finished and pending requests are kept
+func (s *Scheduler) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.running {
+		return
+	}
+	s.running = false
+	close(s.stop)
+}
+
+func (s *Scheduler) run(stop chan struct{}) {
+	timer := time.NewTimer(s.delay)
+	timer.Stop()
+	for {
+		select {
+		case <-s.wake:
+		case <-stop:
+			return
 		}
-	}()
+
+		// debounce, every further request restarts the delay
+		timer.Reset(s.delay)
+		for waiting := true; waiting; {
+			select {
+			case <-s.wake:
+				timer.Reset(s.delay)
+			case <-timer.C:
+				waiting = false
+			case <-stop:
+				timer.Stop()
+				// the pending requests are handled after a restart
+				select {
+				case s.wake <- struct{}{}:
+				default:
+				}
+				return
+			}
+		}
+
+		// all requests up to here are handled by this calculation
+		s.lock.Lock()
+		requests, calculate, onResult := s.requests, s.calculate, s.onResult
+		s.requests = 0
+		s.lock.Unlock()
+		if requests == 0 {
+			continue
+		}
+
+		start := time.Now()
+		err := calculate()
+		onResult(Result{Err: err, Duration: time.Since(start), Requests: requests})
+	}
 }
@@ -0,0 +1,113 @@
+package trigger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// creates a started scheduler which sends its results to the returned channel
+func newTestScheduler(t *testing.T, calculate func() error) (*Scheduler, chan Result) {
+	results := make(chan Result, 10)
+	s := NewScheduler(10*time.Millisecond, calculate)
+	s.SetOnResult(func(r Result) { results <- r })
+	s.Start()
+	t.Cleanup(s.Stop)
+	return s, results
+}
+
+func receive(t *testing.T, results chan Result) Result {
+	select {
+	case r := <-results:
+		return r
+	case <-time.After(time.Second):
+		t.Fatal("no calculation")
+		return Result{}
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	calculations := 0
+	s, results := newTestScheduler(t, func() error {
+		calculations++
+		return nil
+	})
+
+	// rapid requests are handled by one calculation
+	for range 10 {
+		s.Request()
+		time.Sleep(time.Millisecond)
+	}
+	r := receive(t, results)
+	assert.NoError(t, r.Err)
+	assert.Equal(t, 10, r.Requests)
+	assert.Equal(t, 1, calculations)
+
+	// no calculation without requests
+	select {
+	case <-results:
+		t.Fatal("calculation without request")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCoalesceWhileCalculating(t *testing.T) {
+	started := make(chan bool)
+	release := make(chan bool)
+	s, results := newTestScheduler(t, func() error {
+		started <- true
+		<-release
+		return nil
+	})
+
+	s.Request()
+	<-started
+	for range 5 {
+		s.Request()
+	}
+	release <- true
+	assert.Equal(t, 1, receive(t, results).Requests)
+
+	// the requests during the slow calculation are coalesced into one
+	<-started
+	release <- true
+	assert.Equal(t, 5, receive(t, results).Requests)
+	select {
+	case <-started:
+		t.Fatal("stale calculation")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResult(t *testing.T) {
+	s, results := newTestScheduler(t, func() error {
+		time.Sleep(5 * time.Millisecond)
+		return errors.New("failed")
+	})
+	s.Request()
+	r := receive(t, results)
+	assert.EqualError(t, r.Err, "failed")
+	assert.GreaterOrEqual(t, r.Duration, 5*time.Millisecond)
+}
+
+func TestStartStop(t *testing.T) {
+	results := make(chan Result, 10)
+	s := NewScheduler(time.Millisecond, func() error { return nil })
+	s.SetOnResult(func(r Result) { results <- r })
+
+	// requests are kept until the scheduler is started
+	s.Request()
+	s.Request()
+	s.Start()
+	s.Start()
+	assert.Equal(t, 2, receive(t, results).Requests)
+
+	s.Stop()
+	s.Request()
+	s.SetCalculate(func() error { return errors.New("new calculation") })
+	s.Start()
+	defer s.Stop()
+	assert.EqualError(t, receive(t, results).Err, "new calculation")
+}
@@ -0,0 +1,172 @@
+// Package watch reports changes of files on disk, f.e. data files which are rewritten during a running measurement
+package watch
+
+import (
+	"log"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDelay is the time the watcher waits for further writes before it reports a change
+const DefaultDelay = 200 * time.Millisecond
+
+// Watcher calls a function when a watched file was written or replaced
+//
+// the directories of the files are watched, so files replaced by a rename of a new file are followed too;
+// several writes within the delay are reported once, so a file isn't read while it is written
+type Watcher struct {
+	delay    time.Duration
+	onChange func(path string)
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+
+	lock sync.Mutex
+	// watched files and the number of watched files in each directory
+	files  map[string]bool
+	dirs   map[string]int
+	timers map[string]*time.Timer
+	closed bool
+}
+
+// New starts a watcher without files, onChange is called in its own goroutine with the path passed to Add
+func New(delay time.Duration, onChange func(path string)) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		delay:    delay,
+		onChange: onChange,
+		watcher:  watcher,
+		done:     make(chan struct{}),
+		files:    make(map[string]bool),
+		dirs:     make(map[string]int),
+		timers:   make(map[string]*time.Timer),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Add watches the file, adding a file twice has no effect
+func (w *Watcher) Add(path string) error {
+	path = filepath.Clean(path)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.files[path] {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if w.dirs[dir] == 0 {
+		if err := w.watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	w.dirs[dir]++
+	w.files[path] = true
+	return nil
+}
+
+// Remove stops watching the file, pending changes of the file are not reported
+func (w *Watcher) Remove(path string) error {
+	path = filepath.Clean(path)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.files[path] {
+		return nil
+	}
+	delete(w.files, path)
+	if t := w.timers[path]; t != nil {
+		t.Stop()
+		delete(w.timers, path)
+	}
+	dir := filepath.Dir(path)
+	w.dirs[dir]--
+	if w.dirs[dir] == 0 {
+		delete(w.dirs, dir)
+		return w.watcher.Remove(dir)
+	}
+	return nil
+}
+
+// Files returns the sorted watched files
+func (w *Watcher) Files() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	files := make([]string, 0, len(w.files))
+	for path := range w.files {
+		files = append(files, path)
+	}
+	slices.Sort(files)
+	return files
+}
+
+// Close stops the watcher, no changes are reported afterwards
+func (w *Watcher) Close() error {
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		return nil
+	}
+	w.closed = true
+	for _, t := range w.timers {
+		t.Stop()
+	}
+	w.lock.Unlock()
+
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+// handles the events of the directories until the watcher is closed
+func (w *Watcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// a file replaced by a rename is created in the directory
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
+				w.changed(filepath.Clean(event.Name))
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Println("Error while watching files:", err)
+		}
+	}
+}
+
+// reports the change of a watched file after the delay, further changes restart the delay
+func (w *Watcher) changed(path string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.files[path] || w.closed {
+		return
+	}
+	// a timer which already fired is replaced, its report may still be running
+	if t := w.timers[path]; t != nil && t.Stop() {
+		t.Reset(w.delay)
+		return
+	}
+	var t *time.Timer
+	t = time.AfterFunc(w.delay, func() {
+		w.lock.Lock()
+		if w.timers[path] == t {
+			delete(w.timers, path)
+		}
+		report := w.files[path] && !w.closed
+		w.lock.Unlock()
+		if report {
+			w.onChange(path)
+		}
+	})
+	w.timers[path] = t
+}
@@ -0,0 +1,91 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// creates a watcher which sends the changed files to the returned channel
+func newTestWatcher(t *testing.T) (*Watcher, chan string) {
+	changes := make(chan string, 10)
+	w, err := New(20*time.Millisecond, func(path string) { changes <- path })
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+	return w, changes
+}
+
+func receive(t *testing.T, changes chan string) string {
+	select {
+	case path := <-changes:
+		return path
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported")
+		return ""
+	}
+}
+
+func assertNoChange(t *testing.T, changes chan string) {
+	select {
+	case path := <-changes:
+		t.Fatalf("unexpected change of %s", path)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWrite(t *testing.T) {
+	w, changes := newTestWatcher(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.dat")
+	other := filepath.Join(dir, "other.dat")
+	assert.NoError(t, os.WriteFile(file, []byte("1\n"), 0644))
+	assert.NoError(t, w.Add(file))
+	assert.NoError(t, w.Add(file))
+	assert.Equal(t, []string{file}, w.Files())
+
+	// several writes are reported once
+	for i := range 5 {
+		assert.NoError(t, os.WriteFile(file, []byte{byte('0' + i)}, 0644))
+	}
+	assert.Equal(t, file, receive(t, changes))
+	assertNoChange(t, changes)
+
+	// other files of the directory are ignored
+	assert.NoError(t, os.WriteFile(other, []byte("1\n"), 0644))
+	assertNoChange(t, changes)
+
+	assert.NoError(t, w.Remove(file))
+	assert.Empty(t, w.Files())
+	assert.NoError(t, os.WriteFile(file, []byte("2\n"), 0644))
+	assertNoChange(t, changes)
+}
+
+func TestReplace(t *testing.T) {
+	w, changes := newTestWatcher(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.dat")
+	assert.NoError(t, os.WriteFile(file, []byte("1\n"), 0644))
+	assert.NoError(t, w.Add(file))
+
+	// pipelines often write a new file and rename it
+	temporary := filepath.Join(dir, "data.tmp")
+	assert.NoError(t, os.WriteFile(temporary, []byte("2\n"), 0644))
+	assert.NoError(t, os.Rename(temporary, file))
+	assert.Equal(t, file, receive(t, changes))
+}
+
+func TestClose(t *testing.T) {
+	w, changes := newTestWatcher(t)
+	file := filepath.Join(t.TempDir(), "data.dat")
+	assert.NoError(t, os.WriteFile(file, []byte("1\n"), 0644))
+	assert.NoError(t, w.Add(file))
+
+	assert.NoError(t, os.WriteFile(file, []byte("2\n"), 0644))
+	assert.NoError(t, w.Close())
+	assert.NoError(t, w.Close())
+	assertNoChange(t, changes)
+	assert.Error(t, w.Add(filepath.Join(t.TempDir(), "new.dat")))
+}